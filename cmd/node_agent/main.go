@@ -0,0 +1,52 @@
+// Command node_agent runs the agent of an MPC node: it ingests share
+// containers, verifies and stores the shares of the node, and serves
+// their status on a local HTTP API.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"github.com/krakenh2020/ZKPComponent"
+	"github.com/krakenh2020/ZKPComponent/key_management"
+	"github.com/krakenh2020/ZKPComponent/node_agent"
+)
+
+func main() {
+	id := flag.Int("id", 0, "index of the node (0, 1 or 2)")
+	keyName := flag.String("key", "test", "name of the NaCl key pair of the node")
+	keyDir := flag.String("keys", "key_management/keys", "directory of the key pair")
+	store := flag.String("store", "node_store", "directory storing the accepted shares")
+	verifyKey := flag.String("vk", "verifyKey.txt", "verifying key of the dataset proofs")
+	artifacts := flag.String("artifacts", "", "circuit artifact store to load the verifying key of the circuit of each dataset from, instead of -vk")
+	listen := flag.String("listen", "127.0.0.1:8700", "address of the local API")
+	flag.Parse()
+
+	pubKey, err := key_management.LoadPubKey(*keyName, *keyDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	secKey, err := key_management.LoadSecKey(*keyName, *keyDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var keys ZKPComponent.VerifyingKeys
+	if *artifacts != "" {
+		keys = ZKPComponent.NewArtifactStore(*artifacts)
+	} else {
+		keys, err = ZKPComponent.LoadLegacyVerifyingKeys(*verifyKey)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	agent, err := node_agent.NewAgent(node_agent.Config{NodeId: *id, PubKey: pubKey, SecKey: secKey,
+		StoreDir: *store, VerifyingKeys: keys})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("node %d listening on %s", *id, *listen)
+	log.Fatal(http.ListenAndServe(*listen, agent.Handler()))
+}
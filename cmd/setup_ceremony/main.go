@@ -0,0 +1,408 @@
+// Command setup_ceremony runs the multi-party Groth16 setup of the
+// circuits: each participant contributes its secrets to the parameters
+// of the previous one, anyone verifies the transcript of the
+// contributions, and the keys are exported to an artifact store. The
+// keys are sound as long as one participant forgot its secrets.
+//
+//	setup_ceremony phase1-init -power 14 -out phase1-0
+//	setup_ceremony phase1-contribute -in phase1-0 -out phase1-1
+//	setup_ceremony phase1-verify phase1-0 phase1-1 ...
+//	setup_ceremony phase2-init -phase1 phase1-0,phase1-1,... -out phase2-0
+//	setup_ceremony phase2-contribute -in phase2-0 -out phase2-1
+//	setup_ceremony phase2-verify -phase1 phase1-0,phase1-1,... phase2-0 phase2-1 ...
+//	setup_ceremony export -phase1 phase1-0,phase1-1,... -artifacts artifacts phase2-0 phase2-1 ...
+//	setup_ceremony export -phase1 ... -pk proofKey.txt -vk verifyKey.txt phase2-0 phase2-1 ...
+//	setup_ceremony simulate -participants 3 -artifacts artifacts
+//
+// The phase 2 starts from the last contribution of the phase 1, once
+// its whole transcript is verified. Circuits with gnark commitments,
+// such as the ECDSA dataset circuit, are not supported.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/constraint"
+	"github.com/krakenh2020/ZKPComponent"
+	"github.com/krakenh2020/ZKPComponent/setup_ceremony"
+	"github.com/krakenh2020/ZKPComponent/signature"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: setup_ceremony phase1-init|phase1-contribute|phase1-verify|"+
+		"phase2-init|phase2-contribute|phase2-verify|export|simulate [flags]")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	cmd, args := os.Args[1], os.Args[2:]
+	var err error
+	switch cmd {
+	case "phase1-init":
+		err = phase1Init(args)
+	case "phase1-contribute":
+		err = phase1Contribute(args)
+	case "phase1-verify":
+		err = phase1Verify(args)
+	case "phase2-init":
+		err = phase2Init(args)
+	case "phase2-contribute":
+		err = phase2Contribute(args)
+	case "phase2-verify":
+		err = phase2Verify(args)
+	case "export":
+		err = export(args)
+	case "simulate":
+		err = simulate(args)
+	default:
+		usage()
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// keyFlags are the flags of the outputs of the keys
+type keyFlags struct {
+	artifacts string
+	pk, vk    string
+}
+
+func (k *keyFlags) add(fs *flag.FlagSet) {
+	fs.StringVar(&k.artifacts, "artifacts", "artifacts", "directory of the artifact store")
+	fs.StringVar(&k.pk, "pk", "", "file to write the proving key to as a JSON array of bytes, as proofKey.txt, "+
+		"instead of the artifact store")
+	fs.StringVar(&k.vk, "vk", "", "file to write the verifying key to, as verifyKey.txt, with -pk")
+}
+
+// circuitFlags adds the flags selecting the circuit
+func circuitFlags(fs *flag.FlagSet) *ZKPComponent.CircuitId {
+	var id ZKPComponent.CircuitId
+	fs.StringVar(&id.Name, "circuit", ZKPComponent.CircuitDatasetName, "name of the circuit")
+	fs.IntVar((*int)(&id.Version), "version", int(signature.CurrentCircuitVersion), "version of the circuit")
+	fs.IntVar(&id.Size, "size", 0, "size of the circuit, for circuits depending on the length of the dataset")
+
+	return &id
+}
+
+func writeFile(file string, o io.WriterTo) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	_, err = o.WriteTo(f)
+	if err2 := f.Close(); err == nil {
+		err = err2
+	}
+
+	return err
+}
+
+func readFile(file string, o io.ReaderFrom) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = o.ReadFrom(f)
+
+	return err
+}
+
+func readPhase1(files []string) ([]*setup_ceremony.Phase1, error) {
+	res := make([]*setup_ceremony.Phase1, len(files))
+	for i, file := range files {
+		res[i] = new(setup_ceremony.Phase1)
+		if err := readFile(file, res[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// phase1Flag adds the flag of the files of the phase 1 transcript
+func phase1Flag(fs *flag.FlagSet) *string {
+	return fs.String("phase1", "", "files of the transcript of the phase 1, comma separated, from phase1-0")
+}
+
+// initPhase2 compiles the circuit id and returns the initial phase 2
+// parameters and the evaluations from the verified phase 1 transcript.
+func initPhase2(id ZKPComponent.CircuitId, phase1 string) (constraint.ConstraintSystem, *setup_ceremony.Phase2,
+	*setup_ceremony.Phase2Evaluations, error) {
+	transcript, err := readPhase1(strings.Split(phase1, ","))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	ccs, err := id.Compile()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	p2, evals, err := setup_ceremony.InitVerifiedPhase2(ccs, transcript)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return ccs, p2, evals, nil
+}
+
+func readPhase2(files []string) ([]*setup_ceremony.Phase2, error) {
+	res := make([]*setup_ceremony.Phase2, len(files))
+	for i, file := range files {
+		res[i] = new(setup_ceremony.Phase2)
+		if err := readFile(file, res[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+func phase1Init(args []string) error {
+	fs := flag.NewFlagSet("phase1-init", flag.ExitOnError)
+	power := fs.Int("power", 14, "log2 of the maximal number of constraints of the circuits")
+	out := fs.String("out", "phase1-0", "file of the initial parameters")
+	fs.Parse(args)
+
+	p, err := setup_ceremony.InitPhase1(*power)
+	if err != nil {
+		return err
+	}
+
+	return writeFile(*out, p)
+}
+
+func phase1Contribute(args []string) error {
+	fs := flag.NewFlagSet("phase1-contribute", flag.ExitOnError)
+	in := fs.String("in", "", "file of the previous contribution")
+	out := fs.String("out", "", "file of the contribution")
+	fs.Parse(args)
+
+	var p setup_ceremony.Phase1
+	if err := readFile(*in, &p); err != nil {
+		return err
+	}
+	if err := p.Contribute(); err != nil {
+		return err
+	}
+	log.Printf("contribution hash %x", p.Hash)
+
+	return writeFile(*out, &p)
+}
+
+func phase1Verify(args []string) error {
+	fs := flag.NewFlagSet("phase1-verify", flag.ExitOnError)
+	fs.Parse(args)
+
+	transcript, err := readPhase1(fs.Args())
+	if err != nil {
+		return err
+	}
+	if err := setup_ceremony.VerifyPhase1Transcript(transcript); err != nil {
+		return err
+	}
+	for i, p := range transcript[1:] {
+		log.Printf("contribution %d valid, hash %x", i+1, p.Hash)
+	}
+
+	return nil
+}
+
+func phase2Init(args []string) error {
+	fs := flag.NewFlagSet("phase2-init", flag.ExitOnError)
+	id := circuitFlags(fs)
+	phase1 := phase1Flag(fs)
+	out := fs.String("out", "phase2-0", "file of the initial parameters")
+	fs.Parse(args)
+
+	_, p2, _, err := initPhase2(*id, *phase1)
+	if err != nil {
+		return err
+	}
+
+	return writeFile(*out, p2)
+}
+
+func phase2Contribute(args []string) error {
+	fs := flag.NewFlagSet("phase2-contribute", flag.ExitOnError)
+	in := fs.String("in", "", "file of the previous contribution")
+	out := fs.String("out", "", "file of the contribution")
+	fs.Parse(args)
+
+	var p setup_ceremony.Phase2
+	if err := readFile(*in, &p); err != nil {
+		return err
+	}
+	if err := p.Contribute(); err != nil {
+		return err
+	}
+	log.Printf("contribution hash %x", p.Hash)
+
+	return writeFile(*out, &p)
+}
+
+func phase2Verify(args []string) error {
+	fs := flag.NewFlagSet("phase2-verify", flag.ExitOnError)
+	id := circuitFlags(fs)
+	phase1 := phase1Flag(fs)
+	fs.Parse(args)
+
+	_, init, _, err := initPhase2(*id, *phase1)
+	if err != nil {
+		return err
+	}
+	transcript, err := readPhase2(fs.Args())
+	if err != nil {
+		return err
+	}
+	if err := setup_ceremony.VerifyPhase2Transcript(init, transcript); err != nil {
+		return err
+	}
+	for i, p := range transcript[1:] {
+		log.Printf("contribution %d valid, hash %x", i+1, p.Hash)
+	}
+
+	return nil
+}
+
+// export verifies the transcripts of the phase 1 and of the phase 2 and
+// saves the keys of the last contribution of the phase 2. The
+// evaluations of the circuit are computed again from the last
+// contribution of the phase 1.
+func export(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	id := circuitFlags(fs)
+	phase1 := phase1Flag(fs)
+	var out keyFlags
+	out.add(fs)
+	fs.Parse(args)
+
+	transcript1, err := readPhase1(strings.Split(*phase1, ","))
+	if err != nil {
+		return err
+	}
+	ccs, err := id.Compile()
+	if err != nil {
+		return err
+	}
+	transcript2, err := readPhase2(fs.Args())
+	if err != nil {
+		return err
+	}
+	pk, vk, err := setup_ceremony.ExtractVerifiedKeys(ccs, transcript1, transcript2)
+	if err != nil {
+		return err
+	}
+
+	return save(*id, out, ccs, pk, vk)
+}
+
+func save(id ZKPComponent.CircuitId, out keyFlags, ccs constraint.ConstraintSystem, pk groth16.ProvingKey,
+	vk groth16.VerifyingKey) error {
+	a, err := ZKPComponent.NewCircuitArtifacts(id, ccs, pk, vk)
+	if err != nil {
+		return err
+	}
+	if out.pk != "" {
+		if out.vk == "" {
+			return fmt.Errorf("missing -vk")
+		}
+		if err := ZKPComponent.WriteKey(out.pk, pk); err != nil {
+			return err
+		}
+		if err := ZKPComponent.WriteKey(out.vk, vk); err != nil {
+			return err
+		}
+		log.Printf("keys of circuit %s written to %s and %s", id, out.pk, out.vk)
+
+		return nil
+	}
+	if err := ZKPComponent.NewArtifactStore(out.artifacts).Save(a); err != nil {
+		return err
+	}
+	log.Printf("keys of circuit %s saved in %s", id, out.artifacts)
+
+	return nil
+}
+
+// simulate runs the whole ceremony in this process, the participants
+// contributing in turn, each contribution being verified.
+func simulate(args []string) error {
+	fs := flag.NewFlagSet("simulate", flag.ExitOnError)
+	id := circuitFlags(fs)
+	power := fs.Int("power", 14, "log2 of the maximal number of constraints of the circuits")
+	participants := fs.Int("participants", 3, "number of participants in each phase")
+	var out keyFlags
+	out.add(fs)
+	fs.Parse(args)
+
+	p1, err := setup_ceremony.InitPhase1(*power)
+	if err != nil {
+		return err
+	}
+	for i := 1; i <= *participants; i++ {
+		prev := p1
+		p1 = new(setup_ceremony.Phase1)
+		if err := clone(p1, prev); err != nil {
+			return err
+		}
+		if err := p1.Contribute(); err != nil {
+			return err
+		}
+		if err := setup_ceremony.VerifyPhase1(prev, p1); err != nil {
+			return err
+		}
+		log.Printf("phase 1 contribution %d, hash %x", i, p1.Hash)
+	}
+
+	ccs, err := id.Compile()
+	if err != nil {
+		return err
+	}
+	p2, e, err := setup_ceremony.InitPhase2(ccs, p1)
+	if err != nil {
+		return err
+	}
+	for i := 1; i <= *participants; i++ {
+		prev := p2
+		p2 = new(setup_ceremony.Phase2)
+		if err := clone(p2, prev); err != nil {
+			return err
+		}
+		if err := p2.Contribute(); err != nil {
+			return err
+		}
+		if err := setup_ceremony.VerifyPhase2(prev, p2); err != nil {
+			return err
+		}
+		log.Printf("phase 2 contribution %d, hash %x", i, p2.Hash)
+	}
+	pk, vk, err := setup_ceremony.ExtractKeys(p2, e)
+	if err != nil {
+		return err
+	}
+
+	return save(*id, out, ccs, pk, vk)
+}
+
+// clone copies the parameters through their encoding, as a participant
+// receiving them.
+func clone(dst io.ReaderFrom, src io.WriterTo) error {
+	r, w := io.Pipe()
+	go func() {
+		_, err := src.WriteTo(w)
+		w.CloseWithError(err)
+	}()
+	_, err := dst.ReadFrom(r)
+
+	return err
+}
@@ -0,0 +1,76 @@
+// Command solidity_verifier exports the Solidity verifier contract of
+// the dataset proofs and, given a share container, prints the calldata
+// of its verifyProof function for the authentication proof of the
+// container.
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/krakenh2020/ZKPComponent"
+	"github.com/krakenh2020/ZKPComponent/signature"
+)
+
+func main() {
+	verifyKey := flag.String("vk", "verifyKey.txt", "verifying key of the dataset proofs")
+	artifacts := flag.String("artifacts", "", "circuit artifact store to load the verifying key from, instead of -vk")
+	out := flag.String("out", "Verifier.sol", "file of the exported contract")
+	container := flag.String("calldata", "", "share container whose calldata to print, instead of exporting the contract")
+	flag.Parse()
+
+	if *container != "" {
+		f, err := os.Open(*container)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		aProof, cols, err := ZKPComponent.ReadAuthColsFrom(f)
+		if err != nil {
+			log.Fatal(err)
+		}
+		p, err := ZKPComponent.NewSolidityProof(aProof, cols)
+		if err != nil {
+			log.Fatal(err)
+		}
+		calldata, err := p.Calldata()
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("0x" + hex.EncodeToString(calldata))
+		return
+	}
+
+	var vk groth16.VerifyingKey
+	if *artifacts != "" {
+		a, err := ZKPComponent.NewArtifactStore(*artifacts).LoadVerifier(ZKPComponent.CircuitId{
+			Name: ZKPComponent.CircuitDatasetName, Version: signature.CurrentCircuitVersion})
+		if err != nil {
+			log.Fatal(err)
+		}
+		vk = a.VerifyingKey
+	} else {
+		var err error
+		vk, err = ZKPComponent.LoadVerifyingKey(*verifyKey)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = ZKPComponent.ExportSolidityVerifier(vk, f)
+	if err2 := f.Close(); err == nil {
+		err = err2
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("verifier contract written to %s", *out)
+}
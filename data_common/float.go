@@ -5,21 +5,25 @@ import (
 	"math"
 )
 
-func FloatToFixInt(x float64) (int64, error) {
-	k := 41
-	f := 20
+// FixK is the bit length of the fixed-point encoded values and
+// FixF the number of fractional bits.
+const (
+	FixK = 41
+	FixF = 20
+)
 
-	if math.Abs(x) >= math.Pow(2, float64(k-f-1)) {
+func FloatToFixInt(x float64) (int64, error) {
+	if math.Abs(x) >= math.Pow(2, float64(FixK-FixF-1)) {
 		return 0, fmt.Errorf("float too big or to small")
 	}
 
-	v := int64(math.Round(x * math.Pow(2, 20)))
+	v := int64(math.Round(x * math.Pow(2, FixF)))
 
 	return v, nil
 }
 
 func FixIntToFloat(i int64) (x float64) {
-	v := float64(i) / math.Pow(2, 20)
+	v := float64(i) / math.Pow(2, FixF)
 
 	return v
 }
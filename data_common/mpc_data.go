@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"math/big"
 	"os"
 	"os/exec"
@@ -206,16 +207,27 @@ func ReadShare(file string, pubKey, secKey []byte, nodeId int) ([]*big.Int, []st
 	if err != nil {
 		return nil, nil, err
 	}
+	defer f.Close()
 
-	reader := bufio.NewReader(f)
+	return ReadShareFrom(f, pubKey, secKey, nodeId)
+}
+
+// ReadShareFrom reads the share of node nodeId and the columns from a
+// share container, as written by SplitCsvFile.
+func ReadShareFrom(r io.Reader, pubKey, secKey []byte, nodeId int) ([]*big.Int, []string, error) {
+	if nodeId < 0 || nodeId >= 3 {
+		return nil, nil, fmt.Errorf("invalid node id %d", nodeId)
+	}
+	reader := bufio.NewReader(r)
 
-	countLines := 0
 	var decVec []*big.Int
-	for countLines < 3 {
+	for countLines := 0; countLines < 3; countLines++ {
 		text, err := Readln(reader)
+		if err != nil {
+			return nil, nil, err
+		}
 
 		if countLines != nodeId {
-			countLines++
 			continue
 		}
 
@@ -229,13 +241,13 @@ func ReadShare(file string, pubKey, secKey []byte, nodeId int) ([]*big.Int, []st
 		if err != nil {
 			return nil, nil, err
 		}
-		countLines++
 	}
 	// columns info
 	text, err := Readln(reader)
+	if err != nil {
+		return nil, nil, err
+	}
 	cols := strings.Split(text, ",")
 
-	f.Close()
-
 	return decVec, cols, nil
 }
@@ -0,0 +1,390 @@
+package data_common
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/krakenh2020/ZKPComponent/encryption"
+)
+
+// ShareWriter writes a share vector incrementally: a line with the
+// columns, a line with the binary encoding of the elements, followed
+// by blocks of encoded elements, each preceded by its number of
+// elements as 4 bytes big-endian. Close ends the elements with an
+// empty block followed by a trailer, such as the authentication of the
+// share.
+type ShareWriter struct {
+	w        io.Writer
+	encoding string
+	closed   bool
+}
+
+// MaxShareTrailer is the maximal length of the trailer of a share
+// stream.
+const MaxShareTrailer = 1 << 20
+
+func NewShareWriter(w io.Writer, cols []string, encoding string) (*ShareWriter, error) {
+	_, err := encryption.EncodingWidth(encoding)
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.WriteString(w, strings.Join(cols, ",")+"\n"+encoding+"\n")
+	if err != nil {
+		return nil, err
+	}
+
+	return &ShareWriter{w: w, encoding: encoding}, nil
+}
+
+func (s *ShareWriter) Write(vec []*big.Int) error {
+	if s.closed {
+		return fmt.Errorf("write to closed share stream")
+	}
+	if len(vec) == 0 {
+		return nil
+	}
+	if int64(len(vec)) > math.MaxUint32 {
+		return fmt.Errorf("block too long")
+	}
+	msg, err := encryption.EncodeVec(vec, s.encoding)
+	if err != nil {
+		return err
+	}
+	_, err = s.w.Write(binary.BigEndian.AppendUint32(nil, uint32(len(vec))))
+	if err != nil {
+		return err
+	}
+	_, err = s.w.Write(msg)
+
+	return err
+}
+
+// Close ends the elements and writes the trailer, which may be empty.
+// It does not close the underlying writer.
+func (s *ShareWriter) Close(trailer []byte) error {
+	if s.closed {
+		return fmt.Errorf("share stream already closed")
+	}
+	if len(trailer) > MaxShareTrailer {
+		return fmt.Errorf("trailer too long")
+	}
+	s.closed = true
+	_, err := s.w.Write(make([]byte, 4))
+	if err != nil {
+		return err
+	}
+	_, err = s.w.Write(trailer)
+
+	return err
+}
+
+// ShareReader reads a share vector written by ShareWriter.
+type ShareReader struct {
+	r     *bufio.Reader
+	cols  []string
+	width int
+	buf   []byte
+	left  int
+	done  bool
+}
+
+func NewShareReader(r io.Reader) (*ShareReader, error) {
+	reader := bufio.NewReader(r)
+	text, err := Readln(reader)
+	if err != nil {
+		return nil, err
+	}
+	cols := strings.Split(text, ",")
+	encoding, err := Readln(reader)
+	if err != nil {
+		return nil, err
+	}
+	width, err := encryption.EncodingWidth(encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ShareReader{r: reader, cols: cols, width: width}, nil
+}
+
+func (s *ShareReader) Cols() []string {
+	return s.cols
+}
+
+// Read returns the next at most n elements of the share. It returns
+// io.EOF once all the elements were read, and an error if the stream
+// ends before the end of the elements.
+func (s *ShareReader) Read(n int) ([]*big.Int, error) {
+	res := make([]*big.Int, 0, n)
+	for len(res) < n && !s.done {
+		if s.left == 0 {
+			var count [4]byte
+			_, err := io.ReadFull(s.r, count[:])
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return nil, fmt.Errorf("share stream truncated")
+			}
+			if err != nil {
+				return nil, err
+			}
+			s.left = int(binary.BigEndian.Uint32(count[:]))
+			s.done = s.left == 0
+			continue
+		}
+
+		k := n - len(res)
+		if k > s.left {
+			k = s.left
+		}
+		if cap(s.buf) < k*s.width {
+			s.buf = make([]byte, k*s.width)
+		}
+		buf := s.buf[:k*s.width]
+		_, err := io.ReadFull(s.r, buf)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, fmt.Errorf("share stream truncated")
+		}
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < k; i++ {
+			res = append(res, new(big.Int).SetBytes(buf[i*s.width:(i+1)*s.width]))
+		}
+		s.left -= k
+	}
+	if len(res) == 0 && n > 0 {
+		return nil, io.EOF
+	}
+
+	return res, nil
+}
+
+// ReadAll reads the remaining elements of the share.
+func (s *ShareReader) ReadAll() ([]*big.Int, error) {
+	res := make([]*big.Int, 0)
+	for {
+		vec, err := s.Read(len(s.cols))
+		if err == io.EOF {
+			return res, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, vec...)
+	}
+}
+
+// Trailer returns the trailer of the stream. It must be called after
+// all the elements were read.
+func (s *ShareReader) Trailer() ([]byte, error) {
+	if !s.done {
+		return nil, fmt.Errorf("elements of the share not read")
+	}
+	res, err := io.ReadAll(io.LimitReader(s.r, MaxShareTrailer+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(res) > MaxShareTrailer {
+		return nil, fmt.Errorf("trailer too long")
+	}
+
+	return res, nil
+}
+
+// CsvRowReader reads CSV data row by row as fixed point values. Reading
+// stops at the first empty line, after which Readln returns the lines
+// following the data, such as the signature of a signed CSV file.
+type CsvRowReader struct {
+	r    *bufio.Reader
+	cols []string
+	rows int
+	done bool
+}
+
+func NewCsvRowReader(r io.Reader) (*CsvRowReader, error) {
+	reader := bufio.NewReader(r)
+	text, err := Readln(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CsvRowReader{r: reader, cols: strings.Split(text, ",")}, nil
+}
+
+func (c *CsvRowReader) Cols() []string {
+	return c.cols
+}
+
+// Rows returns the number of rows read so far.
+func (c *CsvRowReader) Rows() int {
+	return c.rows
+}
+
+// Next reads the next row into row, which has one element per column.
+// It returns io.EOF after the last row.
+func (c *CsvRowReader) Next(row []*big.Int) error {
+	if c.done {
+		return io.EOF
+	}
+	text, err := Readln(c.r)
+	if err == io.EOF || (err == nil && text == "") {
+		c.done = true
+		return io.EOF
+	}
+	if err != nil {
+		return err
+	}
+
+	vals := strings.Split(text, ",")
+	if len(vals) != len(c.cols) || len(row) != len(c.cols) {
+		return fmt.Errorf("row %d has %d values, expected %d", c.rows+1, len(vals), len(c.cols))
+	}
+	for j, e := range vals {
+		f, err := strconv.ParseFloat(e, 64)
+		if err != nil {
+			return err
+		}
+		v, err := FloatToFixInt(f)
+		if err != nil {
+			return err
+		}
+		row[j] = new(big.Int).SetInt64(v)
+	}
+	c.rows++
+
+	return nil
+}
+
+// Readln returns the next line after the data.
+func (c *CsvRowReader) Readln() (string, error) {
+	if !c.done {
+		return "", fmt.Errorf("rows of the csv not read")
+	}
+
+	return Readln(c.r)
+}
+
+// OpenShareStream decrypts a share stream written by SplitCsvStream.
+func OpenShareStream(r io.Reader, pubKey, secKey []byte) (*ShareReader, error) {
+	dec, err := encryption.NewStreamReader(r, pubKey, secKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewShareReader(dec)
+}
+
+// SplitCsvStream splits the CSV data row by row into 3 shares and
+// writes each share to outputs[i], encrypted with the streaming
+// encryption for pubKeys[i], so that the memory use does not depend
+// on the size of the dataset. Reading stops at the first empty line,
+// hence signed CSV files can be split as well. It returns the columns
+// and the number of rows. The shares are not authenticated, see
+// ZKPComponent.CsvSplitEncryptAndZkpStream for shares with commitments
+// and a proof that node agents can verify.
+func SplitCsvStream(r io.Reader, outputs []io.Writer, pubKeys [][]byte) ([]string, int, error) {
+	if len(outputs) != 3 || len(pubKeys) != 3 {
+		return nil, 0, fmt.Errorf("expected 3 outputs and 3 keys")
+	}
+	rows, err := NewCsvRowReader(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	cols := rows.Cols()
+
+	streams := make([]*encryption.StreamWriter, 3)
+	writers := make([]*ShareWriter, 3)
+	for i := 0; i < 3; i++ {
+		streams[i], err = encryption.NewStreamWriter(outputs[i], pubKeys[i])
+		if err != nil {
+			return nil, 0, err
+		}
+		writers[i], err = NewShareWriter(streams[i], cols, encryption.EncodingBin32)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	row := make([]*big.Int, len(cols))
+	for {
+		err = rows.Next(row)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+
+		shares, err := CreateSharesShamir(row)
+		if err != nil {
+			return nil, 0, err
+		}
+		for i := 0; i < 3; i++ {
+			err = writers[i].Write(shares[i])
+			if err != nil {
+				return nil, 0, err
+			}
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		err = writers[i].Close(nil)
+		if err != nil {
+			return nil, 0, err
+		}
+		err = streams[i].Close()
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return cols, rows.Rows(), nil
+}
+
+// SplitCsvFileStream is SplitCsvStream from file to the files outputs.
+func SplitCsvFileStream(file string, outputs []string, pubKeys [][]byte) ([]string, int, error) {
+	if len(outputs) != 3 {
+		return nil, 0, fmt.Errorf("expected 3 outputs")
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	files := make([]*os.File, 3)
+	bufs := make([]*bufio.Writer, 3)
+	ws := make([]io.Writer, 3)
+	for i := 0; i < 3; i++ {
+		files[i], err = os.Create(outputs[i])
+		if err != nil {
+			return nil, 0, err
+		}
+		defer files[i].Close()
+		bufs[i] = bufio.NewWriter(files[i])
+		ws[i] = bufs[i]
+	}
+
+	cols, rows, err := SplitCsvStream(f, ws, pubKeys)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := 0; i < 3; i++ {
+		err = bufs[i].Flush()
+		if err != nil {
+			return nil, 0, err
+		}
+		err = files[i].Close()
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return cols, rows, nil
+}
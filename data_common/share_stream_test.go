@@ -0,0 +1,84 @@
+package data_common
+
+import (
+	"bytes"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/krakenh2020/ZKPComponent/key_management"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCsvStreamSplitJoin(t *testing.T) {
+	pubKey, secKey := key_management.GenerateKeypair()
+	pubKeys := [][]byte{pubKey, pubKey, pubKey}
+
+	dir := t.TempDir()
+	outputs := []string{filepath.Join(dir, "0"), filepath.Join(dir, "1"), filepath.Join(dir, "2")}
+	cols, rows, err := SplitCsvFileStream("../datasets/framingham_small.csv", outputs, pubKeys)
+	assert.NoError(t, err)
+	assert.Equal(t, 500, rows)
+
+	text, err := os.ReadFile("../datasets/framingham_small.csv")
+	assert.NoError(t, err)
+	vec, _, _, err := CsvTextToVec(string(bytes.SplitN(text, []byte("\n\n"), 2)[0]))
+	assert.NoError(t, err)
+
+	shares := make([][]*big.Int, 3)
+	for i := 0; i < 3; i++ {
+		f, err := os.Open(outputs[i])
+		assert.NoError(t, err)
+		r, err := OpenShareStream(f, pubKey, secKey)
+		assert.NoError(t, err)
+		assert.Equal(t, cols, r.Cols())
+		shares[i], err = r.ReadAll()
+		assert.NoError(t, err)
+		f.Close()
+	}
+
+	res, err := JoinSharesShamir(shares)
+	assert.NoError(t, err)
+	assert.Equal(t, len(vec), len(res))
+	for i := range vec {
+		assert.Equal(t, 0, vec[i].Cmp(res[i]), i)
+	}
+}
+
+func TestShareStreamTruncated(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewShareWriter(&buf, []string{"a", "b"}, "bin16")
+	assert.NoError(t, err)
+	assert.NoError(t, w.Write([]*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3)}))
+	assert.NoError(t, w.Close([]byte("trailer")))
+	trailerLen := len("trailer")
+
+	r, err := NewShareReader(bytes.NewReader(buf.Bytes()[:buf.Len()-trailerLen-1]))
+	assert.NoError(t, err)
+	vec, err := r.Read(2)
+	assert.NoError(t, err)
+	assert.Equal(t, []*big.Int{big.NewInt(1), big.NewInt(2)}, vec)
+	_, err = r.Read(2)
+	assert.Error(t, err)
+
+	r, err = NewShareReader(bytes.NewReader(buf.Bytes()))
+	assert.NoError(t, err)
+	vec, err = r.ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(vec))
+	_, err = r.Read(2)
+	assert.Equal(t, io.EOF, err)
+	trailer, err := r.Trailer()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("trailer"), trailer)
+
+	// the elements must end with an empty block
+	r, err = NewShareReader(bytes.NewReader(buf.Bytes()[:buf.Len()-trailerLen-4]))
+	assert.NoError(t, err)
+	_, err = r.ReadAll()
+	assert.Error(t, err)
+	_, err = r.Trailer()
+	assert.Error(t, err)
+}
@@ -12,14 +12,111 @@ import (
 	"golang.org/x/crypto/nacl/box"
 )
 
+// Encodings of the vector before encryption. The JSON encoding is the
+// original one, the binary encodings write each element as unsigned
+// big-endian bytes of fixed width.
+const (
+	EncodingJSON  = ""
+	EncodingBin16 = "bin16"
+	EncodingBin32 = "bin32"
+)
+
 type VecEnc struct {
-	Key []byte
-	Iv  []byte
-	Val []byte
+	Key      []byte
+	Iv       []byte
+	Val      []byte
+	Encoding string `json:",omitempty"`
+}
+
+// CompactEncoding returns the most compact encoding of the vector: a
+// binary encoding if all the elements are non-negative and fit in 32
+// bytes, the JSON encoding otherwise.
+func CompactEncoding(input []*big.Int) string {
+	maxLen := 0
+	for _, e := range input {
+		if e.Sign() < 0 {
+			return EncodingJSON
+		}
+		if e.BitLen() > maxLen {
+			maxLen = e.BitLen()
+		}
+	}
+	if maxLen <= 128 {
+		return EncodingBin16
+	}
+	if maxLen <= 256 {
+		return EncodingBin32
+	}
+
+	return EncodingJSON
+}
+
+// EncodingWidth returns the byte length of an element in a binary
+// encoding.
+func EncodingWidth(encoding string) (int, error) {
+	switch encoding {
+	case EncodingBin16:
+		return 16, nil
+	case EncodingBin32:
+		return 32, nil
+	}
+
+	return 0, fmt.Errorf("unknown encoding %q", encoding)
+}
+
+func EncodeVec(input []*big.Int, encoding string) ([]byte, error) {
+	if encoding == EncodingJSON {
+		return json.Marshal(input)
+	}
+	width, err := EncodingWidth(encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]byte, width*len(input))
+	for i, e := range input {
+		if e.Sign() < 0 || e.BitLen() > 8*width {
+			return nil, fmt.Errorf("element %d does not fit the encoding", i)
+		}
+		e.FillBytes(res[i*width : (i+1)*width])
+	}
+
+	return res, nil
+}
+
+func DecodeVec(msg []byte, encoding string) ([]*big.Int, error) {
+	if encoding == EncodingJSON {
+		var res []*big.Int
+		err := json.Unmarshal(msg, &res)
+		return res, err
+	}
+	width, err := EncodingWidth(encoding)
+	if err != nil {
+		return nil, err
+	}
+	if len(msg)%width != 0 {
+		return nil, fmt.Errorf("message length does not match the encoding")
+	}
+
+	res := make([]*big.Int, len(msg)/width)
+	for i := range res {
+		res[i] = new(big.Int).SetBytes(msg[i*width : (i+1)*width])
+	}
+
+	return res, nil
 }
 
+// EncryptVec encrypts the vector with its compact encoding, see
+// CompactEncoding.
 func EncryptVec(input []*big.Int, pubKey []byte) (*VecEnc, error) {
-	inputBytes, err := json.Marshal(input)
+	return EncryptVecWithEncoding(input, pubKey, CompactEncoding(input))
+}
+
+func EncryptVecWithEncoding(input []*big.Int, pubKey []byte, encoding string) (*VecEnc, error) {
+	inputBytes, err := EncodeVec(input, encoding)
+	if err != nil {
+		return nil, err
+	}
 
 	// prepare keys
 	key := make([]byte, 32)
@@ -58,9 +155,12 @@ func EncryptVec(input []*big.Int, pubKey []byte) (*VecEnc, error) {
 		return nil, err
 	}
 
-	return &VecEnc{Key: keyEnc, Iv: iv, Val: symEnc}, nil
+	return &VecEnc{Key: keyEnc, Iv: iv, Val: symEnc, Encoding: encoding}, nil
 }
 
+// DecVec decrypts a vector encrypted by EncryptVec. Malformed
+// ciphertexts, which may come from untrusted containers, are refused
+// with an error.
 func DecVec(encVec *VecEnc, pubKey, secKey []byte) ([]*big.Int, error) {
 	// prepare keys
 	key, err := Decrypt(encVec.Key, pubKey, secKey)
@@ -72,6 +172,12 @@ func DecVec(encVec *VecEnc, pubKey, secKey []byte) ([]*big.Int, error) {
 	if err != nil {
 		return nil, err
 	}
+	if len(encVec.Iv) != c.BlockSize() {
+		return nil, fmt.Errorf("invalid IV length %d", len(encVec.Iv))
+	}
+	if len(encVec.Val) == 0 || len(encVec.Val)%c.BlockSize() != 0 {
+		return nil, fmt.Errorf("invalid ciphertext length %d", len(encVec.Val))
+	}
 
 	msgPad := make([]byte, len(encVec.Val))
 	decrypter := cipher.NewCBCDecrypter(c, encVec.Iv)
@@ -79,18 +185,17 @@ func DecVec(encVec *VecEnc, pubKey, secKey []byte) ([]*big.Int, error) {
 
 	// unpad the message
 	padLen := int(msgPad[len(msgPad)-1])
-	if (len(msgPad) - padLen) < 0 {
+	if padLen == 0 || padLen > c.BlockSize() {
 		return nil, fmt.Errorf("failed to decrypt")
 	}
-	msgByte := msgPad[0:(len(msgPad) - padLen)]
-
-	var res []*big.Int
-	err = json.Unmarshal(msgByte, &res)
-	if err != nil {
-		return nil, err
+	for _, b := range msgPad[len(msgPad)-padLen:] {
+		if int(b) != padLen {
+			return nil, fmt.Errorf("failed to decrypt")
+		}
 	}
+	msgByte := msgPad[0:(len(msgPad) - padLen)]
 
-	return res, nil
+	return DecodeVec(msgByte, encVec.Encoding)
 }
 
 func Encrypt(input, pubkey []byte) ([]byte, error) {
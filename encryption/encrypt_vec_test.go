@@ -2,10 +2,12 @@ package encryption_test
 
 import (
 	"github.com/krakenh2020/ZKPComponent/encryption"
+	"math/big"
 	"testing"
 
 	"github.com/krakenh2020/ZKPComponent/data_common"
 	"github.com/krakenh2020/ZKPComponent/key_management"
+	"github.com/krakenh2020/ZKPComponent/signature"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -42,4 +44,72 @@ func TestEncVec(t *testing.T) {
 	assert.NoError(t, err)
 
 	assert.Equal(t, a, d)
+
+	// malformed ciphertexts are refused without panicking
+	for _, tamper := range []func(e *encryption.VecEnc){
+		func(e *encryption.VecEnc) { e.Val = nil },
+		func(e *encryption.VecEnc) { e.Val = e.Val[:len(e.Val)-1] },
+		func(e *encryption.VecEnc) { e.Iv = e.Iv[:8] },
+		func(e *encryption.VecEnc) { e.Val = e.Val[:16] },
+	} {
+		bad := *e
+		tamper(&bad)
+		_, err = encryption.DecVec(&bad, pubKey, secKey)
+		assert.Error(t, err)
+	}
+}
+
+func TestEncVecEncodings(t *testing.T) {
+	pubKey, secKey := key_management.GenerateKeypair()
+	small, err := data_common.NewUniformRandomVector(100, data_common.MPCPrimeHalf)
+	assert.NoError(t, err)
+	large, err := data_common.NewUniformRandomVector(100, new(big.Int).Lsh(big.NewInt(1), 256))
+	assert.NoError(t, err)
+	signed := []*big.Int{big.NewInt(-1), big.NewInt(2)}
+
+	for _, e := range []struct {
+		vec      []*big.Int
+		encoding string
+	}{{small, encryption.EncodingBin16}, {large, encryption.EncodingBin32}, {signed, encryption.EncodingJSON}} {
+		enc, err := encryption.EncryptVec(e.vec, pubKey)
+		assert.NoError(t, err)
+		assert.Equal(t, e.encoding, enc.Encoding)
+		dec, err := encryption.DecVec(enc, pubKey, secKey)
+		assert.NoError(t, err)
+		assert.Equal(t, e.vec, dec)
+	}
+
+	_, err = encryption.EncryptVecWithEncoding(large, pubKey, encryption.EncodingBin16)
+	assert.Error(t, err)
+}
+
+func benchmarkEncryptVec(b *testing.B, compact bool) {
+	vec, _, _, _, _, err := signature.CsvToVecAuth("../datasets/framingham_small.csv")
+	assert.NoError(b, err)
+	shares, err := data_common.CreateSharesShamir(vec)
+	assert.NoError(b, err)
+	pubKey, secKey := key_management.GenerateKeypair()
+	encoding := encryption.EncodingJSON
+	if compact {
+		encoding = encryption.CompactEncoding(shares[0])
+	}
+
+	b.ResetTimer()
+	var size int
+	for i := 0; i < b.N; i++ {
+		enc, err := encryption.EncryptVecWithEncoding(shares[0], pubKey, encoding)
+		assert.NoError(b, err)
+		_, err = encryption.DecVec(enc, pubKey, secKey)
+		assert.NoError(b, err)
+		size = len(enc.Val)
+	}
+	b.ReportMetric(float64(size), "bytes")
+}
+
+func BenchmarkEncryptVecJSON(b *testing.B) {
+	benchmarkEncryptVec(b, false)
+}
+
+func BenchmarkEncryptVecBinary(b *testing.B) {
+	benchmarkEncryptVec(b, true)
 }
@@ -0,0 +1,225 @@
+package encryption
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// StreamChunkSize is the size of the plaintext segments of the
+// streaming encryption.
+const StreamChunkSize = 64 << 10
+
+const (
+	streamVersion   = 1
+	streamPrefixLen = 7
+	encKeyLen       = 32 + 48 // NaCl anonymous box of a 32 bytes key
+	streamHeaderLen = 1 + encKeyLen + streamPrefixLen + 4
+)
+
+// StreamWriter encrypts a stream of unbounded length with bounded
+// memory, using the STREAM construction over AES-GCM: the stream is cut
+// into segments of StreamChunkSize bytes, each encrypted with a nonce
+// made of a random prefix, the index of the segment and a flag marking
+// the last segment, so that reordering, dropping and truncating
+// segments are detected. The AES key is encrypted with the NaCl public
+// key of the recipient in the header of the stream.
+type StreamWriter struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	header  []byte
+	prefix  []byte
+	counter uint32
+	buf     []byte
+	closed  bool
+}
+
+func NewStreamWriter(w io.Writer, pubKey []byte) (*StreamWriter, error) {
+	key := make([]byte, 32)
+	_, err := io.ReadFull(rand.Reader, key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newStreamAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	keyEnc, err := Encrypt(key, pubKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(keyEnc) != encKeyLen {
+		return nil, fmt.Errorf("unexpected length of the encrypted key")
+	}
+
+	header := make([]byte, 0, streamHeaderLen)
+	header = append(header, streamVersion)
+	header = append(header, keyEnc...)
+	prefix := make([]byte, streamPrefixLen)
+	_, err = io.ReadFull(rand.Reader, prefix)
+	if err != nil {
+		return nil, err
+	}
+	header = append(header, prefix...)
+	header = binary.BigEndian.AppendUint32(header, StreamChunkSize)
+
+	_, err = w.Write(header)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StreamWriter{w: w, aead: aead, header: header, prefix: prefix, buf: make([]byte, 0, StreamChunkSize)}, nil
+}
+
+func newStreamAEAD(key []byte) (cipher.AEAD, error) {
+	c, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(c)
+}
+
+func streamNonce(prefix []byte, counter uint32, last bool) []byte {
+	nonce := make([]byte, 0, streamPrefixLen+5)
+	nonce = append(nonce, prefix...)
+	nonce = binary.BigEndian.AppendUint32(nonce, counter)
+	if last {
+		return append(nonce, 1)
+	}
+
+	return append(nonce, 0)
+}
+
+func (s *StreamWriter) seal(last bool) error {
+	if s.counter == ^uint32(0) {
+		return fmt.Errorf("stream too long")
+	}
+	ct := s.aead.Seal(nil, streamNonce(s.prefix, s.counter, last), s.buf, s.header)
+	s.counter++
+	s.buf = s.buf[:0]
+	_, err := s.w.Write(ct)
+
+	return err
+}
+
+func (s *StreamWriter) Write(p []byte) (int, error) {
+	if s.closed {
+		return 0, fmt.Errorf("write to closed stream")
+	}
+	n := 0
+	for len(p) > 0 {
+		// a full segment is only sealed once more data arrives, since
+		// the last segment must be flagged
+		if len(s.buf) == StreamChunkSize {
+			err := s.seal(false)
+			if err != nil {
+				return n, err
+			}
+		}
+		k := copy(s.buf[len(s.buf):StreamChunkSize], p)
+		s.buf = s.buf[:len(s.buf)+k]
+		p = p[k:]
+		n += k
+	}
+
+	return n, nil
+}
+
+// Close writes the last segment. It does not close the underlying writer.
+func (s *StreamWriter) Close() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	return s.seal(true)
+}
+
+// StreamReader decrypts a stream written by StreamWriter. Read returns
+// an error if the stream was modified or truncated.
+type StreamReader struct {
+	r       *bufio.Reader
+	aead    cipher.AEAD
+	header  []byte
+	prefix  []byte
+	chunk   int
+	counter uint32
+	buf     []byte
+	seg     []byte
+	done    bool
+}
+
+func NewStreamReader(r io.Reader, pubKey, secKey []byte) (*StreamReader, error) {
+	header := make([]byte, streamHeaderLen)
+	_, err := io.ReadFull(r, header)
+	if err != nil {
+		return nil, err
+	}
+	if header[0] != streamVersion {
+		return nil, fmt.Errorf("unknown stream version %d", header[0])
+	}
+	key, err := Decrypt(header[1:1+encKeyLen], pubKey, secKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newStreamAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	chunk := int(binary.BigEndian.Uint32(header[1+encKeyLen+streamPrefixLen:]))
+	if chunk <= 0 || chunk > 16<<20 {
+		return nil, fmt.Errorf("invalid segment size %d", chunk)
+	}
+
+	return &StreamReader{r: bufio.NewReaderSize(r, chunk+aead.Overhead()+1), aead: aead, header: header,
+		prefix: header[1+encKeyLen : 1+encKeyLen+streamPrefixLen], chunk: chunk,
+		seg: make([]byte, chunk+aead.Overhead())}, nil
+}
+
+func (s *StreamReader) next() error {
+	n, err := io.ReadFull(s.r, s.seg)
+	last := false
+	if err == io.ErrUnexpectedEOF || err == io.EOF {
+		last = true
+	} else if err != nil {
+		return err
+	} else if _, err = s.r.Peek(1); err == io.EOF {
+		last = true
+	} else if err != nil {
+		return err
+	}
+
+	pt, err := s.aead.Open(s.buf[:0], streamNonce(s.prefix, s.counter, last), s.seg[:n], s.header)
+	if err != nil {
+		if last {
+			return fmt.Errorf("stream truncated or modified")
+		}
+		return fmt.Errorf("stream modified")
+	}
+	s.counter++
+	s.buf = pt
+	s.done = last
+
+	return nil
+}
+
+func (s *StreamReader) Read(p []byte) (int, error) {
+	for len(s.buf) == 0 {
+		if s.done {
+			return 0, io.EOF
+		}
+		err := s.next()
+		if err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, s.buf)
+	s.buf = s.buf[n:]
+
+	return n, nil
+}
@@ -0,0 +1,81 @@
+package encryption_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"testing"
+
+	"github.com/krakenh2020/ZKPComponent/encryption"
+	"github.com/krakenh2020/ZKPComponent/key_management"
+	"github.com/stretchr/testify/assert"
+)
+
+func encryptStream(t *testing.T, text, pubKey []byte) []byte {
+	var buf bytes.Buffer
+	w, err := encryption.NewStreamWriter(&buf, pubKey)
+	assert.NoError(t, err)
+	// write in odd sized pieces to cross segment boundaries
+	for len(text) > 0 {
+		k := 1000
+		if k > len(text) {
+			k = len(text)
+		}
+		_, err = w.Write(text[:k])
+		assert.NoError(t, err)
+		text = text[k:]
+	}
+	assert.NoError(t, w.Close())
+
+	return buf.Bytes()
+}
+
+func TestStreamEncDec(t *testing.T) {
+	pubKey, secKey := key_management.GenerateKeypair()
+
+	for _, n := range []int{0, 1, encryption.StreamChunkSize - 1, encryption.StreamChunkSize,
+		3*encryption.StreamChunkSize + 5} {
+		text := make([]byte, n)
+		_, err := rand.Read(text)
+		assert.NoError(t, err)
+
+		enc := encryptStream(t, text, pubKey)
+		r, err := encryption.NewStreamReader(bytes.NewReader(enc), pubKey, secKey)
+		assert.NoError(t, err)
+		dec, err := io.ReadAll(r)
+		assert.NoError(t, err)
+		assert.Equal(t, text, append([]byte{}, dec...), n)
+	}
+}
+
+func TestStreamTampered(t *testing.T) {
+	pubKey, secKey := key_management.GenerateKeypair()
+	text := make([]byte, 2*encryption.StreamChunkSize+100)
+	enc := encryptStream(t, text, pubKey)
+
+	decrypt := func(enc []byte) error {
+		r, err := encryption.NewStreamReader(bytes.NewReader(enc), pubKey, secKey)
+		if err != nil {
+			return err
+		}
+		_, err = io.ReadAll(r)
+		return err
+	}
+	assert.NoError(t, decrypt(enc))
+
+	// truncation at a segment boundary
+	segment := encryption.StreamChunkSize + 16
+	headerLen := len(enc) - 2*segment - (100 + 16)
+	assert.Error(t, decrypt(enc[:headerLen+2*segment]))
+
+	// modified ciphertext
+	mod := append([]byte{}, enc...)
+	mod[headerLen+segment+5] ^= 1
+	assert.Error(t, decrypt(mod))
+
+	// swapped segments
+	swap := append([]byte{}, enc...)
+	copy(swap[headerLen:], enc[headerLen+segment:headerLen+2*segment])
+	copy(swap[headerLen+segment:], enc[headerLen:headerLen+segment])
+	assert.Error(t, decrypt(swap))
+}
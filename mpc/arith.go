@@ -0,0 +1,99 @@
+package mpc
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/krakenh2020/ZKPComponent/data_common"
+)
+
+// Add returns the shares of x + y.
+func Add(x, y []*big.Int) []*big.Int {
+	res := make([]*big.Int, len(x))
+	for i := range x {
+		res[i] = new(big.Int).Add(x[i], y[i])
+		res[i].Mod(res[i], data_common.MPCPrime)
+	}
+
+	return res
+}
+
+// Sub returns the shares of x - y.
+func Sub(x, y []*big.Int) []*big.Int {
+	res := make([]*big.Int, len(x))
+	for i := range x {
+		res[i] = new(big.Int).Sub(x[i], y[i])
+		res[i].Mod(res[i], data_common.MPCPrime)
+	}
+
+	return res
+}
+
+// AddPublic returns the shares of x + c for a public vector c. Since
+// the sharing polynomial of a constant is constant, every node simply
+// adds c to its share.
+func AddPublic(x, c []*big.Int) []*big.Int {
+	return Add(x, c)
+}
+
+// MulPublic returns the shares of x * c for a public vector c.
+func MulPublic(x, c []*big.Int) []*big.Int {
+	res := make([]*big.Int, len(x))
+	for i := range x {
+		res[i] = new(big.Int).Mul(x[i], c[i])
+		res[i].Mod(res[i], data_common.MPCPrime)
+	}
+
+	return res
+}
+
+// Open reveals the values shared by x to all the nodes. The result is
+// given as signed values, as in data_common.JoinSharesShamir, and the
+// opening fails if the shares received are inconsistent.
+func (node *Node) Open(x []*big.Int) ([]*big.Int, error) {
+	shares, err := node.Broadcast(x)
+	if err != nil {
+		return nil, err
+	}
+
+	return data_common.JoinSharesShamir(shares)
+}
+
+// Mul returns the shares of the element-wise product of x and y,
+// consuming a Beaver triple for each element: given a triple (a, b, c)
+// the nodes open d = x - a and e = y - b and compute
+// x * y = c + d * b + e * a + d * e.
+func (node *Node) Mul(x, y []*big.Int) ([]*big.Int, error) {
+	if len(x) != len(y) {
+		return nil, fmt.Errorf("vectors of different length")
+	}
+	n := len(x)
+
+	t, err := node.dealer.Triples(node.nextSeq(), node.Id, n)
+	if err != nil {
+		return nil, err
+	}
+
+	de, err := node.Open(append(Sub(x, t.A), Sub(y, t.B)...))
+	if err != nil {
+		return nil, err
+	}
+	d, e := de[:n], de[n:]
+
+	res := make([]*big.Int, n)
+	tmp := new(big.Int)
+	for i := 0; i < n; i++ {
+		res[i] = new(big.Int).Set(t.C[i])
+		res[i].Add(res[i], tmp.Mul(d[i], t.B[i]))
+		res[i].Add(res[i], tmp.Mul(e[i], t.A[i]))
+		res[i].Add(res[i], tmp.Mul(d[i], e[i]))
+		res[i].Mod(res[i], data_common.MPCPrime)
+	}
+
+	return res, nil
+}
+
+func (node *Node) nextSeq() int {
+	node.seq++
+	return node.seq
+}
@@ -0,0 +1,57 @@
+package mpc
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/krakenh2020/ZKPComponent/data_common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMul(t *testing.T) {
+	n := 100
+	bound := new(big.Int).Lsh(big.NewInt(1), 60)
+	x, err := data_common.NewUniformRangeRandomVector(n, new(big.Int).Neg(bound), bound)
+	assert.NoError(t, err)
+	y, err := data_common.NewUniformRangeRandomVector(n, new(big.Int).Neg(bound), bound)
+	assert.NoError(t, err)
+
+	xShares, err := data_common.CreateSharesShamir(x)
+	assert.NoError(t, err)
+	yShares, err := data_common.CreateSharesShamir(y)
+	assert.NoError(t, err)
+
+	res, err := RunNodes(NewDealer(), func(node *Node) ([]*big.Int, error) {
+		xy, err := node.Mul(xShares[node.Id], yShares[node.Id])
+		if err != nil {
+			return nil, err
+		}
+		// x * y * y - x
+		xyy, err := node.Mul(xy, yShares[node.Id])
+		if err != nil {
+			return nil, err
+		}
+		return Sub(xyy, xShares[node.Id]), nil
+	})
+	assert.NoError(t, err)
+
+	z, err := data_common.JoinSharesShamir(res)
+	assert.NoError(t, err)
+	for i := 0; i < n; i++ {
+		check := new(big.Int).Mul(x[i], y[i])
+		check.Mul(check, y[i])
+		check.Sub(check, x[i])
+		check.Mod(check, data_common.MPCPrime)
+		assert.Equal(t, 0, check.Cmp(new(big.Int).Mod(z[i], data_common.MPCPrime)))
+	}
+}
+
+func TestRunNodesAbort(t *testing.T) {
+	_, err := RunNodes(NewDealer(), func(node *Node) ([]*big.Int, error) {
+		if node.Id == 1 {
+			return nil, assert.AnError
+		}
+		return node.Open([]*big.Int{big.NewInt(1)})
+	})
+	assert.ErrorIs(t, err, assert.AnError)
+}
@@ -0,0 +1,290 @@
+package mpc
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/krakenh2020/ZKPComponent/data_common"
+)
+
+// CompareK bounds the values that can be compared: the difference of
+// the compared fixed-point values must be smaller than 2^(CompareK-1)
+// in absolute value.
+const CompareK = 48
+
+// Op is a comparison operator of a WHERE-style condition.
+type Op int
+
+const (
+	Less Op = iota
+	LessEq
+	Greater
+	GreaterEq
+)
+
+// BitShares is a share of a vector of random values
+// r = 2^m r' + sum_i 2^i b_i together with the shares of the bits b_i.
+type BitShares struct {
+	R    []*big.Int
+	Bits [][]*big.Int
+}
+
+// RandomBits returns the share of node with the given id of n random
+// values with m shared low bits, for the request with sequence number seq.
+func (d *Dealer) RandomBits(seq, id, n, m int) (*BitShares, error) {
+	t, err := d.deal(seq, id, func() ([]interface{}, error) {
+		bits, err := NewRandomBits(n, m)
+		if err != nil {
+			return nil, err
+		}
+		res := make([]interface{}, NumNodes)
+		for i := range bits {
+			res[i] = bits[i]
+		}
+		return res, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return t.(*BitShares), nil
+}
+
+// NewRandomBits generates n random values with m shared low bits and
+// returns their shares, one for each node.
+func NewRandomBits(n, m int) ([]*BitShares, error) {
+	high, err := data_common.NewUniformRandomVector(n, new(big.Int).Lsh(big.NewInt(1), uint(CompareK+TruncKappa-m)))
+	if err != nil {
+		return nil, err
+	}
+	bits := make([][]*big.Int, m)
+	for b := 0; b < m; b++ {
+		bits[b], err = data_common.NewUniformRandomVector(n, big.NewInt(2))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	r := make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		r[i] = new(big.Int).Lsh(high[i], uint(m))
+		for b := 0; b < m; b++ {
+			r[i].Add(r[i], new(big.Int).Lsh(bits[b][i], uint(b)))
+		}
+	}
+
+	res := make([]*BitShares, NumNodes)
+	for i := 0; i < NumNodes; i++ {
+		res[i] = &BitShares{Bits: make([][]*big.Int, m)}
+	}
+	rShares, err := shareField(r)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < NumNodes; i++ {
+		res[i].R = rShares[i]
+	}
+	for b := 0; b < m; b++ {
+		bitShares, err := shareField(bits[b])
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < NumNodes; i++ {
+			res[i].Bits[b] = bitShares[i]
+		}
+	}
+
+	return res, nil
+}
+
+// BitLT returns the shares of the bits [c < r] for public values c
+// and shared values r given by their bits. The bits are scanned from
+// the most significant one, keeping track of whether the prefixes are
+// still equal, which costs one multiplication per bit.
+func (node *Node) BitLT(c []*big.Int, bits [][]*big.Int) ([]*big.Int, error) {
+	n := len(c)
+	one := big.NewInt(1)
+	lt := constVec(n, big.NewInt(0))
+	eq := constVec(n, one)
+
+	for b := len(bits) - 1; b >= 0; b-- {
+		t, err := node.Mul(eq, bits[b])
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < n; i++ {
+			if c[i].Bit(b) == 0 {
+				// lt += t, eq -= t
+				lt[i].Add(lt[i], t[i])
+				eq[i].Sub(eq[i], t[i])
+			} else {
+				// eq = t
+				eq[i].Set(t[i])
+			}
+			lt[i].Mod(lt[i], data_common.MPCPrime)
+			eq[i].Mod(eq[i], data_common.MPCPrime)
+		}
+	}
+
+	return lt, nil
+}
+
+// Mod2m returns the shares of x mod 2^m, for |x| < 2^(CompareK-1).
+func (node *Node) Mod2m(x []*big.Int, m int) ([]*big.Int, error) {
+	if m <= 0 || m >= CompareK {
+		return nil, fmt.Errorf("cannot reduce modulo 2^%d", m)
+	}
+	n := len(x)
+	r, err := node.dealer.RandomBits(node.nextSeq(), node.Id, n, m)
+	if err != nil {
+		return nil, err
+	}
+
+	offset := new(big.Int).Lsh(big.NewInt(1), CompareK-1)
+	masked := make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		masked[i] = new(big.Int).Add(x[i], r.R[i])
+		masked[i].Add(masked[i], offset)
+		masked[i].Mod(masked[i], data_common.MPCPrime)
+	}
+	c, err := node.Open(masked)
+	if err != nil {
+		return nil, err
+	}
+
+	mod2m := new(big.Int).Lsh(big.NewInt(1), uint(m))
+	for i := 0; i < n; i++ {
+		if c[i].Sign() < 0 {
+			return nil, fmt.Errorf("value out of the comparison range")
+		}
+		c[i].Mod(c[i], mod2m)
+	}
+
+	u, err := node.BitLT(c, r.Bits)
+	if err != nil {
+		return nil, err
+	}
+
+	// x mod 2^m = c mod 2^m - r mod 2^m + 2^m [c mod 2^m < r mod 2^m]
+	res := make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		res[i] = new(big.Int).Lsh(u[i], uint(m))
+		res[i].Add(res[i], c[i])
+		for b := 0; b < m; b++ {
+			res[i].Sub(res[i], new(big.Int).Lsh(r.Bits[b][i], uint(b)))
+		}
+		res[i].Mod(res[i], data_common.MPCPrime)
+	}
+
+	return res, nil
+}
+
+// Trunc returns the shares of x / 2^m rounded down, for
+// |x| < 2^(CompareK-1).
+func (node *Node) Trunc(x []*big.Int, m int) ([]*big.Int, error) {
+	xMod, err := node.Mod2m(x, m)
+	if err != nil {
+		return nil, err
+	}
+	inv := new(big.Int).ModInverse(new(big.Int).Lsh(big.NewInt(1), uint(m)), data_common.MPCPrime)
+
+	return MulPublic(Sub(x, xMod), constVec(len(x), inv)), nil
+}
+
+// LTZ returns the shares of the bits [x < 0], obtained as the
+// negated truncation of x by CompareK-1 bits.
+func (node *Node) LTZ(x []*big.Int) ([]*big.Int, error) {
+	t, err := node.Trunc(x, CompareK-1)
+	if err != nil {
+		return nil, err
+	}
+
+	return Sub(constVec(len(x), big.NewInt(0)), t), nil
+}
+
+// LessThan returns the shares of the bits [x < y].
+func (node *Node) LessThan(x, y []*big.Int) ([]*big.Int, error) {
+	return node.LTZ(Sub(x, y))
+}
+
+// Compare returns the shares of the bits [x op c] for fixed-point
+// shared values x and a public threshold c.
+func (node *Node) Compare(x []*big.Int, op Op, c float64) ([]*big.Int, error) {
+	cFix, err := data_common.FloatToFixInt(c)
+	if err != nil {
+		return nil, err
+	}
+	cVec := constVec(len(x), big.NewInt(cFix))
+	ones := constVec(len(x), big.NewInt(1))
+
+	switch op {
+	case Less:
+		return node.LessThan(x, cVec)
+	case LessEq:
+		gt, err := node.LessThan(cVec, x)
+		if err != nil {
+			return nil, err
+		}
+		return Sub(ones, gt), nil
+	case Greater:
+		return node.LessThan(cVec, x)
+	case GreaterEq:
+		lt, err := node.LessThan(x, cVec)
+		if err != nil {
+			return nil, err
+		}
+		return Sub(ones, lt), nil
+	}
+
+	return nil, fmt.Errorf("unknown comparison operator")
+}
+
+// Where returns the shares of the bits selecting the rows of the
+// dataset whose column col satisfies the condition op c.
+func (node *Node) Where(share []*big.Int, nCols, col int, op Op, c float64) ([]*big.Int, error) {
+	if _, err := Rows(share, nCols); err != nil {
+		return nil, err
+	}
+	if col < 0 || col >= nCols {
+		return nil, fmt.Errorf("column %d out of range", col)
+	}
+
+	return node.Compare(Column(share, nCols, col), op, c)
+}
+
+// CountWhere returns the share of the number of rows whose column col
+// satisfies the condition op c, as a fixed-point value.
+func (node *Node) CountWhere(share []*big.Int, nCols, col int, op Op, c float64) ([]*big.Int, error) {
+	sel, err := node.Where(share, nCols, col, op, c)
+	if err != nil {
+		return nil, err
+	}
+
+	count := new(big.Int)
+	for i := range sel {
+		count.Add(count, sel[i])
+	}
+	count.Lsh(count, data_common.FixF)
+	count.Mod(count, data_common.MPCPrime)
+
+	return []*big.Int{count}, nil
+}
+
+// SumWhere returns the share of the sum of column sumCol over the rows
+// whose column col satisfies the condition op c.
+func (node *Node) SumWhere(share []*big.Int, nCols, col int, op Op, c float64, sumCol int) ([]*big.Int, error) {
+	if sumCol < 0 || sumCol >= nCols {
+		return nil, fmt.Errorf("column %d out of range", sumCol)
+	}
+	sel, err := node.Where(share, nCols, col, op, c)
+	if err != nil {
+		return nil, err
+	}
+
+	selected, err := node.Mul(sel, Column(share, nCols, sumCol))
+	if err != nil {
+		return nil, err
+	}
+
+	return ColumnSum(selected, 1)
+}
@@ -0,0 +1,74 @@
+package mpc
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/krakenh2020/ZKPComponent/data_common"
+	"github.com/krakenh2020/ZKPComponent/signature"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLessThan(t *testing.T) {
+	n := 50
+	x, xShares := randomFixVector(t, n, 1000)
+	y, yShares := randomFixVector(t, n, 1000)
+	// equal values
+	xShares[0][0], xShares[1][0], xShares[2][0] = yShares[0][0], yShares[1][0], yShares[2][0]
+	x[0] = y[0]
+
+	res, err := RunNodes(NewDealer(), func(node *Node) ([]*big.Int, error) {
+		return node.LessThan(xShares[node.Id], yShares[node.Id])
+	})
+	assert.NoError(t, err)
+	lt, err := data_common.JoinSharesShamir(res)
+	assert.NoError(t, err)
+
+	xFix, err := data_common.JoinSharesShamir(xShares)
+	assert.NoError(t, err)
+	yFix, err := data_common.JoinSharesShamir(yShares)
+	assert.NoError(t, err)
+	for i := 0; i < n; i++ {
+		expected := int64(0)
+		if xFix[i].Cmp(yFix[i]) < 0 {
+			expected = 1
+		}
+		assert.Equal(t, expected, lt[i].Int64())
+	}
+}
+
+func countWhereFramingham(tb testing.TB, threshold float64) (float64, float64) {
+	vec, cols, vecFloat, _, _, err := signature.CsvToVecAuth("../datasets/framingham_small.csv")
+	assert.NoError(tb, err)
+	nCols := len(cols)
+	sysBP := 10
+	assert.Equal(tb, "sysBP", cols[sysBP])
+
+	shares, err := data_common.CreateSharesShamir(vec)
+	assert.NoError(tb, err)
+
+	res, err := RunNodes(NewDealer(), func(node *Node) ([]*big.Int, error) {
+		return node.CountWhere(shares[node.Id], nCols, sysBP, Greater, threshold)
+	})
+	assert.NoError(tb, err)
+
+	expected := 0.0
+	for i := sysBP; i < len(vecFloat); i += nCols {
+		if vecFloat[i] > threshold {
+			expected++
+		}
+	}
+
+	return expected, data_common.JoinSharesShamirFloat(res)[0]
+}
+
+func TestCountWhere(t *testing.T) {
+	expected, count := countWhereFramingham(t, 140)
+	assert.Equal(t, expected, count)
+}
+
+func BenchmarkCountWhere(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		countWhereFramingham(b, 140)
+	}
+}
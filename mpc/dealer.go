@@ -0,0 +1,143 @@
+package mpc
+
+import (
+	"crypto/rand"
+	"math/big"
+	"sync"
+
+	"github.com/krakenh2020/ZKPComponent/data_common"
+)
+
+// TripleShares is a share of a vector of Beaver triples (a, b, c)
+// with c = a * b mod MPCPrime.
+type TripleShares struct {
+	A []*big.Int
+	B []*big.Int
+	C []*big.Int
+}
+
+// Dealer is a trusted dealer handing out correlated randomness to
+// the nodes. It is meant for testing: the dealer knows all the
+// secrets it generates. Nodes ask for randomness in the same order,
+// so the i-th request of every node gets a share of the same values.
+type Dealer struct {
+	mu      sync.Mutex
+	pending map[int]*dealt
+}
+
+type dealt struct {
+	shares  []interface{}
+	fetched int
+}
+
+func NewDealer() *Dealer {
+	return &Dealer{pending: make(map[int]*dealt)}
+}
+
+// deal returns the share of node id for the request with sequence
+// number seq, calling gen to create the shares of all the nodes if
+// this is the first node asking for it.
+func (d *Dealer) deal(seq, id int, gen func() ([]interface{}, error)) (interface{}, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	e, ok := d.pending[seq]
+	if !ok {
+		shares, err := gen()
+		if err != nil {
+			return nil, err
+		}
+		e = &dealt{shares: shares}
+		d.pending[seq] = e
+	}
+
+	e.fetched++
+	if e.fetched == NumNodes {
+		delete(d.pending, seq)
+	}
+
+	return e.shares[id], nil
+}
+
+// Triples returns the share of node with the given id of n Beaver
+// triples for the request with sequence number seq.
+func (d *Dealer) Triples(seq, id, n int) (*TripleShares, error) {
+	t, err := d.deal(seq, id, func() ([]interface{}, error) {
+		triples, err := NewBeaverTriples(n)
+		if err != nil {
+			return nil, err
+		}
+		res := make([]interface{}, NumNodes)
+		for i := range triples {
+			res[i] = triples[i]
+		}
+		return res, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return t.(*TripleShares), nil
+}
+
+// NewBeaverTriples generates n random Beaver triples and returns
+// their shares, one for each node.
+func NewBeaverTriples(n int) ([]*TripleShares, error) {
+	a, err := data_common.NewUniformRandomVector(n, data_common.MPCPrime)
+	if err != nil {
+		return nil, err
+	}
+	b, err := data_common.NewUniformRandomVector(n, data_common.MPCPrime)
+	if err != nil {
+		return nil, err
+	}
+	c := make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		c[i] = new(big.Int).Mul(a[i], b[i])
+		c[i].Mod(c[i], data_common.MPCPrime)
+	}
+
+	aShares, err := shareField(a)
+	if err != nil {
+		return nil, err
+	}
+	bShares, err := shareField(b)
+	if err != nil {
+		return nil, err
+	}
+	cShares, err := shareField(c)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*TripleShares, NumNodes)
+	for i := 0; i < NumNodes; i++ {
+		res[i] = &TripleShares{A: aShares[i], B: bShares[i], C: cShares[i]}
+	}
+
+	return res, nil
+}
+
+// shareField splits a vector of elements of Z_MPCPrime the same way
+// as data_common.CreateSharesShamir, without interpreting them as
+// signed values.
+func shareField(input []*big.Int) ([][]*big.Int, error) {
+	res := make([][]*big.Int, NumNodes)
+	for i := 0; i < NumNodes; i++ {
+		res[i] = make([]*big.Int, len(input))
+	}
+
+	for j := 0; j < len(input); j++ {
+		a, err := rand.Int(rand.Reader, data_common.MPCPrime)
+		if err != nil {
+			return nil, err
+		}
+		for i := int64(0); i < NumNodes; i++ {
+			res[i][j] = new(big.Int).Mul(a, big.NewInt(i+1))
+			res[i][j].Add(res[i][j], input[j])
+			res[i][j].Mod(res[i][j], data_common.MPCPrime)
+		}
+	}
+
+	return res, nil
+}
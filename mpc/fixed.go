@@ -0,0 +1,194 @@
+package mpc
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/krakenh2020/ZKPComponent/data_common"
+)
+
+// TruncK bounds the values that can be truncated: the shared value x
+// must satisfy |x| < 2^(TruncK-1). TruncKappa is the statistical
+// security parameter of the masking, TruncK + TruncKappa must stay
+// below the bit length of MPCPrime.
+const (
+	TruncK     = 86
+	TruncKappa = 40
+)
+
+// divShift are the additional fractional bits used to represent the
+// inverse of a public divisor.
+const divShift = data_common.FixF
+
+// TruncShares is a share of a vector of random values r = 2^m r0 + r1
+// with 0 <= r1 < 2^m, used to truncate m bits. RLow is the share of r1.
+type TruncShares struct {
+	R    []*big.Int
+	RLow []*big.Int
+}
+
+// TruncPairs returns the share of node with the given id of n random
+// truncation pairs for m bits, for the request with sequence number seq.
+func (d *Dealer) TruncPairs(seq, id, n, m int) (*TruncShares, error) {
+	t, err := d.deal(seq, id, func() ([]interface{}, error) {
+		pairs, err := NewTruncPairs(n, m)
+		if err != nil {
+			return nil, err
+		}
+		res := make([]interface{}, NumNodes)
+		for i := range pairs {
+			res[i] = pairs[i]
+		}
+		return res, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return t.(*TruncShares), nil
+}
+
+// NewTruncPairs generates n random truncation pairs for m bits and
+// returns their shares, one for each node.
+func NewTruncPairs(n, m int) ([]*TruncShares, error) {
+	if m <= 0 || m >= TruncK {
+		return nil, fmt.Errorf("cannot truncate %d bits", m)
+	}
+	low, err := data_common.NewUniformRandomVector(n, new(big.Int).Lsh(big.NewInt(1), uint(m)))
+	if err != nil {
+		return nil, err
+	}
+	high, err := data_common.NewUniformRandomVector(n, new(big.Int).Lsh(big.NewInt(1), uint(TruncK+TruncKappa-m)))
+	if err != nil {
+		return nil, err
+	}
+
+	r := make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		r[i] = new(big.Int).Lsh(high[i], uint(m))
+		r[i].Add(r[i], low[i])
+	}
+
+	rShares, err := shareField(r)
+	if err != nil {
+		return nil, err
+	}
+	lowShares, err := shareField(low)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*TruncShares, NumNodes)
+	for i := 0; i < NumNodes; i++ {
+		res[i] = &TruncShares{R: rShares[i], RLow: lowShares[i]}
+	}
+
+	return res, nil
+}
+
+// TruncPr returns the shares of x / 2^m, rounded probabilistically
+// to one of the two nearest integers (Catrina and Saxena). The nodes
+// open c = x + 2^(TruncK-1) + r, which statistically hides x, and
+// compute (x - (c mod 2^m) + r1) / 2^m.
+func (node *Node) TruncPr(x []*big.Int, m int) ([]*big.Int, error) {
+	n := len(x)
+	t, err := node.dealer.TruncPairs(node.nextSeq(), node.Id, n, m)
+	if err != nil {
+		return nil, err
+	}
+
+	offset := new(big.Int).Lsh(big.NewInt(1), TruncK-1)
+	masked := make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		masked[i] = new(big.Int).Add(x[i], t.R[i])
+		masked[i].Add(masked[i], offset)
+		masked[i].Mod(masked[i], data_common.MPCPrime)
+	}
+	c, err := node.Open(masked)
+	if err != nil {
+		return nil, err
+	}
+
+	mod2m := new(big.Int).Lsh(big.NewInt(1), uint(m))
+	inv := new(big.Int).ModInverse(mod2m, data_common.MPCPrime)
+	res := make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		if c[i].Sign() < 0 {
+			return nil, fmt.Errorf("value out of the truncation range")
+		}
+		cLow := new(big.Int).Mod(c[i], mod2m)
+		res[i] = new(big.Int).Sub(x[i], cLow)
+		res[i].Add(res[i], t.RLow[i])
+		res[i].Mul(res[i], inv)
+		res[i].Mod(res[i], data_common.MPCPrime)
+	}
+
+	return res, nil
+}
+
+// MulFix returns the shares of the product of fixed-point values
+// x and y, encoded as in data_common.FloatToFixInt.
+func (node *Node) MulFix(x, y []*big.Int) ([]*big.Int, error) {
+	xy, err := node.Mul(x, y)
+	if err != nil {
+		return nil, err
+	}
+
+	return node.TruncPr(xy, data_common.FixF)
+}
+
+// MulPublicFix returns the shares of the fixed-point values x
+// multiplied by the public value c.
+func (node *Node) MulPublicFix(x []*big.Int, c float64) ([]*big.Int, error) {
+	cFix, err := data_common.FloatToFixInt(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return node.TruncPr(MulPublic(x, constVec(len(x), big.NewInt(cFix))), data_common.FixF)
+}
+
+// DivPublic returns the shares of the fixed-point values x divided by
+// the public value d >= 1. The inverse of d is represented with
+// additional fractional bits, so that dividing by large counts (e.g.
+// the number of rows when computing a mean) keeps its precision.
+// TruncPr needs |x| 2^(FixF+divShift) / d < 2^(TruncK-1): bound is a
+// public bound on the absolute values of x, as fixed-point integers,
+// and an error is returned when it does not ensure it.
+func (node *Node) DivPublic(x []*big.Int, d float64, bound *big.Int) ([]*big.Int, error) {
+	inv, err := divisor(d)
+	if err != nil {
+		return nil, err
+	}
+	if bound.Sign() < 0 || new(big.Int).Mul(bound, inv).BitLen() >= TruncK {
+		return nil, fmt.Errorf("values out of the range of the division")
+	}
+
+	return node.TruncPr(MulPublic(x, constVec(len(x), inv)), data_common.FixF+divShift)
+}
+
+// divisor returns the inverse of the public value d >= 1 with
+// FixF+divShift fractional bits.
+func divisor(d float64) (*big.Int, error) {
+	if !(d >= 1) || math.IsInf(d, 1) {
+		return nil, fmt.Errorf("divisor out of range")
+	}
+	inv := math.Round(math.Pow(2, data_common.FixF+divShift) / d)
+	if inv == 0 {
+		return nil, fmt.Errorf("divisor out of range")
+	}
+
+	return big.NewInt(int64(inv)), nil
+}
+
+// constVec returns a vector of length n with all the entries equal
+// to the (possibly negative) value c reduced modulo MPCPrime.
+func constVec(n int, c *big.Int) []*big.Int {
+	res := make([]*big.Int, n)
+	for i := range res {
+		res[i] = new(big.Int).Mod(c, data_common.MPCPrime)
+	}
+
+	return res
+}
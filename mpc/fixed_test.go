@@ -0,0 +1,67 @@
+package mpc
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	mrand "math/rand"
+	"testing"
+
+	"github.com/krakenh2020/ZKPComponent/data_common"
+	"github.com/stretchr/testify/assert"
+)
+
+func randomFixVector(t *testing.T, n int, bound float64) ([]float64, [][]*big.Int) {
+	vals := make([]float64, n)
+	vec := make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		vals[i] = (mrand.Float64()*2 - 1) * bound
+		v, err := data_common.FloatToFixInt(vals[i])
+		assert.NoError(t, err)
+		vec[i] = big.NewInt(v)
+	}
+	shares, err := data_common.CreateSharesShamir(vec)
+	assert.NoError(t, err)
+
+	return vals, shares
+}
+
+func TestFixedPointArithmetic(t *testing.T) {
+	n := 50
+	x, xShares := randomFixVector(t, n, 1000)
+	y, yShares := randomFixVector(t, n, 1000)
+
+	res, err := RunNodes(NewDealer(), func(node *Node) ([]*big.Int, error) {
+		p, err := node.MulFix(xShares[node.Id], yShares[node.Id])
+		if err != nil {
+			return nil, err
+		}
+		s, err := node.MulPublicFix(xShares[node.Id], -0.25)
+		if err != nil {
+			return nil, err
+		}
+		bound := big.NewInt(1000 << data_common.FixF)
+		d, err := node.DivPublic(xShares[node.Id], 3999, bound)
+		if err != nil {
+			return nil, err
+		}
+		// divisors below 1 and bounds out of the range of TruncPr
+		if _, err := node.DivPublic(xShares[node.Id], 0.5, bound); err == nil {
+			return nil, fmt.Errorf("divisor 0.5 accepted")
+		}
+		if _, err := node.DivPublic(xShares[node.Id], 3999, new(big.Int).Lsh(bound, 40)); err == nil {
+			return nil, fmt.Errorf("bound 2^50 accepted")
+		}
+		return append(append(p, s...), d...), nil
+	})
+	assert.NoError(t, err)
+
+	out := data_common.JoinSharesShamirFloat(res)
+	eps := math.Pow(2, -data_common.FixF+1)
+	for i := 0; i < n; i++ {
+		// the inputs are rounded to FixF fractional bits
+		assert.InDelta(t, x[i]*y[i], out[i], eps*(math.Abs(x[i])+math.Abs(y[i])+1))
+		assert.InDelta(t, x[i]*-0.25, out[n+i], eps)
+		assert.InDelta(t, x[i]/3999, out[2*n+i], eps)
+	}
+}
@@ -0,0 +1,148 @@
+package mpc
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+// NumNodes is the number of MPC nodes, matching the 3-party
+// Shamir sharing of data_common.CreateSharesShamir.
+const NumNodes = 3
+
+// Network is an in-process message-passing network connecting the
+// MPC nodes. Every ordered pair of nodes has its own buffered channel,
+// so that a node can send to all the other nodes before receiving.
+type Network struct {
+	links [NumNodes][NumNodes]chan []*big.Int
+	done  chan struct{}
+	once  sync.Once
+}
+
+func NewNetwork() *Network {
+	n := &Network{done: make(chan struct{})}
+	for i := 0; i < NumNodes; i++ {
+		for j := 0; j < NumNodes; j++ {
+			if i != j {
+				n.links[i][j] = make(chan []*big.Int, 16)
+			}
+		}
+	}
+
+	return n
+}
+
+// Abort unblocks all the nodes waiting for a message, it is called
+// when one of the nodes fails and will not send anything anymore.
+func (n *Network) Abort() {
+	n.once.Do(func() { close(n.done) })
+}
+
+func (n *Network) Send(from, to int, msg []*big.Int) error {
+	select {
+	case n.links[from][to] <- msg:
+		return nil
+	case <-n.done:
+		return fmt.Errorf("network aborted")
+	}
+}
+
+func (n *Network) Recv(from, to int) ([]*big.Int, error) {
+	select {
+	case msg := <-n.links[from][to]:
+		return msg, nil
+	case <-n.done:
+		return nil, fmt.Errorf("network aborted")
+	}
+}
+
+// Node is a single MPC node running in the simulator. It holds
+// shares of the data and obtains correlated randomness (e.g. Beaver
+// triples) from a dealer.
+type Node struct {
+	Id     int
+	net    *Network
+	dealer *Dealer
+	seq    int
+}
+
+func NewNode(id int, net *Network, dealer *Dealer) *Node {
+	return &Node{Id: id, net: net, dealer: dealer}
+}
+
+func (node *Node) Send(to int, msg []*big.Int) error {
+	return node.net.Send(node.Id, to, msg)
+}
+
+func (node *Node) Recv(from int) ([]*big.Int, error) {
+	return node.net.Recv(from, node.Id)
+}
+
+// Broadcast sends msg to all the other nodes and returns the
+// messages received from them, indexed by the node id. The entry
+// of the calling node is msg itself.
+func (node *Node) Broadcast(msg []*big.Int) ([][]*big.Int, error) {
+	res := make([][]*big.Int, NumNodes)
+	for j := 0; j < NumNodes; j++ {
+		if j == node.Id {
+			continue
+		}
+		err := node.Send(j, msg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	for j := 0; j < NumNodes; j++ {
+		if j == node.Id {
+			res[j] = msg
+			continue
+		}
+		res[j], err = node.Recv(j)
+		if err != nil {
+			return nil, err
+		}
+		if len(res[j]) != len(msg) {
+			return nil, fmt.Errorf("node %d sent a message of wrong length", j)
+		}
+	}
+
+	return res, nil
+}
+
+// RunNodes runs f on the three nodes, each in its own goroutine,
+// connected by a fresh Network. It returns the outputs of the nodes
+// indexed by their id, or the first error that occurred.
+func RunNodes(dealer *Dealer, f func(node *Node) ([]*big.Int, error)) ([][]*big.Int, error) {
+	net := NewNetwork()
+	res := make([][]*big.Int, NumNodes)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+	for i := 0; i < NumNodes; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			out, err := f(NewNode(i, net, dealer))
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("node %d: %w", i, err)
+				}
+				mu.Unlock()
+				net.Abort()
+				return
+			}
+			res[i] = out
+		}(i)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return res, nil
+}
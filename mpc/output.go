@@ -0,0 +1,142 @@
+package mpc
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	sig "github.com/consensys/gnark-crypto/signature"
+	"github.com/krakenh2020/ZKPComponent/data_common"
+	"github.com/krakenh2020/ZKPComponent/encryption"
+)
+
+// ResultShare is the share of the result of a query held by one node,
+// encrypted to the buyer's key and signed by the node.
+type ResultShare struct {
+	NodeId int
+	Enc    *encryption.VecEnc
+	Sig    []byte
+}
+
+// ResultBundle collects the result shares of all the nodes for a
+// query, it is what the buyer receives.
+type ResultBundle struct {
+	QueryId string
+	Shares  []*ResultShare
+}
+
+// resultShareMessage returns the bytes signed by a node: the query id,
+// the node id and the encrypted share with its encoding, each prefixed
+// by its length.
+func resultShareMessage(queryId string, nodeId int, enc *encryption.VecEnc) []byte {
+	var buf bytes.Buffer
+	for _, e := range [][]byte{[]byte(queryId), enc.Key, enc.Iv, enc.Val, []byte(enc.Encoding)} {
+		_ = binary.Write(&buf, binary.BigEndian, uint64(len(e)))
+		buf.Write(e)
+	}
+	_ = binary.Write(&buf, binary.BigEndian, uint64(nodeId))
+
+	return buf.Bytes()
+}
+
+// NewResultShare encrypts the result share of node nodeId to the buyer's
+// NaCl public key and signs it with the node's signing key.
+func NewResultShare(queryId string, nodeId int, share []*big.Int, buyerPubKey []byte, signer sig.Signer) (*ResultShare, error) {
+	enc, err := encryption.EncryptVec(share, buyerPubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := signer.Sign(resultShareMessage(queryId, nodeId, enc), sha256.New())
+	if err != nil {
+		return nil, err
+	}
+
+	return &ResultShare{NodeId: nodeId, Enc: enc, Sig: s}, nil
+}
+
+// NewResultBundle collects the result shares of the nodes, sorting
+// them by node id.
+func NewResultBundle(queryId string, shares []*ResultShare) (*ResultBundle, error) {
+	if len(shares) != NumNodes {
+		return nil, fmt.Errorf("expected %d result shares, got %d", NumNodes, len(shares))
+	}
+
+	res := make([]*ResultShare, NumNodes)
+	for _, e := range shares {
+		if e.NodeId < 0 || e.NodeId >= NumNodes || res[e.NodeId] != nil {
+			return nil, fmt.Errorf("invalid or duplicated node id %d", e.NodeId)
+		}
+		res[e.NodeId] = e
+	}
+
+	return &ResultBundle{QueryId: queryId, Shares: res}, nil
+}
+
+// OpenResultBundle verifies the signatures of the nodes on their result
+// shares and decrypts them with the buyer's keys. The signing public
+// keys of the nodes are given in the order of their ids.
+func OpenResultBundle(bundle *ResultBundle, nodePubKeys []sig.PublicKey, buyerPubKey, buyerSecKey []byte) ([][]*big.Int, error) {
+	if len(bundle.Shares) != NumNodes || len(nodePubKeys) != NumNodes {
+		return nil, fmt.Errorf("expected %d result shares and keys", NumNodes)
+	}
+
+	shares := make([][]*big.Int, NumNodes)
+	for i, e := range bundle.Shares {
+		if e == nil || e.NodeId != i || e.Enc == nil {
+			return nil, fmt.Errorf("missing result share of node %d", i)
+		}
+		check, err := nodePubKeys[i].Verify(e.Sig, resultShareMessage(bundle.QueryId, i, e.Enc), sha256.New())
+		if err != nil {
+			return nil, err
+		}
+		if !check {
+			return nil, fmt.Errorf("invalid signature of node %d", i)
+		}
+
+		shares[i], err = encryption.DecVec(e.Enc, buyerPubKey, buyerSecKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for i := 1; i < NumNodes; i++ {
+		if len(shares[i]) != len(shares[0]) {
+			return nil, fmt.Errorf("result shares of different length")
+		}
+	}
+
+	return shares, nil
+}
+
+// ReconstructResult verifies and decrypts the result bundle and joins
+// the result shares, see OpenResultBundle.
+func ReconstructResult(bundle *ResultBundle, nodePubKeys []sig.PublicKey, buyerPubKey, buyerSecKey []byte) ([]*big.Int, error) {
+	shares, err := OpenResultBundle(bundle, nodePubKeys, buyerPubKey, buyerSecKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return data_common.JoinSharesShamir(shares)
+}
+
+// ReconstructResultFloat is as ReconstructResult, interpreting the
+// result as fixed-point values.
+func ReconstructResultFloat(bundle *ResultBundle, nodePubKeys []sig.PublicKey, buyerPubKey, buyerSecKey []byte) ([]float64, error) {
+	res, err := ReconstructResult(bundle, nodePubKeys, buyerPubKey, buyerSecKey)
+	if err != nil {
+		return nil, err
+	}
+
+	resFloat := make([]float64, len(res))
+	for i, e := range res {
+		if !e.IsInt64() {
+			return nil, fmt.Errorf("result out of the fixed-point range")
+		}
+		resFloat[i] = data_common.FixIntToFloat(e.Int64())
+	}
+
+	return resFloat, nil
+}
@@ -0,0 +1,67 @@
+package mpc
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	sig "github.com/consensys/gnark-crypto/signature"
+	"github.com/krakenh2020/ZKPComponent/data_common"
+	"github.com/krakenh2020/ZKPComponent/encryption"
+	"github.com/krakenh2020/ZKPComponent/key_management"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResultBundle(t *testing.T) {
+	signers := make([]sig.Signer, NumNodes)
+	nodePubKeys := make([]sig.PublicKey, NumNodes)
+	for i := 0; i < NumNodes; i++ {
+		var err error
+		signers[i], err = eddsa.GenerateKey(rand.Reader)
+		assert.NoError(t, err)
+		nodePubKeys[i] = signers[i].Public()
+	}
+	buyerPubKey, buyerSecKey := key_management.GenerateKeypair()
+
+	vec, cols, vecFloat, err := data_common.CsvToVec("../datasets/framingham_tiny.csv")
+	assert.NoError(t, err)
+	shares, err := data_common.CreateSharesShamir(vec)
+	assert.NoError(t, err)
+
+	resultShares := make([]*ResultShare, NumNodes)
+	_, err = RunNodes(NewDealer(), func(node *Node) ([]*big.Int, error) {
+		mean, err := node.ColumnMean(shares[node.Id], len(cols))
+		if err != nil {
+			return nil, err
+		}
+		resultShares[node.Id], err = NewResultShare("query1", node.Id, mean, buyerPubKey, signers[node.Id])
+		return mean, err
+	})
+	assert.NoError(t, err)
+
+	bundle, err := NewResultBundle("query1", []*ResultShare{resultShares[2], resultShares[0], resultShares[1]})
+	assert.NoError(t, err)
+
+	mean, err := ReconstructResultFloat(bundle, nodePubKeys, buyerPubKey, buyerSecKey)
+	assert.NoError(t, err)
+	rows := len(vec) / len(cols)
+	for j := range cols {
+		sum := 0.0
+		for i := 0; i < rows; i++ {
+			sum += vecFloat[i*len(cols)+j]
+		}
+		assert.InDelta(t, sum/float64(rows), mean[j], 0.001)
+	}
+
+	// the encoding is signed: two 16-byte elements also decode as one
+	// 32-byte element
+	enc := *bundle.Shares[1].Enc
+	enc.Encoding = encryption.EncodingBin32
+	assert.NotEqual(t, resultShareMessage("query1", 1, bundle.Shares[1].Enc), resultShareMessage("query1", 1, &enc))
+
+	// a share signed for another query is rejected
+	bundle.QueryId = "query2"
+	_, err = ReconstructResult(bundle, nodePubKeys, buyerPubKey, buyerSecKey)
+	assert.Error(t, err)
+}
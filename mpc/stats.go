@@ -0,0 +1,181 @@
+package mpc
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/krakenh2020/ZKPComponent/data_common"
+)
+
+// ColumnStats are the shares of the column-wise summary statistics of a
+// dataset, all given as fixed-point values so that they can be joined
+// with data_common.JoinSharesShamirFloat.
+type ColumnStats struct {
+	Count    []*big.Int
+	Sum      []*big.Int
+	Mean     []*big.Int
+	Variance []*big.Int
+}
+
+// Rows returns the number of rows of a share of a dataset with nCols
+// columns, stored row after row as in data_common.CsvTextToVec.
+func Rows(share []*big.Int, nCols int) (int, error) {
+	if nCols <= 0 || len(share)%nCols != 0 {
+		return 0, fmt.Errorf("share length %d does not match %d columns", len(share), nCols)
+	}
+
+	return len(share) / nCols, nil
+}
+
+// Column returns the share of column j of the dataset.
+func Column(share []*big.Int, nCols, j int) []*big.Int {
+	rows := len(share) / nCols
+	res := make([]*big.Int, rows)
+	for i := 0; i < rows; i++ {
+		res[i] = share[i*nCols+j]
+	}
+
+	return res
+}
+
+// ColumnSum returns the shares of the sums of the columns. It is a
+// local computation not needing any interaction.
+func ColumnSum(share []*big.Int, nCols int) ([]*big.Int, error) {
+	rows, err := Rows(share, nCols)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*big.Int, nCols)
+	for j := 0; j < nCols; j++ {
+		res[j] = new(big.Int)
+		for i := 0; i < rows; i++ {
+			res[j].Add(res[j], share[i*nCols+j])
+		}
+		res[j].Mod(res[j], data_common.MPCPrime)
+	}
+
+	return res, nil
+}
+
+// ColumnCount returns the shares of the number of values in each
+// column. The count is public, hence shared as a constant.
+func ColumnCount(share []*big.Int, nCols int) ([]*big.Int, error) {
+	rows, err := Rows(share, nCols)
+	if err != nil {
+		return nil, err
+	}
+	count, err := data_common.FloatToFixInt(float64(rows))
+	if err != nil {
+		return nil, err
+	}
+
+	return constVec(nCols, big.NewInt(count)), nil
+}
+
+// ColumnMean returns the shares of the means of the columns.
+func (node *Node) ColumnMean(share []*big.Int, nCols int) ([]*big.Int, error) {
+	rows, err := Rows(share, nCols)
+	if err != nil {
+		return nil, err
+	}
+	sum, err := ColumnSum(share, nCols)
+	if err != nil {
+		return nil, err
+	}
+	// the values are within the range of data_common.FloatToFixInt
+	bound := new(big.Int).Lsh(big.NewInt(int64(rows)), data_common.FixK-1)
+
+	return node.DivPublic(sum, float64(rows), bound)
+}
+
+// ColumnVariance returns the shares of the (population) variances of
+// the columns, computed as the mean of the squares minus the square
+// of the mean. bound is a public bound on the absolute values of the
+// dataset: DivPublic keeps the mean of the squares within the range of
+// TruncPr only for values below about 2^((TruncK-1-2FixF-divShift)/2),
+// 5800 with the default parameters, and an error is returned for
+// larger bounds.
+func (node *Node) ColumnVariance(share []*big.Int, nCols int, bound float64) ([]*big.Int, error) {
+	mean, err := node.ColumnMean(share, nCols)
+	if err != nil {
+		return nil, err
+	}
+
+	return node.columnVariance(share, nCols, mean, bound)
+}
+
+func (node *Node) columnVariance(share []*big.Int, nCols int, mean []*big.Int, bound float64) ([]*big.Int, error) {
+	rows, err := Rows(share, nCols)
+	if err != nil {
+		return nil, err
+	}
+	if !(bound >= 0) {
+		return nil, fmt.Errorf("invalid bound %v", bound)
+	}
+	b, err := data_common.FloatToFixInt(bound)
+	if err != nil {
+		return nil, err
+	}
+	// bound of the sums of the squares, rounded by MulFix
+	sumBound := new(big.Int).Mul(big.NewInt(b), big.NewInt(b))
+	sumBound.Rsh(sumBound, data_common.FixF)
+	sumBound.Add(sumBound, big.NewInt(1))
+	sumBound.Mul(sumBound, big.NewInt(int64(rows)))
+
+	squares, err := node.MulFix(share, share)
+	if err != nil {
+		return nil, err
+	}
+	sumSquares, err := ColumnSum(squares, nCols)
+	if err != nil {
+		return nil, err
+	}
+	meanSquares, err := node.DivPublic(sumSquares, float64(rows), sumBound)
+	if err != nil {
+		return nil, err
+	}
+
+	squareMean, err := node.MulFix(mean, mean)
+	if err != nil {
+		return nil, err
+	}
+
+	return Sub(meanSquares, squareMean), nil
+}
+
+// ColumnStats computes all the column-wise summary statistics of the
+// dataset shared by share, whose values are bounded by bound as in
+// ColumnVariance.
+func (node *Node) ColumnStats(share []*big.Int, nCols int, bound float64) (*ColumnStats, error) {
+	count, err := ColumnCount(share, nCols)
+	if err != nil {
+		return nil, err
+	}
+	sum, err := ColumnSum(share, nCols)
+	if err != nil {
+		return nil, err
+	}
+	mean, err := node.ColumnMean(share, nCols)
+	if err != nil {
+		return nil, err
+	}
+	variance, err := node.columnVariance(share, nCols, mean, bound)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ColumnStats{Count: count, Sum: sum, Mean: mean, Variance: variance}, nil
+}
+
+// Vec returns all the statistics in a single vector: counts, sums,
+// means and variances, each with one entry per column.
+func (s *ColumnStats) Vec() []*big.Int {
+	res := make([]*big.Int, 0, 4*len(s.Count))
+	res = append(res, s.Count...)
+	res = append(res, s.Sum...)
+	res = append(res, s.Mean...)
+	res = append(res, s.Variance...)
+
+	return res
+}
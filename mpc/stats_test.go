@@ -0,0 +1,51 @@
+package mpc
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/krakenh2020/ZKPComponent/data_common"
+	"github.com/krakenh2020/ZKPComponent/signature"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColumnStats(t *testing.T) {
+	vec, cols, vecFloat, _, _, err := signature.CsvToVecAuth("../datasets/framingham_small.csv")
+	assert.NoError(t, err)
+	nCols := len(cols)
+	rows := len(vec) / nCols
+
+	shares, err := data_common.CreateSharesShamir(vec)
+	assert.NoError(t, err)
+
+	res, err := RunNodes(NewDealer(), func(node *Node) ([]*big.Int, error) {
+		s, err := node.ColumnStats(shares[node.Id], nCols, 500)
+		if err != nil {
+			return nil, err
+		}
+		return s.Vec(), nil
+	})
+	assert.NoError(t, err)
+	out := data_common.JoinSharesShamirFloat(res)
+
+	for j := 0; j < nCols; j++ {
+		sum, sumSquares := 0.0, 0.0
+		for i := 0; i < rows; i++ {
+			sum += vecFloat[i*nCols+j]
+			sumSquares += vecFloat[i*nCols+j] * vecFloat[i*nCols+j]
+		}
+		mean := sum / float64(rows)
+		variance := sumSquares/float64(rows) - mean*mean
+
+		assert.Equal(t, float64(rows), out[j])
+		assert.InDelta(t, sum, out[nCols+j], 0.01)
+		assert.InDelta(t, mean, out[2*nCols+j], 0.001)
+		assert.InDelta(t, variance, out[3*nCols+j], 0.01)
+	}
+
+	// values too large for the mean of their squares
+	_, err = RunNodes(NewDealer(), func(node *Node) ([]*big.Int, error) {
+		return node.ColumnVariance(shares[node.Id], nCols, 10000)
+	})
+	assert.Error(t, err)
+}
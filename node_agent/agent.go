@@ -0,0 +1,471 @@
+package node_agent
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/krakenh2020/ZKPComponent"
+	"github.com/krakenh2020/ZKPComponent/data_common"
+	"github.com/krakenh2020/ZKPComponent/encryption"
+	"github.com/krakenh2020/ZKPComponent/signature"
+	"github.com/krakenh2020/ZKPComponent/signature/ec"
+)
+
+// Config configures the agent of one MPC node.
+type Config struct {
+	// NodeId is the index of the node, between 0 and 2.
+	NodeId int
+	// PubKey and SecKey are the NaCl keys of the node.
+	PubKey []byte
+	SecKey []byte
+	// StoreDir is the directory where accepted shares are stored.
+	StoreDir string
+	// VerifyingKeys are the Groth16 keys verifying the dataset proofs,
+	// of the circuit of each signature scheme.
+	VerifyingKeys ZKPComponent.VerifyingKeys
+}
+
+// DatasetStatus is the status of an ingested share container.
+type DatasetStatus struct {
+	DatasetId string
+	Accepted  bool
+	Error     string `json:",omitempty"`
+	Cols      []string
+	Length    int
+	// Commits are the commitments to the shares of an accepted dataset.
+	Commits  []*ec.Ec `json:",omitempty"`
+	Received time.Time
+}
+
+// storedShare is the content of the file of an accepted share. The
+// share is re-encrypted to the node's own key.
+type storedShare struct {
+	Share *encryption.VecEnc
+	Cols  []string
+	Auth  *ZKPComponent.AuthProof
+}
+
+// storedTrailer is the trailer of the stream of a share accepted from a
+// share stream.
+type storedTrailer struct {
+	R    *big.Int
+	Auth *ZKPComponent.AuthProof
+}
+
+// Agent receives share containers for its node, verifies the proof
+// and the commitment of the share, and stores the accepted shares.
+type Agent struct {
+	cfg    Config
+	mu     sync.Mutex
+	status map[string]*DatasetStatus
+	// storeMu serializes the acceptance of the shares
+	storeMu sync.Mutex
+}
+
+const indexFile = "index.json"
+
+// NewAgent creates the agent, loading the status of the datasets
+// already stored in cfg.StoreDir.
+func NewAgent(cfg Config) (*Agent, error) {
+	if cfg.NodeId < 0 || cfg.NodeId >= 3 {
+		return nil, fmt.Errorf("invalid node id %d", cfg.NodeId)
+	}
+	if cfg.VerifyingKeys == nil {
+		return nil, fmt.Errorf("missing verifying keys")
+	}
+	err := os.MkdirAll(cfg.StoreDir, 0700)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Agent{cfg: cfg, status: make(map[string]*DatasetStatus)}
+	index, err := os.ReadFile(filepath.Join(cfg.StoreDir, indexFile))
+	if err == nil {
+		err = json.Unmarshal(index, &a.status)
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// Ingest verifies the share of the node in the container, as written
+// by ZKPComponent.CsvTextSplitEncryptAndZkpToWriter, and stores it if
+// it is accepted. The returned status records why a share was refused.
+// An error is returned if the container has no authentication proof.
+func (a *Agent) Ingest(container []byte) (*DatasetStatus, error) {
+	sts, err := a.IngestBatch([][]byte{container})
+	if err != nil {
+		return nil, err
+	}
+	if sts[0].DatasetId == "" {
+		return nil, errors.New(sts[0].Error)
+	}
+
+	return sts[0], nil
+}
+
+// IngestBatch is Ingest for several containers, verifying their shares
+// together with ZKPComponent.VerifyDatasetSplitAndZKpCsvBatch. The
+// status of a container without authentication proof has an empty
+// DatasetId and is not recorded.
+func (a *Agent) IngestBatch(containers [][]byte) ([]*DatasetStatus, error) {
+	sts := make([]*DatasetStatus, len(containers))
+	aProofs := make([]*ZKPComponent.AuthProof, len(containers))
+	var proofs []ZKPComponent.SplitProof
+	var index []int
+	for i, container := range containers {
+		sts[i] = &DatasetStatus{Received: time.Now().UTC()}
+		aProof, err := ZKPComponent.ReadAuthFrom(bytes.NewReader(container))
+		if err != nil {
+			sts[i].Error = err.Error()
+			continue
+		}
+		if aProof.Sign == nil || len(aProof.Commits) != 3 {
+			sts[i].Error = "incomplete authentication proof"
+			continue
+		}
+		aProofs[i] = aProof
+		sts[i].DatasetId = signature.DatasetId(aProof.Sign)
+		if aProof.Sign.CommitData == nil {
+			sts[i].Error = "dataset not committed with Pedersen commitments"
+			continue
+		}
+
+		p, err := a.splitProof(container, aProof)
+		if err != nil {
+			sts[i].Error = err.Error()
+			continue
+		}
+		proofs = append(proofs, *p)
+		index = append(index, i)
+	}
+
+	errs := ZKPComponent.VerifyDatasetSplitAndZKpCsvBatch(a.cfg.VerifyingKeys, proofs)
+	for j, i := range index {
+		if errs[j] != nil {
+			sts[i].Error = errs[j].Error()
+			continue
+		}
+		share, cols := proofs[j].Split, proofs[j].Cols
+		sts[i].Cols = cols
+		sts[i].Length = (len(share) - 1) / 2
+		err := a.accept(sts[i], aProofs[i].Commits, func() error {
+			return a.store(sts[i].DatasetId, share, cols, aProofs[i])
+		})
+		if err != nil {
+			sts[i].Error = err.Error()
+		}
+	}
+
+	return sts, a.record(sts)
+}
+
+// IngestStream verifies the share stream of the node, as written by
+// ZKPComponent.CsvSplitEncryptAndZkpStream, and stores the share if it
+// is accepted. The share is verified while it is read, see
+// ZKPComponent.ShareStreamReader, and its rows are re-encrypted to the
+// key of the node into a temporary file of the store, kept if the share
+// is accepted. An error is returned if the stream has no
+// authentication proof.
+func (a *Agent) IngestStream(r io.Reader) (*DatasetStatus, error) {
+	reader, err := ZKPComponent.OpenShareStreamReader(r, a.cfg.VerifyingKeys, a.cfg.NodeId, a.cfg.PubKey, a.cfg.SecKey)
+	if err != nil {
+		return nil, err
+	}
+	tmp, err := os.CreateTemp(a.cfg.StoreDir, "stream.tmp")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+	enc, err := encryption.NewStreamWriter(tmp, a.cfg.PubKey)
+	if err != nil {
+		return nil, err
+	}
+	w, err := data_common.NewShareWriter(enc, reader.Cols(), encryption.EncodingBin32)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		row, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		err = w.Write(row)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rShare, aProof, err := reader.Verify()
+	if aProof == nil {
+		return nil, err
+	}
+	st := &DatasetStatus{DatasetId: signature.DatasetId(aProof.Sign), Received: time.Now().UTC()}
+	if err != nil {
+		st.Error = err.Error()
+	} else {
+		st.Cols = reader.Cols()
+		st.Length = reader.Rows() * len(st.Cols)
+		err = a.accept(st, aProof.Commits, func() error {
+			trailer, err := json.Marshal(&storedTrailer{R: rShare, Auth: aProof})
+			if err != nil {
+				return err
+			}
+			err = w.Close(trailer)
+			if err != nil {
+				return err
+			}
+			err = enc.Close()
+			if err != nil {
+				return err
+			}
+			err = tmp.Close()
+			if err != nil {
+				return err
+			}
+
+			return os.Rename(tmp.Name(), a.streamFile(st.DatasetId))
+		})
+		if err != nil {
+			st.Error = err.Error()
+		}
+	}
+
+	return st, a.record([]*DatasetStatus{st})
+}
+
+// accept stores the share of a verified dataset with write and records
+// it as accepted. A dataset accepted before is not stored again, and is
+// refused if its commitments differ: the share would no longer match
+// the shares of the other nodes.
+func (a *Agent) accept(st *DatasetStatus, commits []*ec.Ec, write func() error) error {
+	a.storeMu.Lock()
+	defer a.storeMu.Unlock()
+
+	old, ok := a.Status(st.DatasetId)
+	if ok && old.Accepted {
+		if !equalCommits(old.Commits, commits) {
+			return fmt.Errorf("dataset %s already accepted with other commitments", st.DatasetId)
+		}
+	} else {
+		err := write()
+		if err != nil {
+			return err
+		}
+	}
+	st.Accepted = true
+	st.Commits = commits
+
+	return a.record([]*DatasetStatus{st})
+}
+
+func equalCommits(a, b []*ec.Ec) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] == nil || b[i] == nil || a[i].X == nil || a[i].Y == nil || b[i].X == nil || b[i].Y == nil ||
+			!a[i].Equal(b[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// record updates the status of the ingested datasets.
+func (a *Agent) record(sts []*DatasetStatus) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, st := range sts {
+		if st.DatasetId == "" {
+			continue
+		}
+		if old, ok := a.status[st.DatasetId]; ok && old.Accepted && !st.Accepted {
+			// keep the share accepted before
+			continue
+		}
+		a.status[st.DatasetId] = st
+	}
+
+	return a.saveIndex()
+}
+
+// splitProof decrypts the share of the node in the container.
+func (a *Agent) splitProof(container []byte, aProof *ZKPComponent.AuthProof) (*ZKPComponent.SplitProof, error) {
+	share, cols, err := data_common.ReadShareFrom(bytes.NewReader(container), a.cfg.PubKey, a.cfg.SecKey, a.cfg.NodeId)
+	if err != nil {
+		return nil, err
+	}
+
+	proof, commits, sign, pubKey, err := ZKPComponent.ExpandAuthProof(aProof)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ZKPComponent.SplitProof{Proof: proof, Split: share, Id: a.cfg.NodeId, Commits: commits, Cols: cols,
+		Sign: sign, PubKey: pubKey}, nil
+}
+
+func (a *Agent) shareFile(datasetId string) string {
+	return filepath.Join(a.cfg.StoreDir, datasetId+".share")
+}
+
+// streamFile is the file of a share accepted from a stream, a share
+// stream encrypted to the key of the node with a storedTrailer.
+func (a *Agent) streamFile(datasetId string) string {
+	return filepath.Join(a.cfg.StoreDir, datasetId+".stream")
+}
+
+func (a *Agent) store(datasetId string, share []*big.Int, cols []string, aProof *ZKPComponent.AuthProof) error {
+	enc, err := encryption.EncryptVec(share, a.cfg.PubKey)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(&storedShare{Share: enc, Cols: cols, Auth: aProof})
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(a.shareFile(datasetId), data)
+}
+
+// saveIndex must be called with a.mu held.
+func (a *Agent) saveIndex() error {
+	data, err := json.MarshalIndent(a.status, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(filepath.Join(a.cfg.StoreDir, indexFile), data)
+}
+
+// writeFileAtomic writes data to a temporary file of its own and
+// renames it, so that concurrent writes of the same file do not mix.
+func writeFileAtomic(file string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if err2 := tmp.Close(); err == nil {
+		err = err2
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), file)
+}
+
+// Share returns the decrypted share of an accepted dataset, its columns
+// and its AuthProof.
+func (a *Agent) Share(datasetId string) ([]*big.Int, []string, *ZKPComponent.AuthProof, error) {
+	st, ok := a.Status(datasetId)
+	if !ok || !st.Accepted {
+		return nil, nil, nil, fmt.Errorf("no accepted share for dataset %s", datasetId)
+	}
+
+	f, err := os.Open(a.streamFile(datasetId))
+	if err == nil {
+		defer f.Close()
+		return a.readStream(f)
+	}
+	if !os.IsNotExist(err) {
+		return nil, nil, nil, err
+	}
+
+	data, err := os.ReadFile(a.shareFile(datasetId))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var s storedShare
+	err = json.Unmarshal(data, &s)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	share, err := encryption.DecVec(s.Share, a.cfg.PubKey, a.cfg.SecKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return share, s.Cols, s.Auth, nil
+}
+
+// readStream reads a share stored in the stream of a streamFile.
+func (a *Agent) readStream(r io.Reader) ([]*big.Int, []string, *ZKPComponent.AuthProof, error) {
+	reader, err := data_common.OpenShareStream(r, a.cfg.PubKey, a.cfg.SecKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	n := len(reader.Cols())
+	vals := make([]*big.Int, 0)
+	hides := make([]*big.Int, 0)
+	for {
+		row, err := reader.Read(2 * n)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if len(row) != 2*n {
+			return nil, nil, nil, fmt.Errorf("incomplete row %d", len(vals)/n+1)
+		}
+		vals = append(vals, row[:n]...)
+		hides = append(hides, row[n:]...)
+	}
+	trailerBytes, err := reader.Trailer()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var trailer storedTrailer
+	err = json.Unmarshal(trailerBytes, &trailer)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return append(append(vals, hides...), trailer.R), reader.Cols(), trailer.Auth, nil
+}
+
+func (a *Agent) Status(datasetId string) (DatasetStatus, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	st, ok := a.status[datasetId]
+	if !ok {
+		return DatasetStatus{}, false
+	}
+
+	return *st, true
+}
+
+// List returns the status of all the ingested datasets, sorted by id.
+func (a *Agent) List() []DatasetStatus {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	res := make([]DatasetStatus, 0, len(a.status))
+	for _, e := range a.status {
+		res = append(res, *e)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].DatasetId < res[j].DatasetId })
+
+	return res
+}
@@ -0,0 +1,259 @@
+package node_agent
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	"github.com/krakenh2020/ZKPComponent"
+	"github.com/krakenh2020/ZKPComponent/encryption"
+	"github.com/krakenh2020/ZKPComponent/key_management"
+	"github.com/krakenh2020/ZKPComponent/signature"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAgent(t *testing.T) {
+	signer, err := eddsa.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	dir := t.TempDir()
+	signed := filepath.Join(dir, "signed.csv")
+	sign, err := signature.SignCsv("../datasets/framingham_tiny.csv", signer)
+	assert.NoError(t, err)
+	assert.NoError(t, signature.WriteSignCsv("../datasets/framingham_tiny.csv", signed, sign))
+
+	pk, err := ZKPComponent.LoadProvingKey("../proofKey.txt")
+	assert.NoError(t, err)
+	keys, err := ZKPComponent.LoadLegacyVerifyingKeys("../verifyKey.txt")
+	assert.NoError(t, err)
+	r1cs, err := ZKPComponent.CompileCircuitDataset()
+	assert.NoError(t, err)
+
+	pubKeys := make([][]byte, 3)
+	secKeys := make([][]byte, 3)
+	for i := 0; i < 3; i++ {
+		pubKeys[i], secKeys[i] = key_management.GenerateKeypair()
+	}
+	file := filepath.Join(dir, "enc.txt")
+	splits, _, _, _, _, err := ZKPComponent.DatasetSplitEncryptAndZkpCsvToFile(signed, file, pk, r1cs, pubKeys)
+	assert.NoError(t, err)
+	container, err := os.ReadFile(file)
+	assert.NoError(t, err)
+
+	store := filepath.Join(dir, "store")
+	agent, err := NewAgent(Config{NodeId: 1, PubKey: pubKeys[1], SecKey: secKeys[1], StoreDir: store, VerifyingKeys: keys})
+	assert.NoError(t, err)
+	srv := httptest.NewServer(agent.Handler())
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/datasets", "text/plain", bytes.NewReader(container))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	var st DatasetStatus
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&st))
+	resp.Body.Close()
+	assert.True(t, st.Accepted)
+	assert.Equal(t, signature.DatasetId(sign), st.DatasetId)
+
+	// the stored share survives a restart of the agent
+	agent, err = NewAgent(Config{NodeId: 1, PubKey: pubKeys[1], SecKey: secKeys[1], StoreDir: store, VerifyingKeys: keys})
+	assert.NoError(t, err)
+	share, _, _, err := agent.Share(st.DatasetId)
+	assert.NoError(t, err)
+	assert.Equal(t, splits[1], share)
+
+	// the share of another node does not match the commit of node 0
+	other, err := NewAgent(Config{NodeId: 0, PubKey: pubKeys[1], SecKey: secKeys[1], StoreDir: filepath.Join(dir, "other"), VerifyingKeys: keys})
+	assert.NoError(t, err)
+	lines := bytes.SplitN(container, []byte("\n"), 3)
+	st2, err := other.Ingest(bytes.Replace(container, lines[0], lines[1], 1))
+	assert.NoError(t, err)
+	assert.False(t, st2.Accepted)
+	assert.NotEmpty(t, st2.Error)
+	_, _, _, err = other.Share(st2.DatasetId)
+	assert.Error(t, err)
+
+	// a batch with a share of another node
+	batch, err := NewAgent(Config{NodeId: 1, PubKey: pubKeys[1], SecKey: secKeys[1], StoreDir: filepath.Join(dir, "batch"), VerifyingKeys: keys})
+	assert.NoError(t, err)
+	sts, err := batch.IngestBatch([][]byte{bytes.Replace(container, lines[1], lines[0], 1), container})
+	assert.NoError(t, err)
+	assert.Len(t, sts, 2)
+	assert.False(t, sts[0].Accepted)
+	assert.NotEmpty(t, sts[0].Error)
+	assert.True(t, sts[1].Accepted)
+	share, _, _, err = batch.Share(st.DatasetId)
+	assert.NoError(t, err)
+	assert.Equal(t, splits[1], share)
+
+	// a container without proof or not committed with Pedersen
+	// commitments is refused alone
+	parts := bytes.Split(bytes.TrimSuffix(container, []byte("\n")), []byte("\n"))
+	var aProof ZKPComponent.AuthProof
+	assert.NoError(t, json.Unmarshal(parts[4], &aProof))
+	aProof.Sign.Scheme = signature.CommitSchemeMiMC
+	aProof.Sign.CommitData = nil
+	parts[4], err = json.Marshal(&aProof)
+	assert.NoError(t, err)
+	mimc := bytes.Join(parts, []byte("\n"))
+	sts, err = batch.IngestBatch([][]byte{container, []byte("no proof"), mimc})
+	assert.NoError(t, err)
+	assert.True(t, sts[0].Accepted)
+	assert.False(t, sts[1].Accepted)
+	assert.Empty(t, sts[1].DatasetId)
+	assert.NotEmpty(t, sts[1].Error)
+	assert.False(t, sts[2].Accepted)
+	assert.NotEmpty(t, sts[2].Error)
+	_, err = batch.Ingest([]byte("no proof"))
+	assert.Error(t, err)
+
+	// another split of an accepted dataset is refused
+	file2 := filepath.Join(dir, "enc2.txt")
+	_, _, _, _, _, err = ZKPComponent.DatasetSplitEncryptAndZkpCsvToFile(signed, file2, pk, r1cs, pubKeys)
+	assert.NoError(t, err)
+	container2, err := os.ReadFile(file2)
+	assert.NoError(t, err)
+	st4, err := batch.Ingest(container2)
+	assert.NoError(t, err)
+	assert.False(t, st4.Accepted)
+	assert.NotEmpty(t, st4.Error)
+	share, _, _, err = batch.Share(st.DatasetId)
+	assert.NoError(t, err)
+	assert.Equal(t, splits[1], share)
+
+	// a malformed ciphertext of the share is refused
+	var enc encryption.VecEnc
+	assert.NoError(t, json.Unmarshal(lines[1], &enc))
+	enc.Val = enc.Val[:len(enc.Val)-1]
+	bad, err := json.Marshal(&enc)
+	assert.NoError(t, err)
+	st3, err := batch.Ingest(bytes.Replace(container, lines[1], bad, 1))
+	assert.NoError(t, err)
+	assert.False(t, st3.Accepted)
+	assert.NotEmpty(t, st3.Error)
+
+	// concurrent ingests of the same dataset
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			st, err := batch.Ingest(container)
+			assert.NoError(t, err)
+			assert.True(t, st.Accepted)
+		}()
+	}
+	wg.Wait()
+	share, _, _, err = batch.Share(st.DatasetId)
+	assert.NoError(t, err)
+	assert.Equal(t, splits[1], share)
+
+	srv2 := httptest.NewServer(agent.Handler())
+	defer srv2.Close()
+	resp, err = http.Get(srv2.URL + "/datasets/" + st.DatasetId)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp.Body.Close()
+	resp, err = http.Get(srv2.URL + "/datasets/unknown")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp.Body.Close()
+	var list []DatasetStatus
+	resp, err = http.Get(srv2.URL + "/datasets")
+	assert.NoError(t, err)
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&list))
+	resp.Body.Close()
+	assert.Len(t, list, 1)
+}
+
+func TestAgentStream(t *testing.T) {
+	signer, err := eddsa.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	dir := t.TempDir()
+	signed := filepath.Join(dir, "signed.csv")
+	sign, err := signature.SignCsv("../datasets/framingham_tiny.csv", signer)
+	assert.NoError(t, err)
+	assert.NoError(t, signature.WriteSignCsv("../datasets/framingham_tiny.csv", signed, sign))
+
+	pk, err := ZKPComponent.LoadProvingKey("../proofKey.txt")
+	assert.NoError(t, err)
+	keys, err := ZKPComponent.LoadLegacyVerifyingKeys("../verifyKey.txt")
+	assert.NoError(t, err)
+	r1cs, err := ZKPComponent.CompileCircuitDataset()
+	assert.NoError(t, err)
+
+	pubKeys := make([][]byte, 3)
+	secKeys := make([][]byte, 3)
+	for i := 0; i < 3; i++ {
+		pubKeys[i], secKeys[i] = key_management.GenerateKeypair()
+	}
+	f, err := os.Open(signed)
+	assert.NoError(t, err)
+	defer f.Close()
+	streams := make([]bytes.Buffer, 3)
+	cols, rows, _, err := ZKPComponent.CsvSplitEncryptAndZkpStream(f, []io.Writer{&streams[0], &streams[1], &streams[2]},
+		pk, r1cs, pubKeys)
+	assert.NoError(t, err)
+
+	agent, err := NewAgent(Config{NodeId: 2, PubKey: pubKeys[2], SecKey: secKeys[2], StoreDir: filepath.Join(dir, "store"),
+		VerifyingKeys: keys})
+	assert.NoError(t, err)
+	srv := httptest.NewServer(agent.Handler())
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/streams", "application/octet-stream", bytes.NewReader(streams[2].Bytes()))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	var st DatasetStatus
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&st))
+	resp.Body.Close()
+	assert.True(t, st.Accepted)
+	assert.Equal(t, signature.DatasetId(sign), st.DatasetId)
+	assert.Equal(t, cols, st.Cols)
+	assert.Equal(t, rows*len(cols), st.Length)
+	share, _, aProof, err := agent.Share(st.DatasetId)
+	assert.NoError(t, err)
+	assert.Equal(t, 2*st.Length+1, len(share))
+	proof, commits, sign2, pubKey, err := ZKPComponent.ExpandAuthProof(aProof)
+	assert.NoError(t, err)
+	vk, err := keys.VerifyingKey(ZKPComponent.DatasetCircuitId(sign2))
+	assert.NoError(t, err)
+	_, err = ZKPComponent.VerifyDatasetSplitAndZKpCsv(proof, vk, share, 2, commits, cols, sign2, pubKey)
+	assert.NoError(t, err)
+
+	// the stream of node 2 is refused by node 0, which has the same key
+	other, err := NewAgent(Config{NodeId: 0, PubKey: pubKeys[2], SecKey: secKeys[2], StoreDir: filepath.Join(dir, "other"),
+		VerifyingKeys: keys})
+	assert.NoError(t, err)
+	st2, err := other.IngestStream(bytes.NewReader(streams[2].Bytes()))
+	assert.NoError(t, err)
+	assert.False(t, st2.Accepted)
+	assert.NotEmpty(t, st2.Error)
+
+	// as well as a truncated stream
+	_, err = agent.IngestStream(bytes.NewReader(streams[2].Bytes()[:streams[2].Len()-1]))
+	assert.Error(t, err)
+
+	// the share is stored as a stream, which survives a restart, and
+	// the same stream is accepted again
+	files, err := filepath.Glob(filepath.Join(dir, "store", "*"))
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{filepath.Join(dir, "store", st.DatasetId+".stream"),
+		filepath.Join(dir, "store", indexFile)}, files)
+	agent, err = NewAgent(Config{NodeId: 2, PubKey: pubKeys[2], SecKey: secKeys[2], StoreDir: filepath.Join(dir, "store"),
+		VerifyingKeys: keys})
+	assert.NoError(t, err)
+	st3, err := agent.IngestStream(bytes.NewReader(streams[2].Bytes()))
+	assert.NoError(t, err)
+	assert.True(t, st3.Accepted)
+	share2, _, _, err := agent.Share(st.DatasetId)
+	assert.NoError(t, err)
+	assert.Equal(t, share, share2)
+}
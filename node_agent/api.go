@@ -0,0 +1,91 @@
+package node_agent
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// MaxContainerBytes is the maximal size of a container posted to the API.
+var MaxContainerBytes int64 = 256 << 20
+
+// MaxStreamBytes is the maximal size of a share stream posted to the API.
+var MaxStreamBytes int64 = 16 << 30
+
+// Handler returns the local API of the agent:
+//
+//	POST /datasets       ingests the share container in the body
+//	GET  /datasets       lists the status of all the datasets
+//	GET  /datasets/{id}  returns the status of a dataset
+//	POST /streams        ingests the share stream of the node in the body
+func (a *Agent) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/datasets", a.handleDatasets)
+	mux.HandleFunc("/datasets/", a.handleDataset)
+	mux.HandleFunc("/streams", a.handleStreams)
+
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func (a *Agent) handleDatasets(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, a.List())
+	case http.MethodPost:
+		container, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxContainerBytes))
+		if err != nil {
+			http.Error(w, "invalid request: "+err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		st, err := a.Ingest(container)
+		writeIngested(w, st, err)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (a *Agent) handleDataset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	st, ok := a.Status(strings.TrimPrefix(r.URL.Path, "/datasets/"))
+	if !ok {
+		http.Error(w, "unknown dataset", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, st)
+}
+
+func writeIngested(w http.ResponseWriter, st *DatasetStatus, err error) {
+	if err != nil {
+		http.Error(w, "ingestion failed: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !st.Accepted {
+		writeJSON(w, http.StatusUnprocessableEntity, st)
+		return
+	}
+	writeJSON(w, http.StatusCreated, st)
+}
+
+func (a *Agent) handleStreams(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	st, err := a.IngestStream(http.MaxBytesReader(w, r.Body, MaxStreamBytes))
+	writeIngested(w, st, err)
+}
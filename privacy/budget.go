@@ -0,0 +1,55 @@
+package privacy
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Accountant tracks the privacy budget spent on each dataset, keyed by
+// signature.DatasetId.
+type Accountant struct {
+	mu     sync.Mutex
+	budget map[string]float64
+	spent  map[string]float64
+}
+
+func NewAccountant() *Accountant {
+	return &Accountant{budget: make(map[string]float64), spent: make(map[string]float64)}
+}
+
+// SetBudget sets the total epsilon that can be spent on the dataset.
+func (a *Accountant) SetBudget(datasetId string, epsilon float64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.budget[datasetId] = epsilon
+}
+
+// Spend records a release consuming epsilon on the dataset, or refuses
+// it if the budget of the dataset would be exceeded.
+func (a *Accountant) Spend(datasetId string, epsilon float64) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	budget, ok := a.budget[datasetId]
+	if !ok {
+		return fmt.Errorf("no privacy budget for dataset %s", datasetId)
+	}
+	if epsilon <= 0 {
+		return fmt.Errorf("invalid epsilon %v", epsilon)
+	}
+	if a.spent[datasetId]+epsilon > budget {
+		return fmt.Errorf("privacy budget of dataset %s exhausted", datasetId)
+	}
+	a.spent[datasetId] += epsilon
+
+	return nil
+}
+
+// Remaining returns the epsilon that can still be spent on the dataset.
+func (a *Accountant) Remaining(datasetId string) float64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.budget[datasetId] - a.spent[datasetId]
+}
@@ -0,0 +1,217 @@
+package privacy
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	sig "github.com/consensys/gnark-crypto/signature"
+	"github.com/krakenh2020/ZKPComponent/signature"
+)
+
+// DatasetMetadata is the privacy metadata of a dataset, set by its owner.
+// Version orders the metadata of a dataset: a ledger only accepts
+// versions above the one it registered, so that older signed metadata
+// cannot be replayed.
+type DatasetMetadata struct {
+	DatasetId     string
+	EpsilonBudget float64
+	Version       uint64
+}
+
+// SignedMetadata is DatasetMetadata signed with the key the owner used
+// to sign the dataset.
+type SignedMetadata struct {
+	Metadata DatasetMetadata
+	Sig      []byte
+}
+
+func metadataMessage(m *DatasetMetadata) []byte {
+	return []byte("kraken-dataset-metadata\n" + m.DatasetId + "\n" + strconv.FormatFloat(m.EpsilonBudget, 'g', -1, 64) +
+		"\n" + strconv.FormatUint(m.Version, 10))
+}
+
+func SignMetadata(m *DatasetMetadata, signer sig.Signer) (*SignedMetadata, error) {
+	s, err := signer.Sign(metadataMessage(m), sha256.New())
+	if err != nil {
+		return nil, err
+	}
+
+	return &SignedMetadata{Metadata: *m, Sig: s}, nil
+}
+
+// VerifyMetadata checks that the metadata refers to the signed dataset
+// and is signed by the owner of the dataset.
+func VerifyMetadata(m *SignedMetadata, sign *signature.SignatureZKP) (bool, error) {
+	if m.Metadata.DatasetId != signature.DatasetId(sign) {
+		return false, fmt.Errorf("metadata of another dataset")
+	}
+	if m.Metadata.EpsilonBudget < 0 {
+		return false, fmt.Errorf("invalid privacy budget")
+	}
+
+	pubKey, err := signature.ParsePublicKey(sign.SigScheme, sign.PubKey)
+	if err != nil {
+		return false, err
+	}
+
+	return pubKey.Verify(m.Sig, metadataMessage(&m.Metadata), sha256.New())
+}
+
+// QueryRecord is a release recorded in the ledger.
+type QueryRecord struct {
+	QueryId string
+	Epsilon float64
+	Time    time.Time
+}
+
+// LedgerEntry is the budget of a dataset, the version of the metadata
+// setting it and the queries run on it.
+type LedgerEntry struct {
+	Budget  float64
+	Version uint64
+	Spent   float64
+	Queries []QueryRecord
+}
+
+// Ledger is a persistent record of the privacy budget spent on each
+// dataset, stored as a JSON file. Every change is written to the file
+// before it is acknowledged.
+type Ledger struct {
+	mu      sync.Mutex
+	path    string
+	entries map[string]*LedgerEntry
+}
+
+// OpenLedger loads the ledger stored in file, or creates an empty one
+// if the file does not exist yet.
+func OpenLedger(file string) (*Ledger, error) {
+	l := &Ledger{path: file, entries: make(map[string]*LedgerEntry)}
+
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(data, &l.entries)
+	if err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// save writes the ledger to a temporary file and renames it, so that
+// the file is never left half written.
+func (l *Ledger) save() error {
+	data, err := json.MarshalIndent(l.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(l.path), filepath.Base(l.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if err2 := tmp.Close(); err == nil {
+		err = err2
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), l.path)
+}
+
+// Register sets the budget of a dataset from the metadata signed by
+// its owner, refusing versions not above the registered one. The budget
+// spent so far on the dataset is kept.
+func (l *Ledger) Register(sign *signature.SignatureZKP, m *SignedMetadata) error {
+	check, err := VerifyMetadata(m, sign)
+	if err != nil {
+		return err
+	}
+	if !check {
+		return fmt.Errorf("invalid metadata signature")
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e, ok := l.entries[m.Metadata.DatasetId]
+	if ok && m.Metadata.Version <= e.Version {
+		return fmt.Errorf("metadata version %d not above the registered version %d", m.Metadata.Version, e.Version)
+	}
+	if !ok {
+		e = &LedgerEntry{}
+		l.entries[m.Metadata.DatasetId] = e
+	}
+	old := *e
+	e.Budget = m.Metadata.EpsilonBudget
+	e.Version = m.Metadata.Version
+
+	err = l.save()
+	if err != nil {
+		if ok {
+			*e = old
+		} else {
+			delete(l.entries, m.Metadata.DatasetId)
+		}
+	}
+
+	return err
+}
+
+// Spend records the query consuming epsilon on the dataset, or refuses
+// it if the budget of the dataset would be exceeded.
+func (l *Ledger) Spend(datasetId, queryId string, epsilon float64) error {
+	if epsilon <= 0 {
+		return fmt.Errorf("invalid epsilon %v", epsilon)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e, ok := l.entries[datasetId]
+	if !ok {
+		return fmt.Errorf("no privacy budget for dataset %s", datasetId)
+	}
+	if e.Spent+epsilon > e.Budget {
+		return fmt.Errorf("privacy budget of dataset %s exhausted", datasetId)
+	}
+
+	e.Spent += epsilon
+	e.Queries = append(e.Queries, QueryRecord{QueryId: queryId, Epsilon: epsilon, Time: time.Now().UTC()})
+	err := l.save()
+	if err != nil {
+		e.Spent -= epsilon
+		e.Queries = e.Queries[:len(e.Queries)-1]
+	}
+
+	return err
+}
+
+// Remaining returns the epsilon that can still be spent on the dataset.
+func (l *Ledger) Remaining(datasetId string) float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e, ok := l.entries[datasetId]
+	if !ok {
+		return 0
+	}
+
+	return e.Budget - e.Spent
+}
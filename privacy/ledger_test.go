@@ -0,0 +1,90 @@
+package privacy
+
+import (
+	"crypto/rand"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	sig "github.com/consensys/gnark-crypto/signature"
+	"github.com/krakenh2020/ZKPComponent/signature"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLedger(t *testing.T) {
+	signer, err := eddsa.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	other, err := eddsa.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	sign, err := signature.SignCsv("../datasets/framingham_tiny.csv", signer)
+	assert.NoError(t, err)
+	id := signature.DatasetId(sign)
+
+	meta, err := SignMetadata(&DatasetMetadata{DatasetId: id, EpsilonBudget: 1, Version: 1}, signer)
+	assert.NoError(t, err)
+	forged, err := SignMetadata(&DatasetMetadata{DatasetId: id, EpsilonBudget: 100, Version: 1}, other)
+	assert.NoError(t, err)
+
+	file := filepath.Join(t.TempDir(), "ledger.json")
+	ledger, err := OpenLedger(file)
+	assert.NoError(t, err)
+
+	assert.Error(t, ledger.Register(sign, forged))
+	assert.Error(t, ledger.Spend(id, "q0", 0.1))
+	assert.NoError(t, ledger.Register(sign, meta))
+
+	assert.NoError(t, ledger.Spend(id, "q1", 0.5))
+	assert.NoError(t, ledger.Spend(id, "q2", 0.25))
+
+	// the spent budget survives reopening the ledger
+	ledger, err = OpenLedger(file)
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.25, ledger.Remaining(id), 1e-9)
+	assert.Error(t, ledger.Spend(id, "q3", 0.5))
+	assert.NoError(t, ledger.Spend(id, "q3", 0.25))
+	assert.Error(t, ledger.Spend(id, "q4", 0.01))
+
+	// the owner raises the budget, older metadata cannot be replayed
+	raised, err := SignMetadata(&DatasetMetadata{DatasetId: id, EpsilonBudget: 2, Version: 3}, signer)
+	assert.NoError(t, err)
+	older, err := SignMetadata(&DatasetMetadata{DatasetId: id, EpsilonBudget: 5, Version: 2}, signer)
+	assert.NoError(t, err)
+	assert.NoError(t, ledger.Register(sign, raised))
+	assert.Error(t, ledger.Register(sign, older))
+	assert.Error(t, ledger.Register(sign, raised))
+	assert.InDelta(t, 1, ledger.Remaining(id), 1e-9)
+
+	// the version is part of the signed message
+	older.Metadata.Version = 4
+	assert.Error(t, ledger.Register(sign, older))
+}
+
+func TestVerifyMetadataSchemes(t *testing.T) {
+	ecdsaKey, err := signature.GenerateECDSAP256(rand.Reader)
+	assert.NoError(t, err)
+	ed25519Key, err := signature.GenerateEd25519(rand.Reader)
+	assert.NoError(t, err)
+	other, err := signature.GenerateECDSAP256(rand.Reader)
+	assert.NoError(t, err)
+
+	for _, signer := range []sig.Signer{ecdsaKey, ed25519Key} {
+		t.Run(fmt.Sprintf("%T", signer), func(t *testing.T) {
+			sign, err := signature.SignCsv("../datasets/framingham_tiny.csv", signer)
+			assert.NoError(t, err)
+			m := &DatasetMetadata{DatasetId: signature.DatasetId(sign), EpsilonBudget: 1, Version: 1}
+
+			meta, err := SignMetadata(m, signer)
+			assert.NoError(t, err)
+			check, err := VerifyMetadata(meta, sign)
+			assert.NoError(t, err)
+			assert.True(t, check)
+
+			forged, err := SignMetadata(m, other)
+			assert.NoError(t, err)
+			check, _ = VerifyMetadata(forged, sign)
+			assert.False(t, check)
+		})
+	}
+}
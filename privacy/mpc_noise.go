@@ -0,0 +1,59 @@
+package privacy
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/krakenh2020/ZKPComponent/data_common"
+	"github.com/krakenh2020/ZKPComponent/mpc"
+)
+
+// AddNoise adds noise of the mechanism to the fixed-point result shared
+// by the nodes. Each node samples a part of the noise for every entry,
+// secret-shares it with the other nodes and adds up the shares it
+// receives, so that no node knows the total noise. It must be called
+// by all the nodes.
+func AddNoise(node *mpc.Node, result []*big.Int, mech Mechanism) ([]*big.Int, error) {
+	noise := make([]*big.Int, len(result))
+	for i := range noise {
+		v, err := mech.NoiseShare(mpc.NumNodes)
+		if err != nil {
+			return nil, err
+		}
+		vFix, err := data_common.FloatToFixInt(v)
+		if err != nil {
+			return nil, err
+		}
+		noise[i] = big.NewInt(vFix)
+	}
+	shares, err := data_common.CreateSharesShamir(noise)
+	if err != nil {
+		return nil, err
+	}
+
+	for j := 0; j < mpc.NumNodes; j++ {
+		if j != node.Id {
+			err = node.Send(j, shares[j])
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	res := mpc.Add(result, shares[node.Id])
+	for j := 0; j < mpc.NumNodes; j++ {
+		if j == node.Id {
+			continue
+		}
+		s, err := node.Recv(j)
+		if err != nil {
+			return nil, err
+		}
+		if len(s) != len(result) {
+			return nil, fmt.Errorf("node %d sent a noise share of wrong length", j)
+		}
+		res = mpc.Add(res, s)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,56 @@
+package privacy
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/krakenh2020/ZKPComponent/data_common"
+	"github.com/krakenh2020/ZKPComponent/mpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddNoise(t *testing.T) {
+	vec, cols, _, err := data_common.CsvToVec("../datasets/framingham_tiny.csv")
+	assert.NoError(t, err)
+	shares, err := data_common.CreateSharesShamir(vec)
+	assert.NoError(t, err)
+
+	accountant := NewAccountant()
+	accountant.SetBudget("framingham_tiny", 1)
+	mech := &Laplace{Sensitivity: 1, Eps: 0.4}
+
+	release := func() []float64 {
+		err := accountant.Spend("framingham_tiny", mech.Epsilon())
+		if err != nil {
+			return nil
+		}
+		res, err := mpc.RunNodes(mpc.NewDealer(), func(node *mpc.Node) ([]*big.Int, error) {
+			count, err := mpc.ColumnCount(shares[node.Id], len(cols))
+			if err != nil {
+				return nil, err
+			}
+			return AddNoise(node, count, mech)
+		})
+		assert.NoError(t, err)
+		return data_common.JoinSharesShamirFloat(res)
+	}
+
+	rows := float64(len(vec) / len(cols))
+	for i := 0; i < 2; i++ {
+		counts := release()
+		assert.NotNil(t, counts)
+		exact := 0
+		for _, e := range counts {
+			// with overwhelming probability the noise is below 100 / epsilon
+			assert.InDelta(t, rows, e, 100/mech.Eps)
+			if e == rows {
+				exact++
+			}
+		}
+		assert.Less(t, exact, len(cols))
+	}
+
+	// the third release exceeds the budget
+	assert.Nil(t, release())
+	assert.InDelta(t, 0.2, accountant.Remaining("framingham_tiny"), 1e-9)
+}
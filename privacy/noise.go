@@ -0,0 +1,302 @@
+package privacy
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/krakenh2020/ZKPComponent/data_common"
+)
+
+// Mechanism is a differentially private noise mechanism whose noise
+// can be split into independent parts, one for each MPC node, that
+// sum up to the noise of the mechanism.
+type Mechanism interface {
+	// Epsilon is the privacy budget consumed by one release.
+	Epsilon() float64
+	// NoiseShare samples one of the parts of the noise.
+	NoiseShare(parts int) (float64, error)
+}
+
+// Laplace is the Laplace mechanism with scale Sensitivity / Eps. The
+// Laplace distribution is infinitely divisible: it is the sum of the
+// differences of Gamma(1/parts) distributed values.
+type Laplace struct {
+	Sensitivity float64
+	Eps         float64
+}
+
+func (l *Laplace) Epsilon() float64 {
+	return l.Eps
+}
+
+func (l *Laplace) NoiseShare(parts int) (float64, error) {
+	if l.Eps <= 0 || l.Sensitivity <= 0 || parts <= 0 {
+		return 0, fmt.Errorf("invalid mechanism parameters")
+	}
+	scale := l.Sensitivity / l.Eps
+	g1, err := sampleGamma(1/float64(parts), scale)
+	if err != nil {
+		return 0, err
+	}
+	g2, err := sampleGamma(1/float64(parts), scale)
+	if err != nil {
+		return 0, err
+	}
+
+	return g1 - g2, nil
+}
+
+// Gaussian is the Gaussian mechanism for (Eps, Delta)-differential
+// privacy, with the classical calibration of the standard deviation.
+// The noise is sampled in floating point and only rounded to the
+// fixed-point encoding, which is not a discrete Gaussian: the guarantee
+// is the one of the real-valued mechanism, ignoring the attacks on
+// floating-point samplers. Use DiscreteGaussian when it matters.
+type Gaussian struct {
+	Sensitivity float64
+	Eps         float64
+	Delta       float64
+}
+
+func (g *Gaussian) Epsilon() float64 {
+	return g.Eps
+}
+
+func (g *Gaussian) Sigma() float64 {
+	return g.Sensitivity * math.Sqrt(2*math.Log(1.25/g.Delta)) / g.Eps
+}
+
+func (g *Gaussian) NoiseShare(parts int) (float64, error) {
+	if g.Eps <= 0 || g.Eps >= 1 || g.Delta <= 0 || g.Delta >= 1 || g.Sensitivity <= 0 || parts <= 0 {
+		return 0, fmt.Errorf("invalid mechanism parameters")
+	}
+	z, err := sampleNormal()
+	if err != nil {
+		return 0, err
+	}
+
+	return z * g.Sigma() / math.Sqrt(float64(parts)), nil
+}
+
+// DiscreteGaussian is the discrete Gaussian mechanism on the grid of
+// the fixed-point encoding (multiples of 2^-FixF), sampled exactly
+// (Canonne, Kamath and Steinke 2020). Its standard deviation is
+// calibrated as the one of Gaussian, which gives the same (Eps, Delta)
+// guarantee for the sensitivity rounded up to the grid. Each part is a
+// discrete Gaussian of variance σ²/parts: the privacy loss of their sum
+// exceeds the one of the discrete Gaussian of variance σ² by a term of
+// order parts e^(-π²σ²/parts) (Kairouz, Liu and Steinke 2021), σ in
+// units of the grid, which is negligible.
+type DiscreteGaussian struct {
+	Sensitivity float64
+	Eps         float64
+	Delta       float64
+}
+
+func (g *DiscreteGaussian) Epsilon() float64 {
+	return g.Eps
+}
+
+// Sigma is the standard deviation of the noise.
+func (g *DiscreteGaussian) Sigma() float64 {
+	sensitivity := math.Ceil(g.Sensitivity*math.Pow(2, data_common.FixF)) / math.Pow(2, data_common.FixF)
+
+	return sensitivity * math.Sqrt(2*math.Log(1.25/g.Delta)) / g.Eps
+}
+
+// NoiseShare returns a multiple of 2^-FixF, encoded exactly by
+// data_common.FloatToFixInt.
+func (g *DiscreteGaussian) NoiseShare(parts int) (float64, error) {
+	if g.Eps <= 0 || g.Eps >= 1 || g.Delta <= 0 || g.Delta >= 1 || g.Sensitivity <= 0 || parts <= 0 {
+		return 0, fmt.Errorf("invalid mechanism parameters")
+	}
+	sigma := g.Sigma() * math.Pow(2, data_common.FixF)
+	sigma2 := new(big.Rat).SetFloat64(sigma * sigma / float64(parts))
+	if sigma2 == nil || sigma2.Sign() <= 0 || sigma > 1<<50 {
+		return 0, fmt.Errorf("invalid mechanism parameters")
+	}
+	z, err := sampleDiscreteGaussian(sigma2)
+	if err != nil {
+		return 0, err
+	}
+
+	return float64(z) / math.Pow(2, data_common.FixF), nil
+}
+
+// sampleUniform returns a uniform value in (0, 1) from crypto/rand.
+func sampleUniform() (float64, error) {
+	var buf [8]byte
+	for {
+		_, err := rand.Read(buf[:])
+		if err != nil {
+			return 0, err
+		}
+		u := float64(binary.BigEndian.Uint64(buf[:])>>11) / (1 << 53)
+		if u > 0 {
+			return u, nil
+		}
+	}
+}
+
+// sampleNormal returns a standard normal value (Box-Muller).
+func sampleNormal() (float64, error) {
+	u1, err := sampleUniform()
+	if err != nil {
+		return 0, err
+	}
+	u2, err := sampleUniform()
+	if err != nil {
+		return 0, err
+	}
+
+	return math.Sqrt(-2*math.Log(u1)) * math.Cos(2*math.Pi*u2), nil
+}
+
+// sampleGamma returns a Gamma(shape, scale) value using the method of
+// Marsaglia and Tsang, boosted for shape < 1.
+func sampleGamma(shape, scale float64) (float64, error) {
+	if shape < 1 {
+		g, err := sampleGamma(shape+1, scale)
+		if err != nil {
+			return 0, err
+		}
+		u, err := sampleUniform()
+		if err != nil {
+			return 0, err
+		}
+		return g * math.Pow(u, 1/shape), nil
+	}
+
+	d := shape - 1.0/3
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x, err := sampleNormal()
+		if err != nil {
+			return 0, err
+		}
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		u, err := sampleUniform()
+		if err != nil {
+			return 0, err
+		}
+		if math.Log(u) < 0.5*x*x+d-d*v+d*math.Log(v) {
+			return d * v * scale, nil
+		}
+	}
+}
+
+// sampleBernoulli returns true with probability p in [0, 1].
+func sampleBernoulli(p *big.Rat) (bool, error) {
+	u, err := rand.Int(rand.Reader, p.Denom())
+	if err != nil {
+		return false, err
+	}
+
+	return u.Cmp(p.Num()) < 0, nil
+}
+
+// sampleBernoulliExp returns true with probability exp(-gamma), gamma
+// >= 0, without computing the exponential (Canonne et al., Algorithm 1).
+func sampleBernoulliExp(gamma *big.Rat) (bool, error) {
+	one := big.NewRat(1, 1)
+	if gamma.Cmp(one) > 0 {
+		floor := new(big.Int).Quo(gamma.Num(), gamma.Denom())
+		for k := new(big.Int); k.Cmp(floor) < 0; k.Add(k, big.NewInt(1)) {
+			b, err := sampleBernoulliExp(one)
+			if err != nil || !b {
+				return false, err
+			}
+		}
+		return sampleBernoulliExp(new(big.Rat).Sub(gamma, new(big.Rat).SetInt(floor)))
+	}
+
+	k := int64(1)
+	for ; ; k++ {
+		a, err := sampleBernoulli(new(big.Rat).Quo(gamma, big.NewRat(k, 1)))
+		if err != nil {
+			return false, err
+		}
+		if !a {
+			break
+		}
+	}
+
+	return k%2 == 1, nil
+}
+
+// sampleDiscreteLaplace returns an integer with probability
+// proportional to exp(-|z|/t), t > 0 (Canonne et al., Algorithm 2).
+func sampleDiscreteLaplace(t *big.Int) (*big.Int, error) {
+	for {
+		u, err := rand.Int(rand.Reader, t)
+		if err != nil {
+			return nil, err
+		}
+		d, err := sampleBernoulliExp(new(big.Rat).SetFrac(u, t))
+		if err != nil {
+			return nil, err
+		}
+		if !d {
+			continue
+		}
+		v := int64(0)
+		for {
+			a, err := sampleBernoulliExp(big.NewRat(1, 1))
+			if err != nil {
+				return nil, err
+			}
+			if !a {
+				break
+			}
+			v++
+		}
+		b, err := sampleBernoulli(big.NewRat(1, 2))
+		if err != nil {
+			return nil, err
+		}
+		if b && u.Sign() == 0 && v == 0 {
+			continue
+		}
+		z := new(big.Int).Mul(t, big.NewInt(v))
+		z.Add(z, u)
+		if b {
+			z.Neg(z)
+		}
+		return z, nil
+	}
+}
+
+// sampleDiscreteGaussian returns an integer with probability
+// proportional to exp(-z²/(2 sigma2)) (Canonne et al., Algorithm 3).
+func sampleDiscreteGaussian(sigma2 *big.Rat) (int64, error) {
+	// t = floor(sigma) + 1
+	t := new(big.Int).Quo(sigma2.Num(), sigma2.Denom())
+	t.Sqrt(t).Add(t, big.NewInt(1))
+	tRat := new(big.Rat).SetInt(t)
+	sigma2t := new(big.Rat).Quo(sigma2, tRat)
+	twoSigma2 := new(big.Rat).Add(sigma2, sigma2)
+	for {
+		y, err := sampleDiscreteLaplace(t)
+		if err != nil {
+			return 0, err
+		}
+		gamma := new(big.Rat).SetInt(new(big.Int).Abs(y))
+		gamma.Sub(gamma, sigma2t)
+		gamma.Mul(gamma, gamma)
+		gamma.Quo(gamma, twoSigma2)
+		c, err := sampleBernoulliExp(gamma)
+		if err != nil {
+			return 0, err
+		}
+		if c {
+			return y.Int64(), nil
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package privacy
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/krakenh2020/ZKPComponent/data_common"
+	"github.com/stretchr/testify/assert"
+)
+
+func sampleVariance(t *testing.T, mech Mechanism, n int) float64 {
+	sum, sumSquares := 0.0, 0.0
+	for i := 0; i < n; i++ {
+		v := 0.0
+		for j := 0; j < 3; j++ {
+			s, err := mech.NoiseShare(3)
+			assert.NoError(t, err)
+			v += s
+		}
+		sum += v
+		sumSquares += v * v
+	}
+	mean := sum / float64(n)
+
+	return sumSquares/float64(n) - mean*mean
+}
+
+func TestNoiseVariance(t *testing.T) {
+	n := 20000
+
+	laplace := &Laplace{Sensitivity: 2, Eps: 0.5}
+	scale := laplace.Sensitivity / laplace.Eps
+	assert.InDelta(t, 2*scale*scale, sampleVariance(t, laplace, n), 0.1*2*scale*scale)
+
+	gaussian := &Gaussian{Sensitivity: 2, Eps: 0.5, Delta: 1e-5}
+	sigma2 := gaussian.Sigma() * gaussian.Sigma()
+	assert.InDelta(t, sigma2, sampleVariance(t, gaussian, n), 0.1*sigma2)
+	assert.Equal(t, 0.5, gaussian.Epsilon())
+
+	discrete := &DiscreteGaussian{Sensitivity: 2, Eps: 0.5, Delta: 1e-5}
+	sigma2 = discrete.Sigma() * discrete.Sigma()
+	assert.InDelta(t, sigma2, sampleVariance(t, discrete, n), 0.1*sigma2)
+
+	_, err := (&Laplace{Sensitivity: 1}).NoiseShare(3)
+	assert.Error(t, err)
+	_, err = (&DiscreteGaussian{Sensitivity: 1, Eps: 0.5}).NoiseShare(3)
+	assert.Error(t, err)
+}
+
+func TestDiscreteGaussian(t *testing.T) {
+	// the noise is on the grid of the fixed-point encoding
+	mech := &DiscreteGaussian{Sensitivity: 1, Eps: 0.9, Delta: 1e-6}
+	for i := 0; i < 100; i++ {
+		v, err := mech.NoiseShare(3)
+		assert.NoError(t, err)
+		vFix, err := data_common.FloatToFixInt(v)
+		assert.NoError(t, err)
+		assert.Equal(t, v, data_common.FixIntToFloat(vFix))
+	}
+
+	// the probabilities of a small discrete Gaussian
+	sigma2 := big.NewRat(3, 2)
+	n := 40000
+	counts := make(map[int64]int)
+	for i := 0; i < n; i++ {
+		z, err := sampleDiscreteGaussian(sigma2)
+		assert.NoError(t, err)
+		counts[z]++
+	}
+	norm := 0.0
+	for z := -20; z <= 20; z++ {
+		norm += math.Exp(-float64(z*z) / 3)
+	}
+	for z := int64(-3); z <= 3; z++ {
+		p := math.Exp(-float64(z*z)/3) / norm
+		assert.InDelta(t, p, float64(counts[z])/float64(n), 0.01)
+	}
+}
@@ -0,0 +1,563 @@
+// Package proof_aggregation aggregates Groth16 proofs of a circuit into
+// a single proof of logarithmic size, following SnarkPack: the prover
+// commits to the points of the proofs, proves the Groth16 equation of
+// their random linear combination, and proves with the inner product
+// arguments TIPP and MIPP that the combination is the one of the
+// committed points. The verifier needs O(log N) operations in the
+// groups and a number of pairings independent of N.
+package proof_aggregation
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/groth16"
+)
+
+// round is a round of the inner product arguments, halving the
+// vectors: the cross commitments and products of the halves, L the
+// right half of the points with the left half of the keys and R the
+// opposite.
+type round struct {
+	comABL, comABR [2]curve.GT
+	comCL, comCR   [2]curve.GT
+	zABL, zABR     curve.GT
+	zCL, zCR       curve.G1Affine
+}
+
+// Proof is the aggregation of Groth16 proofs (A_i, B_i, C_i), i < n,
+// padded to a power of two m with the last proof. With the challenge r
+// it proves
+//
+//	Z_AB = prod_i e(r^i A_i, B_i) and Z_C = sum_i r^i C_i
+//
+// for the committed points, from which the verifier checks the random
+// linear combination of the Groth16 equations.
+type Proof struct {
+	n int
+	// commitments with the keys of a and of b
+	comAB, comC [2]curve.GT
+	zAB         curve.GT
+	zC          curve.G1Affine
+	rounds      []round
+	// the points and keys folded to a single element: vr is the key of
+	// the rescaled points r^i A_i
+	a, c  curve.G1Affine
+	b     curve.G2Affine
+	vr, v [2]curve.G2Affine
+	w     [2]curve.G1Affine
+	// KZG openings of the final keys
+	openV [2]curve.G2Affine
+	openW [2]curve.G1Affine
+}
+
+// NbProofs is the number of aggregated proofs.
+func (p *Proof) NbProofs() int {
+	return p.n
+}
+
+// padInputs returns the inputs padded to m with the last one.
+func padInputs(inputs [][]fr.Element, m int) [][]fr.Element {
+	res := make([][]fr.Element, m)
+	copy(res, inputs)
+	for i := len(inputs); i < m; i++ {
+		res[i] = inputs[len(inputs)-1]
+	}
+
+	return res
+}
+
+func concatG1(a, b []curve.G1Affine) []curve.G1Affine {
+	return append(append(make([]curve.G1Affine, 0, len(a)+len(b)), a...), b...)
+}
+
+func concatG2(a, b []curve.G2Affine) []curve.G2Affine {
+	return append(append(make([]curve.G2Affine, 0, len(a)+len(b)), a...), b...)
+}
+
+// commitPair commits to the points a in G1 and b in G2 with the keys v
+// in G2 and w in G1.
+func commitPair(a []curve.G1Affine, b []curve.G2Affine, v []curve.G2Affine, w []curve.G1Affine) (curve.GT, error) {
+	return curve.Pair(concatG1(a, w), concatG2(v, b))
+}
+
+// Aggregate aggregates the proofs of the public inputs, which are
+// given without the constant wire, with a SRS of at least as many
+// proofs.
+func Aggregate(srs *SRS, proofs []groth16.Proof, inputs [][]fr.Element) (*Proof, error) {
+	n := len(proofs)
+	if n == 0 || len(inputs) != n {
+		return nil, fmt.Errorf("number of proofs and inputs differ")
+	}
+	if err := srs.check(); err != nil {
+		return nil, err
+	}
+	m := padding(n)
+	if m > srs.Size() {
+		return nil, fmt.Errorf("more than %d proofs", srs.Size())
+	}
+
+	a := make([]curve.G1Affine, m)
+	b := make([]curve.G2Affine, m)
+	c := make([]curve.G1Affine, m)
+	for i := 0; i < m; i++ {
+		if i < n {
+			p, err := DecodeProof(proofs[i])
+			if err != nil {
+				return nil, err
+			}
+			a[i], b[i], c[i] = p.A, p.B, p.C
+		} else {
+			a[i], b[i], c[i] = a[n-1], b[n-1], c[n-1]
+		}
+	}
+	v := [2][]curve.G2Affine{srs.G2.A[:m], srs.G2.B[:m]}
+	w := [2][]curve.G1Affine{srs.G1.A[m : 2*m], srs.G1.B[m : 2*m]}
+
+	p := &Proof{n: n}
+	var err error
+	for k := 0; k < 2; k++ {
+		if p.comAB[k], err = commitPair(a, b, v[k], w[k]); err != nil {
+			return nil, err
+		}
+		if p.comC[k], err = curve.Pair(c, v[k]); err != nil {
+			return nil, err
+		}
+	}
+	t := newTranscript()
+	t.inputs(padInputs(inputs, m))
+	t.gt(&p.comAB[0], &p.comAB[1], &p.comC[0], &p.comC[1])
+	r, err := t.challenge()
+	if err != nil {
+		return nil, err
+	}
+
+	// rescale A with r^i and its keys with r^-i, keeping the commitment
+	var rInv fr.Element
+	rInv.Inverse(&r)
+	s := powers(r, m)
+	sInv := powers(rInv, m)
+	vr := [2][]curve.G2Affine{make([]curve.G2Affine, m), make([]curve.G2Affine, m)}
+	for i := 0; i < m; i++ {
+		a[i].ScalarMultiplication(&a[i], toBig(&s[i]))
+		for k := 0; k < 2; k++ {
+			vr[k][i].ScalarMultiplication(&v[k][i], toBig(&sInv[i]))
+		}
+	}
+	if p.zAB, err = curve.Pair(a, b); err != nil {
+		return nil, err
+	}
+	if _, err = p.zC.MultiExp(c, s, ecc.MultiExpConfig{}); err != nil {
+		return nil, err
+	}
+	t.gt(&p.zAB)
+	t.g1(&p.zC)
+
+	// TIPP for the rescaled A and B, MIPP for C and r^i, with the same
+	// challenges
+	var xs, xsInv []fr.Element
+	for l := m; l > 1; l /= 2 {
+		h := l / 2
+		var rd round
+		for k := 0; k < 2; k++ {
+			if rd.comABL[k], err = commitPair(a[h:], b[:h], vr[k][:h], w[k][h:]); err != nil {
+				return nil, err
+			}
+			if rd.comABR[k], err = commitPair(a[:h], b[h:], vr[k][h:], w[k][:h]); err != nil {
+				return nil, err
+			}
+			if rd.comCL[k], err = curve.Pair(c[h:], v[k][:h]); err != nil {
+				return nil, err
+			}
+			if rd.comCR[k], err = curve.Pair(c[:h], v[k][h:]); err != nil {
+				return nil, err
+			}
+		}
+		if rd.zABL, err = curve.Pair(a[h:], b[:h]); err != nil {
+			return nil, err
+		}
+		if rd.zABR, err = curve.Pair(a[:h], b[h:]); err != nil {
+			return nil, err
+		}
+		if _, err = rd.zCL.MultiExp(c[h:], s[:h], ecc.MultiExpConfig{}); err != nil {
+			return nil, err
+		}
+		if _, err = rd.zCR.MultiExp(c[:h], s[h:], ecc.MultiExpConfig{}); err != nil {
+			return nil, err
+		}
+		p.rounds = append(p.rounds, rd)
+		rd.bind(t)
+		x, err := t.challenge()
+		if err != nil {
+			return nil, err
+		}
+		var xInv fr.Element
+		xInv.Inverse(&x)
+		xs = append(xs, x)
+		xsInv = append(xsInv, xInv)
+
+		a = foldG1(a[:h], a[h:], &x)
+		b = foldG2(b[:h], b[h:], &xInv)
+		c = foldG1(c[:h], c[h:], &x)
+		s = foldScalars(s[:h], s[h:], &xInv)
+		for k := 0; k < 2; k++ {
+			vr[k] = foldG2(vr[k][:h], vr[k][h:], &xInv)
+			v[k] = foldG2(v[k][:h], v[k][h:], &xInv)
+			w[k] = foldG1(w[k][:h], w[k][h:], &x)
+		}
+	}
+	p.a, p.b, p.c = a[0], b[0], c[0]
+	for k := 0; k < 2; k++ {
+		p.vr[k], p.v[k], p.w[k] = vr[k][0], v[k][0], w[k][0]
+	}
+
+	z, rho, err := p.bindFinal(t)
+	if err != nil {
+		return nil, err
+	}
+
+	// the final keys of G2 are [f_v(x) + rho·f_v(x/r)]₂ and those of G1
+	// [x^m f_w(x)]₁ at x = a and b
+	fv := foldCoefficients(xsInv)
+	for i := range fv {
+		var t fr.Element
+		t.Mul(&fv[i], &sInv[i]).Mul(&t, &rho)
+		fv[i].Add(&fv[i], &t)
+	}
+	fw := append(make([]fr.Element, m), foldCoefficients(xs)...)
+	qv := divideLinear(fv, z)
+	qw := divideLinear(fw, z)
+	cfg := ecc.MultiExpConfig{}
+	for k, g := range [][]curve.G2Affine{srs.G2.A, srs.G2.B} {
+		if _, err = p.openV[k].MultiExp(g[:len(qv)], qv, cfg); err != nil {
+			return nil, err
+		}
+	}
+	for k, g := range [][]curve.G1Affine{srs.G1.A, srs.G1.B} {
+		if _, err = p.openW[k].MultiExp(g[:len(qw)], qw, cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+func (rd *round) bind(t *transcript) {
+	t.gt(&rd.comABL[0], &rd.comABL[1], &rd.comABR[0], &rd.comABR[1])
+	t.gt(&rd.comCL[0], &rd.comCL[1], &rd.comCR[0], &rd.comCR[1])
+	t.gt(&rd.zABL, &rd.zABR)
+	t.g1(&rd.zCL, &rd.zCR)
+}
+
+// bindFinal returns the challenges z, the point of the KZG openings,
+// and rho, combining the keys of the rescaled and the other points.
+func (p *Proof) bindFinal(t *transcript) (z, rho fr.Element, err error) {
+	t.g1(&p.a, &p.c, &p.w[0], &p.w[1])
+	t.g2(&p.b, &p.vr[0], &p.vr[1], &p.v[0], &p.v[1])
+	if z, err = t.challenge(); err != nil {
+		return
+	}
+	rho, err = t.challenge()
+
+	return
+}
+
+// Verify checks the aggregated proof of the public inputs, given
+// without the constant wire, for the verifying key.
+func Verify(srs *VerifierSRS, verKey groth16.VerifyingKey, inputs [][]fr.Element, p *Proof) error {
+	n := len(inputs)
+	if n == 0 || n != p.n {
+		return fmt.Errorf("number of proofs and inputs differ")
+	}
+	m := padding(n)
+	if m > srs.Size || 1<<len(p.rounds) != m {
+		return fmt.Errorf("invalid number of rounds")
+	}
+	vk, err := DecodeVerifyingKey(verKey)
+	if err != nil {
+		return err
+	}
+	for i := range inputs {
+		if len(inputs[i]) != len(vk.K)-1 {
+			return fmt.Errorf("public inputs of wrong length")
+		}
+	}
+
+	inputs = padInputs(inputs, m)
+	t := newTranscript()
+	t.inputs(inputs)
+	t.gt(&p.comAB[0], &p.comAB[1], &p.comC[0], &p.comC[1])
+	r, err := t.challenge()
+	if err != nil {
+		return err
+	}
+	t.gt(&p.zAB)
+	t.g1(&p.zC)
+
+	// Z_AB = e((sum_i r^i) α, β) e(sum_i r^i vk_x_i, γ) e(Z_C, δ)
+	s := powers(r, m)
+	combined := make([]fr.Element, len(vk.K))
+	var tmp fr.Element
+	for i := range inputs {
+		combined[0].Add(&combined[0], &s[i])
+		for j := range inputs[i] {
+			tmp.Mul(&inputs[i][j], &s[i])
+			combined[j+1].Add(&combined[j+1], &tmp)
+		}
+	}
+	var vkX, alpha curve.G1Affine
+	if _, err = vkX.MultiExp(vk.K, combined, ecc.MultiExpConfig{}); err != nil {
+		return err
+	}
+	alpha.ScalarMultiplication(&vk.Alpha, toBig(&combined[0]))
+	rhs, err := curve.Pair([]curve.G1Affine{alpha, vkX, p.zC}, []curve.G2Affine{vk.Beta2, vk.Gamma2, vk.Delta2})
+	if err != nil {
+		return err
+	}
+	if !rhs.Equal(&p.zAB) {
+		return fmt.Errorf("aggregated pairing equation not satisfied")
+	}
+
+	// fold the commitments and the products with the challenges
+	comAB, comC, zAB, zC := p.comAB, p.comC, p.zAB, p.zC
+	var xs, xsInv []fr.Element
+	for i := range p.rounds {
+		rd := &p.rounds[i]
+		rd.bind(t)
+		x, err := t.challenge()
+		if err != nil {
+			return err
+		}
+		var xInv fr.Element
+		xInv.Inverse(&x)
+		xs = append(xs, x)
+		xsInv = append(xsInv, xInv)
+
+		fold := func(acc, l, r *curve.GT) {
+			el, er := expGT(l, &x), expGT(r, &xInv)
+			acc.Mul(acc, &el).Mul(acc, &er)
+		}
+		for k := 0; k < 2; k++ {
+			fold(&comAB[k], &rd.comABL[k], &rd.comABR[k])
+			fold(&comC[k], &rd.comCL[k], &rd.comCR[k])
+		}
+		fold(&zAB, &rd.zABL, &rd.zABR)
+		var pl, pr curve.G1Affine
+		pl.ScalarMultiplication(&rd.zCL, toBig(&x))
+		pr.ScalarMultiplication(&rd.zCR, toBig(&xInv))
+		zC.Add(&zC, &pl).Add(&zC, &pr)
+	}
+
+	// the folded commitments and products are the ones of the final
+	// points and keys
+	for k := 0; k < 2; k++ {
+		com, err := commitPair([]curve.G1Affine{p.a}, []curve.G2Affine{p.b}, p.vr[k:k+1], p.w[k:k+1])
+		if err != nil {
+			return err
+		}
+		if !com.Equal(&comAB[k]) {
+			return fmt.Errorf("commitment to A and B does not match")
+		}
+		com, err = curve.Pair([]curve.G1Affine{p.c}, p.v[k:k+1])
+		if err != nil {
+			return err
+		}
+		if !com.Equal(&comC[k]) {
+			return fmt.Errorf("commitment to C does not match")
+		}
+	}
+	z1, err := curve.Pair([]curve.G1Affine{p.a}, []curve.G2Affine{p.b})
+	if err != nil {
+		return err
+	}
+	if !z1.Equal(&zAB) {
+		return fmt.Errorf("product of A and B does not match")
+	}
+	sFinal := evalFold(xsInv, r)
+	var c1 curve.G1Affine
+	c1.ScalarMultiplication(&p.c, toBig(&sFinal))
+	if !c1.Equal(&zC) {
+		return fmt.Errorf("combination of C does not match")
+	}
+
+	return p.verifyKeys(srs, t, r, xs, xsInv)
+}
+
+// verifyKeys checks the KZG openings of the final keys at z, for the
+// polynomials determined by the challenges, with a random linear
+// combination of the pairing equations.
+func (p *Proof) verifyKeys(srs *VerifierSRS, t *transcript, r fr.Element, xs, xsInv []fr.Element) error {
+	z, rho, err := p.bindFinal(t)
+	if err != nil {
+		return err
+	}
+	m := 1 << len(xs)
+
+	// f_v(z) + rho·f_v(z/r) and z^m f_w(z)
+	var rInv, zr, ev, ew fr.Element
+	rInv.Inverse(&r)
+	zr.Mul(&z, &rInv)
+	evr := evalFold(xsInv, zr)
+	ev = evalFold(xsInv, z)
+	evr.Mul(&evr, &rho)
+	ev.Add(&ev, &evr)
+	ew = evalFold(xs, z)
+	var zm fr.Element
+	zm.Exp(z, big.NewInt(int64(m)))
+	ew.Mul(&ew, &zm)
+
+	var g1z curve.G1Affine
+	var g2z curve.G2Affine
+	g1z.ScalarMultiplication(&srs.G1, toBig(&z))
+	g2z.ScalarMultiplication(&srs.G2, toBig(&z))
+	var g1ew curve.G1Affine
+	var g2ev curve.G2Affine
+	g2ev.ScalarMultiplication(&srs.G2, toBig(&ev))
+	g1ew.ScalarMultiplication(&srs.G1, toBig(&ew))
+
+	var g1s []curve.G1Affine
+	var g2s []curve.G2Affine
+	secretsG1 := [2]curve.G1Affine{srs.G1A, srs.G1B}
+	secretsG2 := [2]curve.G2Affine{srs.G2A, srs.G2B}
+	for k := 0; k < 2; k++ {
+		weights := make([]*big.Int, 2)
+		for i := range weights {
+			if weights[i], err = rand.Int(rand.Reader, fr.Modulus()); err != nil {
+				return err
+			}
+		}
+
+		// e([a - z]₁, openV) = e([1]₁, vF - [F(z)]₂), vF = v + rho·vr
+		var x1, y1 curve.G1Affine
+		var vF, tmp curve.G2Affine
+		x1.Neg(&g1z).Add(&x1, &secretsG1[k]).ScalarMultiplication(&x1, weights[0])
+		y1.Neg(&srs.G1).ScalarMultiplication(&y1, weights[0])
+		tmp.ScalarMultiplication(&p.vr[k], toBig(&rho))
+		vF.Add(&p.v[k], &tmp)
+		tmp.Neg(&g2ev)
+		vF.Add(&vF, &tmp)
+		g1s = append(g1s, x1, y1)
+		g2s = append(g2s, p.openV[k], vF)
+
+		// e(openW, [a - z]₂) = e(w - [z^m f_w(z)]₁, [1]₁)
+		var x2, y2 curve.G1Affine
+		var s2 curve.G2Affine
+		x2.ScalarMultiplication(&p.openW[k], weights[1])
+		y2.Neg(&g1ew).Add(&y2, &p.w[k]).Neg(&y2).ScalarMultiplication(&y2, weights[1])
+		s2.Neg(&g2z)
+		s2.Add(&s2, &secretsG2[k])
+		g1s = append(g1s, x2, y2)
+		g2s = append(g2s, s2, srs.G2)
+	}
+	ok, err := curve.PairingCheck(g1s, g2s)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("opening of the commitment keys not valid")
+	}
+
+	return nil
+}
+
+// gts returns the elements of GT of the proof in their order in the
+// binary format.
+func (p *Proof) gts() []*curve.GT {
+	res := []*curve.GT{&p.comAB[0], &p.comAB[1], &p.comC[0], &p.comC[1], &p.zAB}
+	for i := range p.rounds {
+		rd := &p.rounds[i]
+		res = append(res, &rd.comABL[0], &rd.comABL[1], &rd.comABR[0], &rd.comABR[1],
+			&rd.comCL[0], &rd.comCL[1], &rd.comCR[0], &rd.comCR[1], &rd.zABL, &rd.zABR)
+	}
+
+	return res
+}
+
+// points returns the points of the proof in their order in the binary
+// format.
+func (p *Proof) points() []interface{} {
+	res := []interface{}{&p.zC}
+	for i := range p.rounds {
+		res = append(res, &p.rounds[i].zCL, &p.rounds[i].zCR)
+	}
+
+	return append(res, &p.a, &p.c, &p.b, &p.vr[0], &p.vr[1], &p.v[0], &p.v[1], &p.w[0], &p.w[1],
+		&p.openV[0], &p.openV[1], &p.openW[0], &p.openW[1])
+}
+
+// WriteTo writes the number of proofs and of rounds, the elements of GT
+// and the points in the binary format of gnark-crypto.
+func (p *Proof) WriteTo(w io.Writer) (int64, error) {
+	var header [8]byte
+	binary.BigEndian.PutUint32(header[:4], uint32(p.n))
+	binary.BigEndian.PutUint32(header[4:], uint32(len(p.rounds)))
+	n, err := w.Write(header[:])
+	written := int64(n)
+	if err != nil {
+		return written, err
+	}
+	for _, x := range p.gts() {
+		b := x.Bytes()
+		n, err = w.Write(b[:])
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+
+	enc := curve.NewEncoder(w)
+	for _, v := range p.points() {
+		if err := enc.Encode(v); err != nil {
+			return written + enc.BytesWritten(), err
+		}
+	}
+
+	return written + enc.BytesWritten(), nil
+}
+
+// ReadFrom reads a proof written by WriteTo, checking that its elements
+// are in the subgroups.
+func (p *Proof) ReadFrom(r io.Reader) (int64, error) {
+	var header [8]byte
+	n, err := io.ReadFull(r, header[:])
+	read := int64(n)
+	if err != nil {
+		return read, err
+	}
+	p.n = int(binary.BigEndian.Uint32(header[:4]))
+	nbRounds := int(binary.BigEndian.Uint32(header[4:]))
+	if p.n == 0 || nbRounds > 28 || padding(p.n) != 1<<nbRounds {
+		return read, fmt.Errorf("invalid number of rounds")
+	}
+	p.rounds = make([]round, nbRounds)
+
+	var b [curve.SizeOfGT]byte
+	for _, x := range p.gts() {
+		n, err = io.ReadFull(r, b[:])
+		read += int64(n)
+		if err != nil {
+			return read, err
+		}
+		if err = x.SetBytes(b[:]); err != nil {
+			return read, err
+		}
+		if !x.IsInSubGroup() {
+			return read, fmt.Errorf("element not in GT")
+		}
+	}
+
+	dec := curve.NewDecoder(r)
+	for _, v := range p.points() {
+		if err := dec.Decode(v); err != nil {
+			return read + dec.BytesRead(), err
+		}
+	}
+
+	return read + dec.BytesRead(), nil
+}
@@ -0,0 +1,146 @@
+package proof_aggregation
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/krakenh2020/ZKPComponent/setup_ceremony"
+	"github.com/stretchr/testify/assert"
+)
+
+// cubeCircuit proves the knowledge of X with X³ + 3·X + 5 = Y
+type cubeCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *cubeCircuit) Define(api frontend.API) error {
+	x3 := api.Mul(c.X, c.X, c.X)
+	api.AssertIsEqual(c.Y, api.Add(x3, api.Mul(c.X, 3), 5))
+
+	return nil
+}
+
+func cubeProofs(t *testing.T, n int) ([]groth16.Proof, [][]fr.Element, groth16.VerifyingKey) {
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &cubeCircuit{})
+	assert.NoError(t, err)
+	pk, vk, err := groth16.Setup(ccs)
+	assert.NoError(t, err)
+
+	var proofs []groth16.Proof
+	var inputs [][]fr.Element
+	for i := 0; i < n; i++ {
+		x := uint64(i + 2)
+		var y fr.Element
+		y.SetUint64(x*x*x + 3*x + 5)
+		witness, err := frontend.NewWitness(&cubeCircuit{X: x, Y: y}, ecc.BN254.ScalarField())
+		assert.NoError(t, err)
+		proof, err := groth16.Prove(ccs, pk, witness)
+		assert.NoError(t, err)
+		proofs = append(proofs, proof)
+		inputs = append(inputs, []fr.Element{y})
+	}
+
+	return proofs, inputs, vk
+}
+
+func TestAggregate(t *testing.T) {
+	proofs, inputs, vk := cubeProofs(t, 5)
+	srs, err := GenerateSRS(3)
+	assert.NoError(t, err)
+
+	for _, n := range []int{1, 2, 5} {
+		p, err := Aggregate(srs, proofs[:n], inputs[:n])
+		assert.NoError(t, err)
+		assert.Equal(t, n, p.NbProofs())
+		assert.NoError(t, Verify(srs.VerifierSRS(), vk, inputs[:n], p))
+	}
+
+	p, err := Aggregate(srs, proofs, inputs)
+	assert.NoError(t, err)
+	var buf bytes.Buffer
+	_, err = p.WriteTo(&buf)
+	assert.NoError(t, err)
+	p2 := new(Proof)
+	_, err = p2.ReadFrom(&buf)
+	assert.NoError(t, err)
+	assert.NoError(t, Verify(srs.VerifierSRS(), vk, inputs, p2))
+
+	// other public inputs
+	other := append([][]fr.Element{}, inputs...)
+	other[1] = inputs[2]
+	assert.Error(t, Verify(srs.VerifierSRS(), vk, other, p))
+	assert.Error(t, Verify(srs.VerifierSRS(), vk, inputs[:4], p))
+
+	// a proof of other inputs
+	swapped := append([]groth16.Proof{}, proofs...)
+	swapped[0], swapped[1] = proofs[1], proofs[0]
+	p, err = Aggregate(srs, swapped, inputs)
+	assert.NoError(t, err)
+	assert.Error(t, Verify(srs.VerifierSRS(), vk, inputs, p))
+
+	// a final key not opened by the SRS
+	p, err = Aggregate(srs, proofs, inputs)
+	assert.NoError(t, err)
+	p.w[0] = p.a
+	assert.Error(t, Verify(srs.VerifierSRS(), vk, inputs, p))
+
+	// more proofs than the SRS
+	small, err := GenerateSRS(1)
+	assert.NoError(t, err)
+	_, err = Aggregate(small, proofs, inputs)
+	assert.Error(t, err)
+}
+
+// contribute returns a contribution to the parameters, leaving them
+// unchanged.
+func contribute(t *testing.T, prev *setup_ceremony.Phase1) *setup_ceremony.Phase1 {
+	var buf bytes.Buffer
+	_, err := prev.WriteTo(&buf)
+	assert.NoError(t, err)
+	next := new(setup_ceremony.Phase1)
+	_, err = next.ReadFrom(&buf)
+	assert.NoError(t, err)
+	assert.NoError(t, next.Contribute())
+
+	return next
+}
+
+func TestNewSRS(t *testing.T) {
+	proofs, inputs, vk := cubeProofs(t, 3)
+
+	var ceremonies [][]*setup_ceremony.Phase1
+	for i := 0; i < 2; i++ {
+		p1, err := setup_ceremony.InitPhase1(2)
+		assert.NoError(t, err)
+		_, err = NewSRS([]*setup_ceremony.Phase1{p1}, []*setup_ceremony.Phase1{p1})
+		assert.Error(t, err)
+		ceremonies = append(ceremonies, []*setup_ceremony.Phase1{p1, contribute(t, p1)})
+	}
+	_, err := NewSRS(ceremonies[0], ceremonies[0])
+	assert.Error(t, err)
+	// a transcript not starting from the initial parameters
+	_, err = NewSRS(ceremonies[0][1:], ceremonies[1])
+	assert.Error(t, err)
+	_, err = NewSRS(ceremonies[0], []*setup_ceremony.Phase1{ceremonies[0][1], contribute(t, ceremonies[0][1])})
+	assert.Error(t, err)
+	srs, err := NewSRS(ceremonies[0], ceremonies[1])
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	_, err = srs.WriteTo(&buf)
+	assert.NoError(t, err)
+	srs2 := new(SRS)
+	_, err = srs2.ReadFrom(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, srs2.Size())
+
+	p, err := Aggregate(srs2, proofs, inputs)
+	assert.NoError(t, err)
+	assert.NoError(t, Verify(srs.VerifierSRS(), vk, inputs, p))
+}
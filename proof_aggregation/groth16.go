@@ -0,0 +1,72 @@
+package proof_aggregation
+
+import (
+	"bytes"
+	"fmt"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+)
+
+// Groth16Key is the verifying key of a BN254 circuit without gnark
+// commitments: K holds the points of the public wires, the constant
+// wire first.
+type Groth16Key struct {
+	Alpha, Beta1, Delta1  curve.G1Affine
+	Beta2, Gamma2, Delta2 curve.G2Affine
+	K                     []curve.G1Affine
+}
+
+// DecodeVerifyingKey returns the points of the verifying key, used by
+// the aggregation and by the batch verification of the dataset proofs,
+// which both leave out the commitments of gnark.
+func DecodeVerifyingKey(verKey groth16.VerifyingKey) (*Groth16Key, error) {
+	if bn254Key, ok := verKey.(*groth16_bn254.VerifyingKey); !ok || len(bn254Key.PublicAndCommitmentCommitted) != 0 {
+		return nil, fmt.Errorf("verifying key not of a BN254 circuit without commitments")
+	}
+	var buf bytes.Buffer
+	if _, err := verKey.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	// [α]1,[β]1,[β]2,[γ]2,[δ]1,[δ]2,[Kvk]1
+	var vk Groth16Key
+	dec := curve.NewDecoder(&buf)
+	for _, v := range []interface{}{&vk.Alpha, &vk.Beta1, &vk.Beta2, &vk.Gamma2, &vk.Delta1, &vk.Delta2, &vk.K} {
+		if err := dec.Decode(v); err != nil {
+			return nil, err
+		}
+	}
+	if len(vk.K) == 0 {
+		return nil, fmt.Errorf("verifying key without public wires")
+	}
+
+	return &vk, nil
+}
+
+// Groth16Proof is a decoded Groth16 proof.
+type Groth16Proof struct {
+	A, C curve.G1Affine
+	B    curve.G2Affine
+}
+
+// DecodeProof returns the points of a proof of a BN254 circuit without
+// gnark commitments, checking that they are in the subgroups.
+func DecodeProof(proof groth16.Proof) (*Groth16Proof, error) {
+	if bn254Proof, ok := proof.(*groth16_bn254.Proof); !ok || len(bn254Proof.Commitments) != 0 {
+		return nil, fmt.Errorf("proof not of a BN254 circuit without commitments")
+	}
+	var buf bytes.Buffer
+	if _, err := proof.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	var p Groth16Proof
+	dec := curve.NewDecoder(&buf)
+	for _, v := range []interface{}{&p.A, &p.B, &p.C} {
+		if err := dec.Decode(v); err != nil {
+			return nil, err
+		}
+	}
+
+	return &p, nil
+}
@@ -0,0 +1,143 @@
+package proof_aggregation
+
+import (
+	"fmt"
+	"io"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/krakenh2020/ZKPComponent/setup_ceremony"
+)
+
+// SRS is the structured reference string of the aggregation of up to
+// N = Size() proofs: the powers of two independent secrets a and b,
+// committing to the proofs with the keys [a^i]₂, [b^i]₂ and [a^(N+i)]₁,
+// [b^(N+i)]₁, i < N.
+type SRS struct {
+	G1 struct {
+		A, B []curve.G1Affine // [a^i]₁, [b^i]₁, i < 2N
+	}
+	G2 struct {
+		A, B []curve.G2Affine // [a^i]₂, [b^i]₂, i < N
+	}
+}
+
+// VerifierSRS is the part of the SRS needed by Verify.
+type VerifierSRS struct {
+	G1   curve.G1Affine // [1]₁
+	G1A  curve.G1Affine // [a]₁
+	G1B  curve.G1Affine // [b]₁
+	G2   curve.G2Affine // [1]₂
+	G2A  curve.G2Affine // [a]₂
+	G2B  curve.G2Affine // [b]₂
+	Size int
+}
+
+// NewSRS returns the SRS of the τ of the last contributions to two
+// independent powers of tau ceremonies, whose transcripts, from
+// setup_ceremony.InitPhase1, are checked with
+// setup_ceremony.VerifyPhase1Transcript. It aggregates up to the number
+// of constraints of the ceremonies.
+func NewSRS(transcriptA, transcriptB []*setup_ceremony.Phase1) (*SRS, error) {
+	for _, transcript := range [][]*setup_ceremony.Phase1{transcriptA, transcriptB} {
+		if err := setup_ceremony.VerifyPhase1Transcript(transcript); err != nil {
+			return nil, err
+		}
+	}
+	a, b := transcriptA[len(transcriptA)-1], transcriptB[len(transcriptB)-1]
+	if a.Size() != b.Size() {
+		return nil, fmt.Errorf("ceremonies of different sizes")
+	}
+	_, _, g1, _ := curve.Generators()
+	tauA, tauB := &a.Parameters.G1.Tau[1], &b.Parameters.G1.Tau[1]
+	if tauA.Equal(&g1) || tauB.Equal(&g1) || tauA.Equal(tauB) {
+		return nil, fmt.Errorf("ceremonies without independent contributions")
+	}
+
+	var srs SRS
+	srs.G1.A = a.Parameters.G1.Tau
+	srs.G1.B = b.Parameters.G1.Tau
+	srs.G2.A = a.Parameters.G2.Tau
+	srs.G2.B = b.Parameters.G2.Tau
+
+	return &srs, nil
+}
+
+// GenerateSRS samples a and b and returns the SRS aggregating up to
+// 2^power proofs. Whoever runs it can forge aggregated proofs, use
+// NewSRS outside of tests.
+func GenerateSRS(power int) (*SRS, error) {
+	if power < 1 || power > 28 {
+		return nil, fmt.Errorf("invalid power %d", power)
+	}
+	n := 1 << power
+	_, _, g1, g2 := curve.Generators()
+
+	var srs SRS
+	for _, s := range []struct {
+		g1 *[]curve.G1Affine
+		g2 *[]curve.G2Affine
+	}{{&srs.G1.A, &srs.G2.A}, {&srs.G1.B, &srs.G2.B}} {
+		var x fr.Element
+		if _, err := x.SetRandom(); err != nil {
+			return nil, err
+		}
+		powers := make([]fr.Element, 2*n)
+		powers[0].SetOne()
+		for i := 1; i < len(powers); i++ {
+			powers[i].Mul(&powers[i-1], &x)
+		}
+		*s.g1 = curve.BatchScalarMultiplicationG1(&g1, powers)
+		*s.g2 = curve.BatchScalarMultiplicationG2(&g2, powers[:n])
+	}
+
+	return &srs, nil
+}
+
+// Size is the maximal number of aggregated proofs.
+func (srs *SRS) Size() int {
+	return len(srs.G2.A)
+}
+
+// VerifierSRS returns the part of the SRS needed by Verify.
+func (srs *SRS) VerifierSRS() *VerifierSRS {
+	return &VerifierSRS{
+		G1: srs.G1.A[0], G1A: srs.G1.A[1], G1B: srs.G1.B[1],
+		G2: srs.G2.A[0], G2A: srs.G2.A[1], G2B: srs.G2.B[1],
+		Size: srs.Size(),
+	}
+}
+
+func (srs *SRS) check() error {
+	n := srs.Size()
+	if n < 2 || n&(n-1) != 0 || len(srs.G2.B) != n || len(srs.G1.A) != 2*n || len(srs.G1.B) != 2*n {
+		return fmt.Errorf("invalid SRS")
+	}
+
+	return nil
+}
+
+// WriteTo writes the SRS in the binary format of gnark-crypto.
+func (srs *SRS) WriteTo(w io.Writer) (int64, error) {
+	enc := curve.NewEncoder(w)
+	for _, v := range []interface{}{srs.G1.A, srs.G1.B, srs.G2.A, srs.G2.B} {
+		if err := enc.Encode(v); err != nil {
+			return enc.BytesWritten(), err
+		}
+	}
+
+	return enc.BytesWritten(), nil
+}
+
+// ReadFrom reads an SRS written by WriteTo, checking that its points
+// are in the subgroups.
+func (srs *SRS) ReadFrom(r io.Reader) (int64, error) {
+	dec := curve.NewDecoder(r)
+	for _, v := range []interface{}{&srs.G1.A, &srs.G1.B, &srs.G2.A, &srs.G2.B} {
+		if err := dec.Decode(v); err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+
+	return dec.BytesRead(), srs.check()
+}
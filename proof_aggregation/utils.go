@@ -0,0 +1,193 @@
+package proof_aggregation
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"math/big"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// transcriptDST separates the Fiat-Shamir challenges of the aggregation
+// from any other use of the hash
+const transcriptDST = "KRAKEN-ZKPComponent-V01-AGGREGATION"
+
+// transcript derives the Fiat-Shamir challenges from everything the
+// prover sent before them.
+type transcript struct {
+	h hash.Hash
+}
+
+func newTranscript() *transcript {
+	t := &transcript{h: sha256.New()}
+	t.h.Write([]byte(transcriptDST))
+
+	return t
+}
+
+func (t *transcript) gt(xs ...*curve.GT) {
+	for _, x := range xs {
+		b := x.Bytes()
+		t.h.Write(b[:])
+	}
+}
+
+func (t *transcript) g1(ps ...*curve.G1Affine) {
+	for _, p := range ps {
+		b := p.Bytes()
+		t.h.Write(b[:])
+	}
+}
+
+func (t *transcript) g2(ps ...*curve.G2Affine) {
+	for _, p := range ps {
+		b := p.Bytes()
+		t.h.Write(b[:])
+	}
+}
+
+// inputs binds the number of proofs and their public inputs.
+func (t *transcript) inputs(inputs [][]fr.Element) {
+	var n fr.Element
+	n.SetUint64(uint64(len(inputs)))
+	b := n.Bytes()
+	t.h.Write(b[:])
+	for i := range inputs {
+		n.SetUint64(uint64(len(inputs[i])))
+		b = n.Bytes()
+		t.h.Write(b[:])
+		for j := range inputs[i] {
+			b = inputs[i][j].Bytes()
+			t.h.Write(b[:])
+		}
+	}
+}
+
+// challenge returns a challenge, the hash of the transcript so far,
+// and starts the transcript of the next one with it.
+func (t *transcript) challenge() (fr.Element, error) {
+	d := t.h.Sum(nil)
+	t.h.Reset()
+	t.h.Write(d)
+
+	var x fr.Element
+	x.SetBytes(d)
+	if x.IsZero() {
+		return x, fmt.Errorf("zero challenge")
+	}
+
+	return x, nil
+}
+
+// padding returns the number of proofs committed to, the power of two
+// not smaller than n and 2.
+func padding(n int) int {
+	m := 2
+	for m < n {
+		m *= 2
+	}
+
+	return m
+}
+
+// powers returns x^i, i < n.
+func powers(x fr.Element, n int) []fr.Element {
+	res := make([]fr.Element, n)
+	res[0].SetOne()
+	for i := 1; i < n; i++ {
+		res[i].Mul(&res[i-1], &x)
+	}
+
+	return res
+}
+
+func toBig(x *fr.Element) *big.Int {
+	return x.BigInt(new(big.Int))
+}
+
+func expGT(x *curve.GT, e *fr.Element) curve.GT {
+	var res curve.GT
+	res.Exp(*x, toBig(e))
+
+	return res
+}
+
+// foldG1 returns l_i + x·r_i.
+func foldG1(l, r []curve.G1Affine, x *fr.Element) []curve.G1Affine {
+	res := make([]curve.G1Affine, len(l))
+	e := toBig(x)
+	for i := range res {
+		res[i].ScalarMultiplication(&r[i], e)
+		res[i].Add(&res[i], &l[i])
+	}
+
+	return res
+}
+
+// foldG2 returns l_i + x·r_i.
+func foldG2(l, r []curve.G2Affine, x *fr.Element) []curve.G2Affine {
+	res := make([]curve.G2Affine, len(l))
+	e := toBig(x)
+	for i := range res {
+		res[i].ScalarMultiplication(&r[i], e)
+		res[i].Add(&res[i], &l[i])
+	}
+
+	return res
+}
+
+// foldScalars returns l_i + x·r_i.
+func foldScalars(l, r []fr.Element, x *fr.Element) []fr.Element {
+	res := make([]fr.Element, len(l))
+	for i := range res {
+		res[i].Mul(&r[i], x).Add(&res[i], &l[i])
+	}
+
+	return res
+}
+
+// foldCoefficients returns the coefficients of
+// prod_j (1 + c_j X^(m/2^(j+1))), m = 2^len(c), the polynomial of the
+// final key of the folding of the halves with c_j in round j.
+func foldCoefficients(c []fr.Element) []fr.Element {
+	res := []fr.Element{fr.One()}
+	for j := len(c) - 1; j >= 0; j-- {
+		n := len(res)
+		for i := 0; i < n; i++ {
+			var t fr.Element
+			t.Mul(&res[i], &c[j])
+			res = append(res, t)
+		}
+	}
+
+	return res
+}
+
+// evalFold evaluates the polynomial of foldCoefficients(c) at y with
+// one multiplication per round.
+func evalFold(c []fr.Element, y fr.Element) fr.Element {
+	one := fr.One()
+	res := one
+	for j := len(c) - 1; j >= 0; j-- {
+		var t fr.Element
+		t.Mul(&c[j], &y).Add(&t, &one)
+		res.Mul(&res, &t)
+		// the power of the previous round
+		y.Square(&y)
+	}
+
+	return res
+}
+
+// divideLinear returns the quotient of p by X - z, dropping the rest.
+func divideLinear(p []fr.Element, z fr.Element) []fr.Element {
+	q := make([]fr.Element, len(p)-1)
+	q[len(q)-1] = p[len(p)-1]
+	for i := len(q) - 1; i > 0; i-- {
+		q[i-1].Mul(&q[i], &z).Add(&q[i-1], &p[i])
+	}
+
+	return q
+}
@@ -0,0 +1,166 @@
+package prover_service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/constraint"
+	"github.com/krakenh2020/ZKPComponent"
+	"github.com/krakenh2020/ZKPComponent/signature"
+	"github.com/krakenh2020/ZKPComponent/signature/ec"
+)
+
+// Config bounds the resources used by the Handler.
+type Config struct {
+	// MaxRequestBytes is the maximal size of a request body.
+	MaxRequestBytes int64
+	// MaxConcurrent is the maximal number of proofs computed at the
+	// same time, further requests are refused with 503.
+	MaxConcurrent int
+}
+
+var DefaultConfig = Config{MaxRequestBytes: 32 << 20, MaxConcurrent: 2}
+
+// ProveRequest is the body of a request: a signed csv as written by
+// signature.WriteSignCsv and the NaCl public keys of the 3 MPC nodes.
+type ProveRequest struct {
+	Csv     string
+	PubKeys [][]byte
+}
+
+// ProveResponse holds the encrypted share container, in the format read
+// by data_common.ReadShare and ZKPComponent.ReadAuth, and the AuthProof
+// it contains.
+type ProveResponse struct {
+	Container []byte
+	Auth      *ZKPComponent.AuthProof
+}
+
+// Handler is an http.Handler splitting, encrypting and proving signed
+// datasets. The proving key and the compiled CircuitDataset are loaded
+// once and shared by all the requests. If the handler has the artifacts
+// of the circuit, each dataset is checked to be of their circuit.
+type Handler struct {
+	proofKey  groth16.ProvingKey
+	r1cs      constraint.ConstraintSystem
+	artifacts *ZKPComponent.CircuitArtifacts
+	cfg       Config
+	sem       chan struct{}
+}
+
+// NewHandler loads the proving key from proofKeyFile (see
+// ZKPComponent.LoadProvingKey) and compiles the circuit.
+func NewHandler(proofKeyFile string, cfg Config) (*Handler, error) {
+	proofKey, err := ZKPComponent.LoadProvingKey(proofKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	r1cs, err := ZKPComponent.CompileCircuitDataset()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewHandlerWithKey(proofKey, r1cs, cfg), nil
+}
+
+// NewHandlerFromStore loads the proving key and the compiled
+// CircuitDataset of the current circuit version from the store, checked
+// against the compiled circuit, see ZKPComponent.ArtifactStore.Load.
+func NewHandlerFromStore(store *ZKPComponent.ArtifactStore, cfg Config) (*Handler, error) {
+	a, err := store.Load(ZKPComponent.CircuitId{Name: ZKPComponent.CircuitDatasetName,
+		Version: signature.CurrentCircuitVersion})
+	if err != nil {
+		return nil, err
+	}
+	h := NewHandlerWithKey(a.ProvingKey, a.R1CS, cfg)
+	h.artifacts = a
+
+	return h, nil
+}
+
+func NewHandlerWithKey(proofKey groth16.ProvingKey, r1cs constraint.ConstraintSystem, cfg Config) *Handler {
+	if cfg.MaxRequestBytes <= 0 {
+		cfg.MaxRequestBytes = DefaultConfig.MaxRequestBytes
+	}
+	if cfg.MaxConcurrent <= 0 {
+		cfg.MaxConcurrent = DefaultConfig.MaxConcurrent
+	}
+
+	return &Handler{proofKey: proofKey, r1cs: r1cs, cfg: cfg, sem: make(chan struct{}, cfg.MaxConcurrent)}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req ProveRequest
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, h.cfg.MaxRequestBytes)).Decode(&req)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			http.Error(w, "request too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(req.PubKeys) != 3 {
+		http.Error(w, "invalid request: expected 3 public keys", http.StatusBadRequest)
+		return
+	}
+	for _, e := range req.PubKeys {
+		if len(e) != 32 {
+			http.Error(w, "invalid request: public keys must have 32 bytes", http.StatusBadRequest)
+			return
+		}
+	}
+
+	select {
+	case h.sem <- struct{}{}:
+		defer func() { <-h.sem }()
+	default:
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, "too many concurrent requests", http.StatusServiceUnavailable)
+		return
+	}
+
+	res, err := h.prove(&req)
+	if err != nil {
+		http.Error(w, "proving failed: "+err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+func (h *Handler) prove(req *ProveRequest) (*ProveResponse, error) {
+	var buf bytes.Buffer
+	var proof groth16.Proof
+	var commits []*ec.Ec
+	var sign *signature.SignatureZKP
+	var err error
+	if h.artifacts != nil {
+		_, proof, commits, _, sign, err = ZKPComponent.CsvTextSplitEncryptAndZkpWithArtifacts(req.Csv, &buf, h.artifacts,
+			req.PubKeys)
+	} else {
+		_, proof, commits, _, sign, err = ZKPComponent.CsvTextSplitEncryptAndZkpToWriter(req.Csv, &buf, h.proofKey, h.r1cs,
+			req.PubKeys)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	aProof, err := ZKPComponent.NewAuthProof(proof, commits, sign)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProveResponse{Container: buf.Bytes(), Auth: aProof}, nil
+}
@@ -0,0 +1,128 @@
+package prover_service
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	sig "github.com/consensys/gnark-crypto/signature"
+	"github.com/krakenh2020/ZKPComponent"
+	"github.com/krakenh2020/ZKPComponent/data_common"
+	"github.com/krakenh2020/ZKPComponent/key_management"
+	"github.com/krakenh2020/ZKPComponent/signature"
+	"github.com/stretchr/testify/assert"
+)
+
+func post(h http.Handler, req *ProveRequest) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(req)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/prove", bytes.NewReader(body)))
+
+	return rec
+}
+
+func TestHandler(t *testing.T) {
+	h, err := NewHandler("../proofKey.txt", Config{MaxRequestBytes: 1 << 20, MaxConcurrent: 1})
+	assert.NoError(t, err)
+	vk, err := ZKPComponent.LoadVerifyingKey("../verifyKey.txt")
+	assert.NoError(t, err)
+
+	signer, err := eddsa.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	dir := t.TempDir()
+	signed := filepath.Join(dir, "signed.csv")
+	sign, err := signature.SignCsv("../datasets/framingham_tiny.csv", signer)
+	assert.NoError(t, err)
+	assert.NoError(t, signature.WriteSignCsv("../datasets/framingham_tiny.csv", signed, sign))
+	csvText, err := os.ReadFile(signed)
+	assert.NoError(t, err)
+
+	pubKey, secKey := key_management.GenerateKeypair()
+	req := &ProveRequest{Csv: string(csvText), PubKeys: [][]byte{pubKey, pubKey, pubKey}}
+
+	rec := post(h, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var res ProveResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+
+	// a node reads and verifies its share from the returned container
+	container := filepath.Join(dir, "enc.txt")
+	assert.NoError(t, os.WriteFile(container, res.Container, 0600))
+	aProof, err := ZKPComponent.ReadAuth(container)
+	assert.NoError(t, err)
+	assert.Equal(t, res.Auth.ZkProof, aProof.ZkProof)
+	proof, commits, sign2, signPubKey, err := ZKPComponent.ExpandAuthProof(aProof)
+	assert.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		share, cols, err := data_common.ReadShare(container, pubKey, secKey, i)
+		assert.NoError(t, err)
+		check, err := ZKPComponent.VerifyDatasetSplitAndZKpCsv(proof, vk, share, i, commits, cols, sign2, signPubKey)
+		assert.NoError(t, err)
+		assert.True(t, check)
+	}
+
+	// unsigned data cannot be proven
+	unsigned, err := os.ReadFile("../datasets/framingham_tiny.csv")
+	assert.NoError(t, err)
+	rec = post(h, &ProveRequest{Csv: string(unsigned), PubKeys: req.PubKeys})
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	rec = post(h, &ProveRequest{Csv: string(csvText), PubKeys: req.PubKeys[:2]})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = post(h, &ProveRequest{Csv: strings.Repeat("1", 2<<20), PubKeys: req.PubKeys})
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/prove", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	// all the proving slots are taken
+	h.sem <- struct{}{}
+	rec = post(h, req)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	<-h.sem
+}
+
+func TestNewHandlerFromStore(t *testing.T) {
+	store := ZKPComponent.NewArtifactStore(t.TempDir())
+	_, err := NewHandlerFromStore(store, DefaultConfig)
+	assert.Error(t, err)
+
+	id := ZKPComponent.CircuitId{Name: ZKPComponent.CircuitDatasetName, Version: signature.CurrentCircuitVersion}
+	_, err = store.ImportLegacyKeys(id, "../proofKey.txt", "../verifyKey.txt")
+	assert.NoError(t, err)
+	h, err := NewHandlerFromStore(store, DefaultConfig)
+	assert.NoError(t, err)
+	assert.NotNil(t, h.r1cs)
+
+	pubKey, _ := key_management.GenerateKeypair()
+	pubKeys := [][]byte{pubKey, pubKey, pubKey}
+	dir := t.TempDir()
+	eddsaKey, err := eddsa.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	ecdsaKey, err := signature.GenerateECDSAP256(rand.Reader)
+	assert.NoError(t, err)
+	for _, c := range []struct {
+		signer sig.Signer
+		code   int
+	}{{eddsaKey, http.StatusOK}, {ecdsaKey, http.StatusUnprocessableEntity}} {
+		signed := filepath.Join(dir, "signed.csv")
+		sign, err := signature.SignCsv("../datasets/framingham_tiny.csv", c.signer)
+		assert.NoError(t, err)
+		assert.NoError(t, signature.WriteSignCsv("../datasets/framingham_tiny.csv", signed, sign))
+		csvText, err := os.ReadFile(signed)
+		assert.NoError(t, err)
+
+		// the keys of CircuitDataset do not prove the ECDSA signatures
+		rec := post(h, &ProveRequest{Csv: string(csvText), PubKeys: pubKeys})
+		assert.Equal(t, c.code, rec.Code)
+	}
+}
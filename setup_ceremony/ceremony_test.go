@@ -0,0 +1,209 @@
+package setup_ceremony
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/assert"
+)
+
+// cubeCircuit proves the knowledge of X with X³ + 3·X + 5 = Y
+type cubeCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *cubeCircuit) Define(api frontend.API) error {
+	x3 := api.Mul(c.X, c.X, c.X)
+	api.AssertIsEqual(c.Y, api.Add(x3, api.Mul(c.X, 3), 5))
+
+	return nil
+}
+
+func TestCeremony(t *testing.T) {
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &cubeCircuit{})
+	assert.NoError(t, err)
+
+	// three participants in each phase
+	p1, err := InitPhase1(3)
+	assert.NoError(t, err)
+	transcript1 := []*Phase1{p1}
+	for i := 0; i < 3; i++ {
+		var buf bytes.Buffer
+		_, err = transcript1[i].WriteTo(&buf)
+		assert.NoError(t, err)
+		next := new(Phase1)
+		_, err = next.ReadFrom(&buf)
+		assert.NoError(t, err)
+		assert.NoError(t, next.Contribute())
+		transcript1 = append(transcript1, next)
+	}
+	assert.NoError(t, VerifyPhase1Transcript(transcript1))
+
+	p2, evals, err := InitPhase2(ccs, transcript1[3])
+	assert.NoError(t, err)
+	transcript2 := []*Phase2{p2}
+	for i := 0; i < 3; i++ {
+		var buf bytes.Buffer
+		_, err = transcript2[i].WriteTo(&buf)
+		assert.NoError(t, err)
+		next := new(Phase2)
+		_, err = next.ReadFrom(&buf)
+		assert.NoError(t, err)
+		assert.NoError(t, next.Contribute())
+		transcript2 = append(transcript2, next)
+	}
+	init, _, err := InitPhase2(ccs, transcript1[3])
+	assert.NoError(t, err)
+	assert.NoError(t, VerifyPhase2Transcript(init, transcript2))
+
+	var buf bytes.Buffer
+	_, err = evals.WriteTo(&buf)
+	assert.NoError(t, err)
+	evals = new(Phase2Evaluations)
+	_, err = evals.ReadFrom(&buf)
+	assert.NoError(t, err)
+	pk, vk, err := ExtractKeys(transcript2[3], evals)
+	assert.NoError(t, err)
+
+	witness, err := frontend.NewWitness(&cubeCircuit{X: 3, Y: 41}, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+	proof, err := groth16.Prove(ccs, pk, witness)
+	assert.NoError(t, err)
+	public, err := frontend.NewWitness(&cubeCircuit{Y: 41}, ecc.BN254.ScalarField(), frontend.PublicOnly())
+	assert.NoError(t, err)
+	assert.NoError(t, groth16.Verify(proof, vk, public))
+	public, err = frontend.NewWitness(&cubeCircuit{Y: 42}, ecc.BN254.ScalarField(), frontend.PublicOnly())
+	assert.NoError(t, err)
+	assert.Error(t, groth16.Verify(proof, vk, public))
+}
+
+func TestCeremonyTampered(t *testing.T) {
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &cubeCircuit{})
+	assert.NoError(t, err)
+
+	p1, err := InitPhase1(3)
+	assert.NoError(t, err)
+	var next Phase1
+	copyPhase1(&next, p1)
+	assert.NoError(t, next.Contribute())
+	assert.NoError(t, VerifyPhase1(p1, &next))
+
+	// a contribution reusing the proof of knowledge of another one
+	var replay Phase1
+	copyPhase1(&replay, &next)
+	assert.NoError(t, replay.Contribute())
+	replay.PublicKeys.Tau = next.PublicKeys.Tau
+	replay.Hash, err = transcriptHash(replay.encode)
+	assert.NoError(t, err)
+	assert.Error(t, VerifyPhase1(&next, &replay))
+
+	// powers of tau which are not powers
+	var tampered Phase1
+	copyPhase1(&tampered, &next)
+	assert.NoError(t, tampered.Contribute())
+	tampered.Parameters.G1.Tau[3] = tampered.Parameters.G1.Tau[2]
+	tampered.Hash, err = transcriptHash(tampered.encode)
+	assert.NoError(t, err)
+	assert.Error(t, VerifyPhase1(&next, &tampered))
+
+	p2, _, err := InitPhase2(ccs, &next)
+	assert.NoError(t, err)
+	var c Phase2
+	copyPhase2(&c, p2)
+	assert.NoError(t, c.Contribute())
+	assert.NoError(t, VerifyPhase2(p2, &c))
+
+	// [δ] updated without dividing L
+	var t2 Phase2
+	copyPhase2(&t2, p2)
+	assert.NoError(t, t2.Contribute())
+	t2.Parameters.G1.L = p2.Parameters.G1.L
+	t2.Hash, err = transcriptHash(t2.encode)
+	assert.NoError(t, err)
+	assert.Error(t, VerifyPhase2(p2, &t2))
+
+	// a transcript hash not matching the contribution
+	copyPhase2(&t2, p2)
+	assert.NoError(t, t2.Contribute())
+	t2.Hash[0] ^= 1
+	assert.Error(t, VerifyPhase2(p2, &t2))
+
+	// a transcript starting from other parameters with the initial hash
+	p2, _, err = InitPhase2(ccs, &next)
+	assert.NoError(t, err)
+	var start Phase2
+	copyPhase2(&start, p2)
+	start.Parameters.G1.L[0] = start.Parameters.G1.L[1]
+	copyPhase2(&c, &start)
+	assert.NoError(t, c.Contribute())
+	assert.NoError(t, VerifyPhase2(&start, &c))
+	assert.Error(t, VerifyPhase2Transcript(p2, []*Phase2{&start, &c}))
+	_, _, err = ExtractVerifiedKeys(ccs, []*Phase1{p1, &next}, []*Phase2{&start, &c})
+	assert.Error(t, err)
+
+	// the keys are extracted from the evaluations at the phase 1
+	// parameters of the transcript
+	copyPhase2(&c, p2)
+	assert.NoError(t, c.Contribute())
+	_, _, err = ExtractVerifiedKeys(ccs, []*Phase1{p1, &next}, []*Phase2{p2, &c})
+	assert.NoError(t, err)
+	var other Phase1
+	copyPhase1(&other, p1)
+	assert.NoError(t, other.Contribute())
+	_, _, err = ExtractVerifiedKeys(ccs, []*Phase1{p1, &other}, []*Phase2{p2, &c})
+	assert.Error(t, err)
+
+	// a phase 1 which is not verified: no contribution, an invalid
+	// one, or parameters of known secrets with the initial hash
+	_, _, err = ExtractVerifiedKeys(ccs, []*Phase1{&next}, []*Phase2{p2, &c})
+	assert.Error(t, err)
+	_, _, err = ExtractVerifiedKeys(ccs, []*Phase1{p1, &next, &replay}, []*Phase2{p2, &c})
+	assert.Error(t, err)
+	var known Phase1
+	copyPhase1(&known, &next)
+	known.Hash = p1.Hash
+	assert.Error(t, VerifyPhase1Transcript([]*Phase1{&known, &next}))
+	_, _, err = InitVerifiedPhase2(ccs, []*Phase1{&known, &next})
+	assert.Error(t, err)
+}
+
+// committedCircuit is cubeCircuit with a gnark commitment to X
+type committedCircuit struct {
+	cubeCircuit
+}
+
+func (c *committedCircuit) Define(api frontend.API) error {
+	commitment, err := api.(frontend.Committer).Commit(c.X)
+	if err != nil {
+		return err
+	}
+	api.AssertIsDifferent(commitment, 0)
+
+	return c.cubeCircuit.Define(api)
+}
+
+func TestCeremonyCommitments(t *testing.T) {
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &committedCircuit{})
+	assert.NoError(t, err)
+	p1, err := InitPhase1(3)
+	assert.NoError(t, err)
+	_, _, err = InitPhase2(ccs, p1)
+	assert.Error(t, err)
+}
+
+func copyPhase1(dst, src *Phase1) {
+	var buf bytes.Buffer
+	src.WriteTo(&buf)
+	dst.ReadFrom(&buf)
+}
+
+func copyPhase2(dst, src *Phase2) {
+	var buf bytes.Buffer
+	src.WriteTo(&buf)
+	dst.ReadFrom(&buf)
+}
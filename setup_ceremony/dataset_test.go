@@ -0,0 +1,81 @@
+package setup_ceremony_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"math/bits"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	"github.com/krakenh2020/ZKPComponent"
+	"github.com/krakenh2020/ZKPComponent/setup_ceremony"
+	"github.com/krakenh2020/ZKPComponent/signature"
+	"github.com/stretchr/testify/assert"
+)
+
+// The test of the ceremony of CircuitDataset is outside of the package,
+// which ZKPComponent imports through the proof aggregation.
+
+func TestCeremonyCircuitDataset(t *testing.T) {
+	if testing.Short() {
+		t.Skip("the ceremony of CircuitDataset takes minutes")
+	}
+	id := ZKPComponent.CircuitId{Name: ZKPComponent.CircuitDatasetName, Version: signature.CurrentCircuitVersion}
+	ccs, err := id.Compile()
+	assert.NoError(t, err)
+	power := bits.Len(uint(ccs.GetNbConstraints() - 1))
+
+	// two participants in each phase
+	p1, err := setup_ceremony.InitPhase1(power)
+	assert.NoError(t, err)
+	transcript1 := []*setup_ceremony.Phase1{p1}
+	for i := 0; i < 2; i++ {
+		next := new(setup_ceremony.Phase1)
+		clone(next, transcript1[i])
+		assert.NoError(t, next.Contribute())
+		transcript1 = append(transcript1, next)
+	}
+	p2, evals, err := setup_ceremony.InitVerifiedPhase2(ccs, transcript1)
+	assert.NoError(t, err)
+	transcript2 := []*setup_ceremony.Phase2{p2}
+	for i := 0; i < 2; i++ {
+		next := new(setup_ceremony.Phase2)
+		clone(next, transcript2[i])
+		assert.NoError(t, next.Contribute())
+		transcript2 = append(transcript2, next)
+	}
+	// ExtractVerifiedKeys without evaluating the circuit again
+	assert.NoError(t, setup_ceremony.VerifyPhase2Transcript(p2, transcript2))
+	pk, vk, err := setup_ceremony.ExtractKeys(transcript2[2], evals)
+	assert.NoError(t, err)
+	_, err = ZKPComponent.NewCircuitArtifacts(id, ccs, pk, vk)
+	assert.NoError(t, err)
+
+	// a signed dataset is proven and verified with the keys
+	signer, err := eddsa.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	sign, err := signature.SignCsv("../datasets/framingham_tiny.csv", signer)
+	assert.NoError(t, err)
+	signed := filepath.Join(t.TempDir(), "signed.csv")
+	assert.NoError(t, signature.WriteSignCsv("../datasets/framingham_tiny.csv", signed, sign))
+	splits, proof, commits, cols, pubSign, err := ZKPComponent.DatasetSplitAndZkpCsv(signed, pk, ccs)
+	assert.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		check, err := ZKPComponent.VerifyDatasetSplitAndZKpCsv(proof, vk, splits[i], i, commits, cols, pubSign,
+			signer.Public())
+		assert.NoError(t, err)
+		assert.True(t, check)
+	}
+	_, err = ZKPComponent.VerifyDatasetZKp(proof, vk, commits, cols[1:], pubSign, signer.Public())
+	assert.Error(t, err)
+}
+
+// clone copies the parameters through their encoding, as a participant
+// receiving them.
+func clone(dst io.ReaderFrom, src io.WriterTo) {
+	var buf bytes.Buffer
+	src.WriteTo(&buf)
+	dst.ReadFrom(&buf)
+}
@@ -0,0 +1,276 @@
+package setup_ceremony
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// Phase1 is the powers of tau phase of the ceremony, independent of
+// the circuit: the parameters for the circuits of up to N = 2^power
+// constraints, after a sequence of contributions of secrets τ, α and
+// β. Hash is the transcript hash of the last contribution, the
+// challenge of the next one.
+type Phase1 struct {
+	Parameters struct {
+		G1 struct {
+			Tau      []curve.G1Affine // [τ^i]₁, i < 2N
+			AlphaTau []curve.G1Affine // [α·τ^i]₁, i < N
+			BetaTau  []curve.G1Affine // [β·τ^i]₁, i < N
+		}
+		G2 struct {
+			Tau  []curve.G2Affine // [τ^i]₂, i < N
+			Beta curve.G2Affine   // [β]₂
+		}
+	}
+	PublicKeys struct {
+		Tau, Alpha, Beta PublicKey
+	}
+	Hash []byte
+}
+
+// InitPhase1 returns the initial parameters, with τ = α = β = 1, for
+// circuits of up to 2^power constraints.
+func InitPhase1(power int) (*Phase1, error) {
+	if power < 1 || power > 28 {
+		return nil, fmt.Errorf("invalid power %d", power)
+	}
+	n := 1 << power
+	_, _, g1, g2 := curve.Generators()
+
+	var p Phase1
+	p.Parameters.G1.Tau = make([]curve.G1Affine, 2*n)
+	p.Parameters.G1.AlphaTau = make([]curve.G1Affine, n)
+	p.Parameters.G1.BetaTau = make([]curve.G1Affine, n)
+	p.Parameters.G2.Tau = make([]curve.G2Affine, n)
+	for i := range p.Parameters.G1.Tau {
+		p.Parameters.G1.Tau[i] = g1
+	}
+	for i := 0; i < n; i++ {
+		p.Parameters.G1.AlphaTau[i] = g1
+		p.Parameters.G1.BetaTau[i] = g1
+		p.Parameters.G2.Tau[i] = g2
+	}
+	p.Parameters.G2.Beta = g2
+
+	var err error
+	p.Hash, err = transcriptHash(p.encode)
+
+	return &p, err
+}
+
+// Size is the maximal number of constraints of the circuits.
+func (p *Phase1) Size() int {
+	return len(p.Parameters.G2.Tau)
+}
+
+// Contribute samples secrets τ, α and β, updates the parameters with
+// them and forgets them.
+func (p *Phase1) Contribute() error {
+	tau, err := randomSecret()
+	if err != nil {
+		return err
+	}
+	alpha, err := randomSecret()
+	if err != nil {
+		return err
+	}
+	beta, err := randomSecret()
+	if err != nil {
+		return err
+	}
+
+	if p.PublicKeys.Tau, err = newPublicKey(tau, p.Hash, dstTau); err != nil {
+		return err
+	}
+	if p.PublicKeys.Alpha, err = newPublicKey(alpha, p.Hash, dstAlpha); err != nil {
+		return err
+	}
+	if p.PublicKeys.Beta, err = newPublicKey(beta, p.Hash, dstBeta); err != nil {
+		return err
+	}
+
+	one := fr.One()
+	scaleG1(p.Parameters.G1.Tau, tau, one)
+	scaleG1(p.Parameters.G1.AlphaTau, tau, alpha)
+	scaleG1(p.Parameters.G1.BetaTau, tau, beta)
+	scaleG2(p.Parameters.G2.Tau, tau, one)
+	p.Parameters.G2.Beta.ScalarMultiplication(&p.Parameters.G2.Beta, toBig(&beta))
+
+	p.Hash, err = transcriptHash(p.encode)
+
+	return err
+}
+
+// VerifyPhase1 checks that next is a valid contribution to prev: the
+// proofs of knowledge of its secrets and the consistency of the
+// updated parameters.
+func VerifyPhase1(prev, next *Phase1) error {
+	n := prev.Size()
+	if n < 2 || next.Size() != n || len(next.Parameters.G1.Tau) != 2*n ||
+		len(next.Parameters.G1.AlphaTau) != n || len(next.Parameters.G1.BetaTau) != n {
+		return fmt.Errorf("parameters of another size")
+	}
+	hash, err := transcriptHash(next.encode)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(hash, next.Hash) {
+		return fmt.Errorf("invalid transcript hash")
+	}
+
+	rTau, err := next.PublicKeys.Tau.verify(prev.Hash, dstTau)
+	if err != nil {
+		return fmt.Errorf("τ: %w", err)
+	}
+	rAlpha, err := next.PublicKeys.Alpha.verify(prev.Hash, dstAlpha)
+	if err != nil {
+		return fmt.Errorf("α: %w", err)
+	}
+	rBeta, err := next.PublicKeys.Beta.verify(prev.Hash, dstBeta)
+	if err != nil {
+		return fmt.Errorf("β: %w", err)
+	}
+
+	pp, np := &prev.Parameters, &next.Parameters
+	_, _, g1, g2 := curve.Generators()
+	if !np.G1.Tau[0].Equal(&g1) || !np.G2.Tau[0].Equal(&g2) {
+		return fmt.Errorf("parameters not based on the generators")
+	}
+
+	// the secrets of the proofs of knowledge are those of the update
+	if !sameRatio(pp.G1.Tau[1], np.G1.Tau[1], rTau, next.PublicKeys.Tau.XR) {
+		return fmt.Errorf("τ not updated with the contributed secret")
+	}
+	if !sameRatio(pp.G1.AlphaTau[0], np.G1.AlphaTau[0], rAlpha, next.PublicKeys.Alpha.XR) {
+		return fmt.Errorf("α not updated with the contributed secret")
+	}
+	if !sameRatio(pp.G1.BetaTau[0], np.G1.BetaTau[0], rBeta, next.PublicKeys.Beta.XR) {
+		return fmt.Errorf("β not updated with the contributed secret")
+	}
+	if !sameRatio(next.PublicKeys.Beta.SG, next.PublicKeys.Beta.SXG, pp.G2.Beta, np.G2.Beta) {
+		return fmt.Errorf("[β]₂ not updated with the contributed secret")
+	}
+
+	// the parameters are powers of the same τ, with the same α and β
+	if !sameRatio(g1, np.G1.Tau[1], g2, np.G2.Tau[1]) {
+		return fmt.Errorf("[τ]₁ and [τ]₂ differ")
+	}
+	if !sameRatio(g1, np.G1.BetaTau[0], g2, np.G2.Beta) {
+		return fmt.Errorf("[β]₁ and [β]₂ differ")
+	}
+	for name, points := range map[string][]curve.G1Affine{
+		"τ": np.G1.Tau, "α·τ": np.G1.AlphaTau, "β·τ": np.G1.BetaTau} {
+		l1, l2, err := linearCombinationG1(points)
+		if err != nil {
+			return err
+		}
+		if !sameRatio(l1, l2, g2, np.G2.Tau[1]) {
+			return fmt.Errorf("[%s^i]₁ are not successive powers", name)
+		}
+	}
+	l1, l2, err := linearCombinationG2(np.G2.Tau)
+	if err != nil {
+		return err
+	}
+	if !sameRatio(g1, np.G1.Tau[1], l1, l2) {
+		return fmt.Errorf("[τ^i]₂ are not successive powers")
+	}
+
+	return nil
+}
+
+// VerifyPhase1Transcript checks that the contributions start from
+// InitPhase1, compared in its full encoding, and each contribution is
+// valid.
+func VerifyPhase1Transcript(contributions []*Phase1) error {
+	if len(contributions) < 2 {
+		return fmt.Errorf("no contribution")
+	}
+	n := contributions[0].Size()
+	power := 0
+	for 1<<power < n {
+		power++
+	}
+	init, err := InitPhase1(power)
+	if err != nil {
+		return err
+	}
+	var initBytes, firstBytes bytes.Buffer
+	if _, err := init.WriteTo(&initBytes); err != nil {
+		return err
+	}
+	if _, err := contributions[0].WriteTo(&firstBytes); err != nil {
+		return err
+	}
+	if !bytes.Equal(initBytes.Bytes(), firstBytes.Bytes()) {
+		return fmt.Errorf("transcript does not start from the initial parameters")
+	}
+	for i := 1; i < len(contributions); i++ {
+		if err := VerifyPhase1(contributions[i-1], contributions[i]); err != nil {
+			return fmt.Errorf("contribution %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+func (p *Phase1) encode(enc *curve.Encoder) error {
+	toEncode := []interface{}{
+		p.Parameters.G1.Tau,
+		p.Parameters.G1.AlphaTau,
+		p.Parameters.G1.BetaTau,
+		p.Parameters.G2.Tau,
+		&p.Parameters.G2.Beta,
+	}
+	for _, pk := range []*PublicKey{&p.PublicKeys.Tau, &p.PublicKeys.Alpha, &p.PublicKeys.Beta} {
+		toEncode = append(toEncode, &pk.SG, &pk.SXG, &pk.XR)
+	}
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteTo writes the parameters, the public keys and the transcript
+// hash, points compressed.
+func (p *Phase1) WriteTo(w io.Writer) (int64, error) {
+	enc := curve.NewEncoder(w)
+	if err := p.encode(enc); err != nil {
+		return enc.BytesWritten(), err
+	}
+	n, err := w.Write(p.Hash)
+
+	return enc.BytesWritten() + int64(n), err
+}
+
+// ReadFrom reads parameters written by WriteTo, checking that the
+// points are in the subgroups.
+func (p *Phase1) ReadFrom(r io.Reader) (int64, error) {
+	dec := curve.NewDecoder(r)
+	toDecode := []interface{}{
+		&p.Parameters.G1.Tau,
+		&p.Parameters.G1.AlphaTau,
+		&p.Parameters.G1.BetaTau,
+		&p.Parameters.G2.Tau,
+		&p.Parameters.G2.Beta,
+	}
+	for _, pk := range []*PublicKey{&p.PublicKeys.Tau, &p.PublicKeys.Alpha, &p.PublicKeys.Beta} {
+		toDecode = append(toDecode, &pk.SG, &pk.SXG, &pk.XR)
+	}
+	for _, v := range toDecode {
+		if err := dec.Decode(v); err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+	p.Hash = make([]byte, hashSize)
+	n, err := io.ReadFull(r, p.Hash)
+
+	return dec.BytesRead() + int64(n), err
+}
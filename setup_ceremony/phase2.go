@@ -0,0 +1,443 @@
+package setup_ceremony
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/fft"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/pedersen"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/constraint"
+)
+
+// Phase2 is the phase of the ceremony specific to a circuit: the
+// parameters depending on δ, after a sequence of contributions of
+// secrets δ. Hash is the transcript hash of the last contribution.
+type Phase2 struct {
+	Parameters struct {
+		G1 struct {
+			Delta curve.G1Affine   // [δ]₁
+			L     []curve.G1Affine // [(β·Aᵢ(τ)+α·Bᵢ(τ)+Cᵢ(τ))/δ]₁ of the private wires
+			Z     []curve.G1Affine // [τ^i·(τ^N-1)/δ]₁, i < N, bit reversed
+		}
+		G2 struct {
+			Delta curve.G2Affine // [δ]₂
+		}
+	}
+	PublicKey PublicKey
+	Hash      []byte
+}
+
+// Phase2Evaluations are the parts of the keys computed from the phase
+// 1 parameters and the circuit, which the contributions of the phase 2
+// leave unchanged. γ is 1.
+type Phase2Evaluations struct {
+	G1 struct {
+		Alpha, Beta curve.G1Affine
+		A, B        []curve.G1Affine // [Aᵢ(τ)]₁ and [Bᵢ(τ)]₁ of all the wires
+		VKK         []curve.G1Affine // [β·Aᵢ(τ)+α·Bᵢ(τ)+Cᵢ(τ)]₁ of the public wires
+	}
+	G2 struct {
+		Beta curve.G2Affine
+		B    []curve.G2Affine // [Bᵢ(τ)]₂ of all the wires
+	}
+}
+
+// InitPhase2 evaluates the constraint system compiled for Groth16 over
+// BN254 at the phase 1 parameters and returns the initial phase 2
+// parameters, with δ = 1. The phase 1 parameters are not verified, see
+// InitVerifiedPhase2. Circuits with gnark commitments, such as
+// CircuitDatasetECDSA, are refused: the Pedersen key of the commitment
+// has a secret of its own, which the ceremony does not share.
+func InitPhase2(ccs constraint.ConstraintSystem, p1 *Phase1) (*Phase2, *Phase2Evaluations, error) {
+	cs, err := decodeR1CS(ccs)
+	if err != nil {
+		return nil, nil, err
+	}
+	domain := fft.NewDomain(uint64(len(cs.Constraints)))
+	n := int(domain.Cardinality)
+	if n > p1.Size() {
+		return nil, nil, fmt.Errorf("circuit of %d constraints larger than the phase 1 parameters", len(cs.Constraints))
+	}
+	pp := &p1.Parameters
+
+	// the Lagrange polynomials of the domain at τ
+	tau := lagrangeG1(pp.G1.Tau[:n], domain)
+	alphaTau := lagrangeG1(pp.G1.AlphaTau[:n], domain)
+	betaTau := lagrangeG1(pp.G1.BetaTau[:n], domain)
+	tau2 := lagrangeG2(pp.G2.Tau[:n], domain)
+
+	nbWires := cs.nbWires()
+	a := make([]curve.G1Jac, nbWires)
+	b := make([]curve.G1Jac, nbWires)
+	k := make([]curve.G1Jac, nbWires)
+	b2 := make([]curve.G2Jac, nbWires)
+	for i, c := range cs.Constraints {
+		for _, t := range c.L {
+			cs.accumulateG1(&a[t.WireID()], t, &tau[i])
+			cs.accumulateG1(&k[t.WireID()], t, &betaTau[i])
+		}
+		for _, t := range c.R {
+			cs.accumulateG1(&b[t.WireID()], t, &tau[i])
+			cs.accumulateG2(&b2[t.WireID()], t, &tau2[i])
+			cs.accumulateG1(&k[t.WireID()], t, &alphaTau[i])
+		}
+		for _, t := range c.O {
+			cs.accumulateG1(&k[t.WireID()], t, &tau[i])
+		}
+	}
+
+	var evals Phase2Evaluations
+	evals.G1.Alpha = pp.G1.AlphaTau[0]
+	evals.G1.Beta = pp.G1.BetaTau[0]
+	evals.G2.Beta = pp.G2.Beta
+	evals.G1.A = curve.BatchJacobianToAffineG1(a)
+	evals.G1.B = curve.BatchJacobianToAffineG1(b)
+	evals.G2.B = make([]curve.G2Affine, nbWires)
+	for i := range b2 {
+		evals.G2.B[i].FromJacobian(&b2[i])
+	}
+	kAff := curve.BatchJacobianToAffineG1(k)
+	evals.G1.VKK = kAff[:cs.GetNbPublicVariables()]
+
+	_, _, g1, g2 := curve.Generators()
+	var p2 Phase2
+	p2.Parameters.G1.Delta = g1
+	p2.Parameters.G2.Delta = g2
+	p2.Parameters.G1.L = kAff[cs.GetNbPublicVariables():]
+	p2.Parameters.G1.Z = make([]curve.G1Affine, n)
+	for i := range p2.Parameters.G1.Z {
+		p2.Parameters.G1.Z[i].Sub(&pp.G1.Tau[i+n], &pp.G1.Tau[i])
+	}
+	bitReverse(p2.Parameters.G1.Z)
+
+	p2.Hash, err = transcriptHash(p2.encode)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &p2, &evals, nil
+}
+
+// accumulateG1 adds the term times p to res.
+func (cs *constraintSystem) accumulateG1(res *curve.G1Jac, t constraint.Term, p *curve.G1Affine) {
+	switch t.CoeffID() {
+	case constraint.CoeffIdZero:
+	case constraint.CoeffIdOne:
+		res.AddMixed(p)
+	case constraint.CoeffIdTwo:
+		res.AddMixed(p).AddMixed(p)
+	case constraint.CoeffIdMinusOne:
+		var neg curve.G1Affine
+		res.AddMixed(neg.Neg(p))
+	default:
+		var tmp curve.G1Jac
+		tmp.FromAffine(p)
+		tmp.ScalarMultiplication(&tmp, toBig(&cs.Coefficients[t.CoeffID()]))
+		res.AddAssign(&tmp)
+	}
+}
+
+// accumulateG2 adds the term times p to res.
+func (cs *constraintSystem) accumulateG2(res *curve.G2Jac, t constraint.Term, p *curve.G2Affine) {
+	switch t.CoeffID() {
+	case constraint.CoeffIdZero:
+	case constraint.CoeffIdOne:
+		res.AddMixed(p)
+	case constraint.CoeffIdTwo:
+		res.AddMixed(p).AddMixed(p)
+	case constraint.CoeffIdMinusOne:
+		var neg curve.G2Affine
+		res.AddMixed(neg.Neg(p))
+	default:
+		var tmp curve.G2Jac
+		tmp.FromAffine(p)
+		tmp.ScalarMultiplication(&tmp, toBig(&cs.Coefficients[t.CoeffID()]))
+		res.AddAssign(&tmp)
+	}
+}
+
+// Contribute samples a secret δ, updates the parameters with it and
+// forgets it.
+func (p *Phase2) Contribute() error {
+	delta, err := randomSecret()
+	if err != nil {
+		return err
+	}
+	if p.PublicKey, err = newPublicKey(delta, p.Hash, dstDelta); err != nil {
+		return err
+	}
+
+	var deltaInv fr.Element
+	deltaInv.Inverse(&delta)
+	p.Parameters.G1.Delta.ScalarMultiplication(&p.Parameters.G1.Delta, toBig(&delta))
+	p.Parameters.G2.Delta.ScalarMultiplication(&p.Parameters.G2.Delta, toBig(&delta))
+	one := fr.One()
+	scaleG1(p.Parameters.G1.L, one, deltaInv)
+	scaleG1(p.Parameters.G1.Z, one, deltaInv)
+
+	p.Hash, err = transcriptHash(p.encode)
+
+	return err
+}
+
+// VerifyPhase2 checks that next is a valid contribution to prev: the
+// proof of knowledge of its secret δ, and that [δ]₁ and [δ]₂ were
+// multiplied by δ while the other parameters were divided by δ.
+func VerifyPhase2(prev, next *Phase2) error {
+	pp, np := &prev.Parameters, &next.Parameters
+	if len(np.G1.L) != len(pp.G1.L) || len(np.G1.Z) != len(pp.G1.Z) {
+		return fmt.Errorf("parameters of another size")
+	}
+	hash, err := transcriptHash(next.encode)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(hash, next.Hash) {
+		return fmt.Errorf("invalid transcript hash")
+	}
+
+	r, err := next.PublicKey.verify(prev.Hash, dstDelta)
+	if err != nil {
+		return fmt.Errorf("δ: %w", err)
+	}
+	if !sameRatio(pp.G1.Delta, np.G1.Delta, r, next.PublicKey.XR) {
+		return fmt.Errorf("[δ]₁ not updated with the contributed secret")
+	}
+	if !sameRatio(next.PublicKey.SG, next.PublicKey.SXG, pp.G2.Delta, np.G2.Delta) {
+		return fmt.Errorf("[δ]₂ not updated with the contributed secret")
+	}
+
+	nextLZ := append(append([]curve.G1Affine{}, np.G1.L...), np.G1.Z...)
+	prevLZ := append(append([]curve.G1Affine{}, pp.G1.L...), pp.G1.Z...)
+	l1, l2, err := mergedCombination(nextLZ, prevLZ)
+	if err != nil {
+		return err
+	}
+	if !sameRatio(l1, l2, pp.G2.Delta, np.G2.Delta) {
+		return fmt.Errorf("L and Z not divided by the contributed secret")
+	}
+
+	return nil
+}
+
+// VerifyPhase2Transcript checks that the contributions start from
+// the initial parameters, compared in their full encoding, and each
+// contribution is valid.
+func VerifyPhase2Transcript(init *Phase2, contributions []*Phase2) error {
+	if len(contributions) < 2 {
+		return fmt.Errorf("no contribution")
+	}
+	var initBytes, firstBytes bytes.Buffer
+	if _, err := init.WriteTo(&initBytes); err != nil {
+		return err
+	}
+	if _, err := contributions[0].WriteTo(&firstBytes); err != nil {
+		return err
+	}
+	if !bytes.Equal(initBytes.Bytes(), firstBytes.Bytes()) {
+		return fmt.Errorf("transcript does not start from the initial parameters")
+	}
+	for i := 1; i < len(contributions); i++ {
+		if err := VerifyPhase2(contributions[i-1], contributions[i]); err != nil {
+			return fmt.Errorf("contribution %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// InitVerifiedPhase2 verifies the transcript of the phase 1 and
+// returns InitPhase2 of its last contribution.
+func InitVerifiedPhase2(ccs constraint.ConstraintSystem, phase1 []*Phase1) (*Phase2, *Phase2Evaluations, error) {
+	if err := VerifyPhase1Transcript(phase1); err != nil {
+		return nil, nil, fmt.Errorf("phase 1: %w", err)
+	}
+
+	return InitPhase2(ccs, phase1[len(phase1)-1])
+}
+
+// ExtractVerifiedKeys returns the Groth16 keys of the circuit from the
+// last contribution of the transcript, once verified. The transcript
+// of the phase 1 is verified, and the initial parameters and the
+// evaluations are computed again from its last contribution and the
+// circuit, not taken from the coordinator.
+func ExtractVerifiedKeys(ccs constraint.ConstraintSystem, phase1 []*Phase1, contributions []*Phase2) (
+	groth16.ProvingKey, groth16.VerifyingKey, error) {
+	init, evals, err := InitVerifiedPhase2(ccs, phase1)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := VerifyPhase2Transcript(init, contributions); err != nil {
+		return nil, nil, err
+	}
+
+	return ExtractKeys(contributions[len(contributions)-1], evals)
+}
+
+// ExtractKeys returns the Groth16 keys of the circuit from the final
+// phase 2 parameters and the evaluations of InitPhase2.
+func ExtractKeys(p2 *Phase2, evals *Phase2Evaluations) (groth16.ProvingKey, groth16.VerifyingKey, error) {
+	nbWires := len(evals.G1.A)
+	if len(evals.G1.B) != nbWires || len(evals.G2.B) != nbWires ||
+		len(evals.G1.VKK)+len(p2.Parameters.G1.L) != nbWires {
+		return nil, nil, fmt.Errorf("parameters of another circuit")
+	}
+
+	// the points at infinity of A and B are left out of the proving key
+	infinityA := make([]bool, nbWires)
+	infinityB := make([]bool, nbWires)
+	var a, b []curve.G1Affine
+	var b2 []curve.G2Affine
+	for i := 0; i < nbWires; i++ {
+		if infinityA[i] = evals.G1.A[i].IsInfinity(); !infinityA[i] {
+			a = append(a, evals.G1.A[i])
+		}
+		if infinityB[i] = evals.G1.B[i].IsInfinity(); !infinityB[i] {
+			b = append(b, evals.G1.B[i])
+			b2 = append(b2, evals.G2.B[i])
+		}
+	}
+
+	n := len(p2.Parameters.G1.Z)
+	var pk groth16_bn254.ProvingKey
+	pk.Domain = *fft.NewDomain(uint64(n))
+	pk.G1.Alpha = evals.G1.Alpha
+	pk.G1.Beta = evals.G1.Beta
+	pk.G1.Delta = p2.Parameters.G1.Delta
+	pk.G1.A = a
+	pk.G1.B = b
+	// gnark keeps the first N-1 points of Z, bit reversed
+	pk.G1.Z = p2.Parameters.G1.Z[:n-1]
+	pk.G1.K = p2.Parameters.G1.L
+	pk.G2.Beta = evals.G2.Beta
+	pk.G2.Delta = p2.Parameters.G2.Delta
+	pk.G2.B = b2
+	pk.InfinityA = infinityA
+	pk.InfinityB = infinityB
+	pk.NbInfinityA = uint64(nbWires - len(a))
+	pk.NbInfinityB = uint64(nbWires - len(b))
+
+	var vk groth16_bn254.VerifyingKey
+	var err error
+	// no commitment, as in gnark's setup of such circuits
+	pk.CommitmentKeys, vk.CommitmentKey, err = pedersen.Setup()
+	if err != nil {
+		return nil, nil, err
+	}
+	_, _, _, g2 := curve.Generators()
+	vk.G1.Alpha = evals.G1.Alpha
+	vk.G1.Beta = evals.G1.Beta
+	vk.G1.Delta = p2.Parameters.G1.Delta
+	vk.G1.K = evals.G1.VKK
+	vk.G2.Beta = evals.G2.Beta
+	vk.G2.Delta = p2.Parameters.G2.Delta
+	vk.G2.Gamma = g2
+	vk.PublicAndCommitmentCommitted = [][]int{}
+	if err := vk.Precompute(); err != nil {
+		return nil, nil, err
+	}
+
+	return &pk, &vk, nil
+}
+
+func (p *Phase2) encode(enc *curve.Encoder) error {
+	toEncode := []interface{}{
+		&p.Parameters.G1.Delta,
+		p.Parameters.G1.L,
+		p.Parameters.G1.Z,
+		&p.Parameters.G2.Delta,
+		&p.PublicKey.SG,
+		&p.PublicKey.SXG,
+		&p.PublicKey.XR,
+	}
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteTo writes the parameters, the public key and the transcript
+// hash, points compressed.
+func (p *Phase2) WriteTo(w io.Writer) (int64, error) {
+	enc := curve.NewEncoder(w)
+	if err := p.encode(enc); err != nil {
+		return enc.BytesWritten(), err
+	}
+	n, err := w.Write(p.Hash)
+
+	return enc.BytesWritten() + int64(n), err
+}
+
+// ReadFrom reads parameters written by WriteTo, checking that the
+// points are in the subgroups.
+func (p *Phase2) ReadFrom(r io.Reader) (int64, error) {
+	dec := curve.NewDecoder(r)
+	toDecode := []interface{}{
+		&p.Parameters.G1.Delta,
+		&p.Parameters.G1.L,
+		&p.Parameters.G1.Z,
+		&p.Parameters.G2.Delta,
+		&p.PublicKey.SG,
+		&p.PublicKey.SXG,
+		&p.PublicKey.XR,
+	}
+	for _, v := range toDecode {
+		if err := dec.Decode(v); err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+	p.Hash = make([]byte, hashSize)
+	n, err := io.ReadFull(r, p.Hash)
+
+	return dec.BytesRead() + int64(n), err
+}
+
+// WriteTo writes the evaluations, points compressed.
+func (e *Phase2Evaluations) WriteTo(w io.Writer) (int64, error) {
+	enc := curve.NewEncoder(w)
+	toEncode := []interface{}{
+		&e.G1.Alpha,
+		&e.G1.Beta,
+		e.G1.A,
+		e.G1.B,
+		e.G1.VKK,
+		&e.G2.Beta,
+		e.G2.B,
+	}
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return enc.BytesWritten(), err
+		}
+	}
+
+	return enc.BytesWritten(), nil
+}
+
+// ReadFrom reads evaluations written by WriteTo.
+func (e *Phase2Evaluations) ReadFrom(r io.Reader) (int64, error) {
+	dec := curve.NewDecoder(r)
+	toDecode := []interface{}{
+		&e.G1.Alpha,
+		&e.G1.Beta,
+		&e.G1.A,
+		&e.G1.B,
+		&e.G1.VKK,
+		&e.G2.Beta,
+		&e.G2.B,
+	}
+	for _, v := range toDecode {
+		if err := dec.Decode(v); err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+
+	return dec.BytesRead(), nil
+}
@@ -0,0 +1,46 @@
+package setup_ceremony
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/constraint"
+	cs_bn254 "github.com/consensys/gnark/constraint/bn254"
+)
+
+// constraintSystem is the part of gnark's R1CS over BN254 the setup depends on.
+// Wires are numbered public first, then secret, then internal, the
+// first public wire being the constant 1.
+type constraintSystem struct {
+	*cs_bn254.R1CS
+	Constraints []constraint.R1C
+}
+
+func (cs *constraintSystem) nbWires() int {
+	return cs.NbInternalVariables + cs.GetNbPublicVariables() + cs.GetNbSecretVariables()
+}
+
+// decodeR1CS checks the constraint system is compiled for Groth16 over
+// BN254, without commitments, see InitPhase2.
+func decodeR1CS(ccs constraint.ConstraintSystem) (*constraintSystem, error) {
+	bn254cs, ok := ccs.(*cs_bn254.R1CS)
+	if !ok {
+		return nil, fmt.Errorf("constraint system not an R1CS over BN254")
+	}
+	if commitments, ok := bn254cs.CommitmentInfo.(constraint.Groth16Commitments); !ok || len(commitments) != 0 {
+		return nil, fmt.Errorf("constraint system with commitments")
+	}
+	cs := constraintSystem{R1CS: bn254cs, Constraints: bn254cs.GetR1Cs()}
+
+	nbWires := cs.nbWires()
+	for _, c := range cs.Constraints {
+		for _, l := range []constraint.LinearExpression{c.L, c.R, c.O} {
+			for _, t := range l {
+				if t.WireID() >= nbWires || t.CoeffID() >= len(cs.Coefficients) {
+					return nil, fmt.Errorf("invalid term in constraint system")
+				}
+			}
+		}
+	}
+
+	return &cs, nil
+}
@@ -0,0 +1,298 @@
+package setup_ceremony
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"math/bits"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/fft"
+)
+
+// PublicKey proves the knowledge of the secret x of a contribution: for
+// a random s, SG = [s]₁, SXG = [s·x]₁ and XR = [x]R, R being hashed
+// from SG, SXG and the transcript of the previous contributions.
+type PublicKey struct {
+	SG  curve.G1Affine
+	SXG curve.G1Affine
+	XR  curve.G2Affine
+}
+
+// domain separation tags of the proofs of knowledge of the secrets
+const (
+	dstTau   = "KRAKEN-ZKPComponent-V01-CEREMONY-TAU"
+	dstAlpha = "KRAKEN-ZKPComponent-V01-CEREMONY-ALPHA"
+	dstBeta  = "KRAKEN-ZKPComponent-V01-CEREMONY-BETA"
+	dstDelta = "KRAKEN-ZKPComponent-V01-CEREMONY-DELTA"
+)
+
+// hashSize is the size of the transcript hashes
+const hashSize = sha256.Size
+
+// randomSecret returns a random non zero field element.
+func randomSecret() (fr.Element, error) {
+	var x fr.Element
+	for x.IsZero() {
+		if _, err := x.SetRandom(); err != nil {
+			return x, err
+		}
+	}
+
+	return x, nil
+}
+
+func toBig(x *fr.Element) *big.Int {
+	var b big.Int
+	return x.BigInt(&b)
+}
+
+// genR hashes the proof of knowledge and the challenge to G2.
+func genR(sG1, sxG1 *curve.G1Affine, challenge []byte, dst string) (curve.G2Affine, error) {
+	var buf bytes.Buffer
+	sBytes := sG1.Bytes()
+	sxBytes := sxG1.Bytes()
+	buf.Write(sBytes[:])
+	buf.Write(sxBytes[:])
+	buf.Write(challenge)
+
+	return curve.HashToG2(buf.Bytes(), []byte(dst))
+}
+
+func newPublicKey(x fr.Element, challenge []byte, dst string) (PublicKey, error) {
+	var pk PublicKey
+	_, _, g1, _ := curve.Generators()
+
+	s, err := randomSecret()
+	if err != nil {
+		return pk, err
+	}
+	var sx fr.Element
+	sx.Mul(&s, &x)
+	pk.SG.ScalarMultiplication(&g1, toBig(&s))
+	pk.SXG.ScalarMultiplication(&g1, toBig(&sx))
+
+	r, err := genR(&pk.SG, &pk.SXG, challenge, dst)
+	if err != nil {
+		return pk, err
+	}
+	pk.XR.ScalarMultiplication(&r, toBig(&x))
+
+	return pk, nil
+}
+
+// verify checks the proof of knowledge and returns R.
+func (pk *PublicKey) verify(challenge []byte, dst string) (curve.G2Affine, error) {
+	r, err := genR(&pk.SG, &pk.SXG, challenge, dst)
+	if err != nil {
+		return r, err
+	}
+	if !sameRatio(pk.SG, pk.SXG, r, pk.XR) {
+		return r, fmt.Errorf("invalid proof of knowledge")
+	}
+
+	return r, nil
+}
+
+// sameRatio checks that b1 = [x]a1 and b2 = [x]a2 for the same x, with
+// e(a1, b2) = e(b1, a2). Points at infinity are refused.
+func sameRatio(a1, b1 curve.G1Affine, a2, b2 curve.G2Affine) bool {
+	if a1.IsInfinity() || b1.IsInfinity() || a2.IsInfinity() || b2.IsInfinity() {
+		return false
+	}
+	var nb1 curve.G1Affine
+	nb1.Neg(&b1)
+	res, err := curve.PairingCheck([]curve.G1Affine{a1, nb1}, []curve.G2Affine{b2, a2})
+
+	return err == nil && res
+}
+
+func randomScalars(n int) ([]fr.Element, error) {
+	r := make([]fr.Element, n)
+	for i := range r {
+		if _, err := r[i].SetRandom(); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// linearCombinationG1 returns Σ rᵢ·Aᵢ and Σ rᵢ·Aᵢ₊₁ for random rᵢ: the
+// second is [x] of the first if Aᵢ₊₁ = [x]Aᵢ for all i.
+func linearCombinationG1(a []curve.G1Affine) (l1, l2 curve.G1Affine, err error) {
+	r, err := randomScalars(len(a) - 1)
+	if err != nil {
+		return
+	}
+	cfg := ecc.MultiExpConfig{}
+	if _, err = l1.MultiExp(a[:len(a)-1], r, cfg); err != nil {
+		return
+	}
+	_, err = l2.MultiExp(a[1:], r, cfg)
+
+	return
+}
+
+// linearCombinationG2 is linearCombinationG1 in G2.
+func linearCombinationG2(a []curve.G2Affine) (l1, l2 curve.G2Affine, err error) {
+	r, err := randomScalars(len(a) - 1)
+	if err != nil {
+		return
+	}
+	cfg := ecc.MultiExpConfig{}
+	if _, err = l1.MultiExp(a[:len(a)-1], r, cfg); err != nil {
+		return
+	}
+	_, err = l2.MultiExp(a[1:], r, cfg)
+
+	return
+}
+
+// mergedCombination is a random linear combination of the pairs
+// (Aᵢ, Bᵢ), to check with one pairing that Bᵢ = [x]Aᵢ for all i.
+func mergedCombination(a, b []curve.G1Affine) (la, lb curve.G1Affine, err error) {
+	r, err := randomScalars(len(a))
+	if err != nil {
+		return
+	}
+	cfg := ecc.MultiExpConfig{}
+	if _, err = la.MultiExp(a, r, cfg); err != nil {
+		return
+	}
+	_, err = lb.MultiExp(b, r, cfg)
+
+	return
+}
+
+// scaleG1 sets aᵢ to [x^i·c]aᵢ.
+func scaleG1(a []curve.G1Affine, x, c fr.Element) {
+	jac := make([]curve.G1Jac, len(a))
+	for i := range a {
+		jac[i].FromAffine(&a[i])
+		jac[i].ScalarMultiplication(&jac[i], toBig(&c))
+		c.Mul(&c, &x)
+	}
+	copy(a, curve.BatchJacobianToAffineG1(jac))
+}
+
+// scaleG2 sets aᵢ to [x^i·c]aᵢ.
+func scaleG2(a []curve.G2Affine, x, c fr.Element) {
+	for i := range a {
+		a[i].ScalarMultiplication(&a[i], toBig(&c))
+		c.Mul(&c, &x)
+	}
+}
+
+// bitReverse permutes the points as gnark's Groth16 setup does with
+// the Z part of the proving key.
+func bitReverse(a []curve.G1Affine) {
+	permuteBitReverse(len(a), func(i, j int) { a[i], a[j] = a[j], a[i] })
+}
+
+// twiddles returns the powers of the inverse generator of the domain,
+// to interpolate points over it.
+func twiddles(domain *fft.Domain) []big.Int {
+	w := fr.One()
+	res := make([]big.Int, domain.Cardinality/2)
+	for i := range res {
+		w.BigInt(&res[i])
+		w.Mul(&w, &domain.GeneratorInv)
+	}
+
+	return res
+}
+
+// lagrangeG1 interpolates the points [τ^i]P, i < n, to the points
+// [Lᵢ(τ)]P of the Lagrange polynomials of the domain of size n: an
+// inverse FFT in the group.
+func lagrangeG1(a []curve.G1Affine, domain *fft.Domain) []curve.G1Affine {
+	n := len(a)
+	w := twiddles(domain)
+	jac := make([]curve.G1Jac, n)
+	for i := range a {
+		jac[i].FromAffine(&a[i])
+	}
+	permuteBitReverse(n, func(i, j int) { jac[i], jac[j] = jac[j], jac[i] })
+
+	var t curve.G1Jac
+	for m := 2; m <= n; m <<= 1 {
+		half, step := m/2, n/m
+		for k := 0; k < n; k += m {
+			for j := 0; j < half; j++ {
+				t.Set(&jac[k+j+half])
+				if j != 0 {
+					t.ScalarMultiplication(&t, &w[j*step])
+				}
+				jac[k+j+half].Set(&jac[k+j])
+				jac[k+j].AddAssign(&t)
+				jac[k+j+half].SubAssign(&t)
+			}
+		}
+	}
+	nInv := toBig(&domain.CardinalityInv)
+	for i := range jac {
+		jac[i].ScalarMultiplication(&jac[i], nInv)
+	}
+
+	return curve.BatchJacobianToAffineG1(jac)
+}
+
+// lagrangeG2 is lagrangeG1 in G2.
+func lagrangeG2(a []curve.G2Affine, domain *fft.Domain) []curve.G2Affine {
+	n := len(a)
+	w := twiddles(domain)
+	jac := make([]curve.G2Jac, n)
+	for i := range a {
+		jac[i].FromAffine(&a[i])
+	}
+	permuteBitReverse(n, func(i, j int) { jac[i], jac[j] = jac[j], jac[i] })
+
+	var t curve.G2Jac
+	for m := 2; m <= n; m <<= 1 {
+		half, step := m/2, n/m
+		for k := 0; k < n; k += m {
+			for j := 0; j < half; j++ {
+				t.Set(&jac[k+j+half])
+				if j != 0 {
+					t.ScalarMultiplication(&t, &w[j*step])
+				}
+				jac[k+j+half].Set(&jac[k+j])
+				jac[k+j].AddAssign(&t)
+				jac[k+j+half].SubAssign(&t)
+			}
+		}
+	}
+	nInv := toBig(&domain.CardinalityInv)
+	res := make([]curve.G2Affine, n)
+	for i := range jac {
+		jac[i].ScalarMultiplication(&jac[i], nInv)
+		res[i].FromJacobian(&jac[i])
+	}
+
+	return res
+}
+
+// permuteBitReverse swaps the indices i < n with their bit reversal.
+func permuteBitReverse(n int, swap func(i, j int)) {
+	nn := uint(bits.UintSize - bits.TrailingZeros(uint(n)))
+	for i := uint(0); i < uint(n); i++ {
+		irev := bits.Reverse(i) >> nn
+		if irev > i {
+			swap(int(i), int(irev))
+		}
+	}
+}
+
+// transcriptHash is the SHA-256 of the encoding of a contribution.
+func transcriptHash(encode func(enc *curve.Encoder) error) ([]byte, error) {
+	h := sha256.New()
+	if err := encode(curve.NewEncoder(h)); err != nil {
+		return nil, err
+	}
+
+	return h.Sum(nil), nil
+}
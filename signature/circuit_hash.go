@@ -0,0 +1,187 @@
+package signature
+
+import (
+	"fmt"
+	gohash "hash"
+	"math/big"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"golang.org/x/crypto/sha3"
+)
+
+// CircuitVersion identifies the hash function used inside the circuits
+// and, with the same choice, by the native signing path: the MiMC of
+// the signed text, the hash of the EdDSA signature and the commitments
+// of CommitSchemeMiMC.
+type CircuitVersion int
+
+const (
+	// CircuitV1 is the MiMC of gnark-crypto up to v0.6 (hash.MIMC_BN254
+	// with 91 rounds), whose round constants are derived from the fixed
+	// seed "seed". It is kept to verify the datasets signed before
+	// CircuitV2, there are no distributed keys for its circuits.
+	CircuitV1 CircuitVersion = 0
+	// CircuitV2 is MiMC with round constants derived from MiMCSeedV2,
+	// and the signed text encoded with hash_to_field, see ColumnsField.
+	CircuitV2 CircuitVersion = 1
+	// CurrentCircuitVersion is the version of the circuit of the
+	// distributed keys, proofKey.txt and verifyKey.txt.
+	CurrentCircuitVersion = CircuitV2
+)
+
+// MiMCSeedV2 is the domain separated seed of the MiMC round constants of
+// CircuitV2.
+const MiMCSeedV2 = "KRAKEN-ZKPComponent-V02-MiMC_BN254"
+
+// mimcSeedV1 is the seed of hash.MIMC_BN254
+const mimcSeedV1 = "seed"
+
+// mimcRounds is the number of rounds of MiMC over BN254, as in
+// gnark-crypto up to v0.6, which now uses 110 rounds
+const mimcRounds = 91
+
+var mimcConstants sync.Map // seed -> []fr.Element
+
+func (v CircuitVersion) check() error {
+	if v != CircuitV1 && v != CircuitV2 {
+		return fmt.Errorf("unknown circuit version %d", v)
+	}
+
+	return nil
+}
+
+// MiMCSeed returns the seed of the MiMC round constants of the version.
+func (v CircuitVersion) MiMCSeed() (string, error) {
+	switch v {
+	case CircuitV1:
+		return mimcSeedV1, nil
+	case CircuitV2:
+		return MiMCSeedV2, nil
+	}
+
+	return "", v.check()
+}
+
+// MiMCConstants returns the MiMC round constants of the version, for
+// the in-circuit hash.
+func (v CircuitVersion) MiMCConstants() ([]big.Int, error) {
+	seed, err := v.MiMCSeed()
+	if err != nil {
+		return nil, err
+	}
+
+	constants := mimcRoundConstants(seed)
+	res := make([]big.Int, len(constants))
+	for i := range constants {
+		constants[i].BigInt(&res[i])
+	}
+
+	return res, nil
+}
+
+// NewHash returns the native hash of the version.
+func (v CircuitVersion) NewHash() (gohash.Hash, error) {
+	switch v {
+	case CircuitV1:
+		return newMiMC(mimcSeedV1), nil
+	case CircuitV2:
+		return newMiMC(MiMCSeedV2), nil
+	}
+
+	return nil, v.check()
+}
+
+// mimcRoundConstants derives the round constants from the seed like
+// gnark-crypto: c_i = keccak256^(i+2)(seed).
+func mimcRoundConstants(seed string) []fr.Element {
+	if c, ok := mimcConstants.Load(seed); ok {
+		return c.([]fr.Element)
+	}
+
+	constants := make([]fr.Element, mimcRounds)
+	h := sha3.NewLegacyKeccak256()
+	h.Write([]byte(seed))
+	rnd := h.Sum(nil)
+	for i := range constants {
+		h.Reset()
+		h.Write(rnd)
+		rnd = h.Sum(nil)
+		constants[i].SetBytes(rnd)
+	}
+	c, _ := mimcConstants.LoadOrStore(seed, constants)
+
+	return c.([]fr.Element)
+}
+
+// mimcDigest is the MiMC hash of gnark-crypto v0.6 with the round
+// constants of the seed: Miyaguchi–Preneel over blocks of fr.Bytes bytes,
+// each reduced modulo the field.
+type mimcDigest struct {
+	constants []fr.Element
+	h         fr.Element
+	data      []byte
+}
+
+func newMiMC(seed string) *mimcDigest {
+	return &mimcDigest{constants: mimcRoundConstants(seed)}
+}
+
+func (d *mimcDigest) Reset() {
+	d.data = nil
+	d.h.SetZero()
+}
+
+func (d *mimcDigest) Size() int {
+	return fr.Bytes
+}
+
+func (d *mimcDigest) BlockSize() int {
+	return fr.Bytes
+}
+
+func (d *mimcDigest) Write(p []byte) (int, error) {
+	d.data = append(d.data, p...)
+
+	return len(p), nil
+}
+
+// Sum appends the hash of the data written so far to b and flushes the
+// data, like the MiMC of gnark-crypto.
+func (d *mimcDigest) Sum(b []byte) []byte {
+	// a partial last block is left padded with zeros
+	if r := len(d.data) % fr.Bytes; r != 0 {
+		q := len(d.data) - r
+		padded := make([]byte, q+fr.Bytes)
+		copy(padded, d.data[:q])
+		copy(padded[q+fr.Bytes-r:], d.data[q:])
+		d.data = padded
+	}
+	if len(d.data) == 0 {
+		d.data = make([]byte, fr.Bytes)
+	}
+
+	var x fr.Element
+	for i := 0; i < len(d.data); i += fr.Bytes {
+		x.SetBytes(d.data[i : i+fr.Bytes])
+		r := d.encrypt(x)
+		d.h.Add(&d.h, &r).Add(&d.h, &x)
+	}
+	d.data = nil
+
+	res := d.h.Bytes()
+
+	return append(b, res[:]...)
+}
+
+// encrypt is the MiMC permutation with exponent 5, keyed with the
+// current state.
+func (d *mimcDigest) encrypt(m fr.Element) fr.Element {
+	var tmp fr.Element
+	for i := range d.constants {
+		tmp.Add(&m, &d.h).Add(&tmp, &d.constants[i])
+		m.Square(&tmp).Square(&m).Mul(&m, &tmp)
+	}
+
+	return *m.Add(&m, &d.h)
+}
@@ -0,0 +1,76 @@
+package signature
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiMCSeed(t *testing.T) {
+	// with the seed of gnark-crypto it is hash.MIMC_BN254 of gnark-crypto
+	// v0.6.1, also for partial blocks
+	expected := map[int]string{
+		0:   "0dde7a022857fec1b8ffa7664a937a250d3ae68f356061754d3531e2674103d8",
+		1:   "051f81ae0ab58ceaac24eb32499c26bfd1bf96bd3d6055450c89248011a32483",
+		32:  "22a42936a648eaf8064ae6082b2cc677b51f7f8004935891d414e43019e9f284",
+		33:  "159da09e97dd44c93a71b9d306c5927a550869ba1a627b392d689a98b4fdd7ef",
+		100: "02c51656bade44d18dc47678fcab731810d47441ab810dccdf052b29b2414da9",
+	}
+	for n, e := range expected {
+		data := make([]byte, n)
+		for i := range data {
+			data[i] = byte(i*7 + 1)
+		}
+		h := newMiMC(mimcSeedV1)
+		h.Write(data)
+		assert.Equal(t, e, hex.EncodeToString(h.Sum(nil)), n)
+	}
+
+	h1, err := CircuitV1.NewHash()
+	assert.NoError(t, err)
+	h2, err := CircuitV2.NewHash()
+	assert.NoError(t, err)
+	assert.NotEqual(t, h1.Sum(nil), h2.Sum(nil))
+
+	c1, err := CircuitV1.MiMCConstants()
+	assert.NoError(t, err)
+	c2, err := CircuitV2.MiMCConstants()
+	assert.NoError(t, err)
+	assert.Len(t, c2, len(c1))
+	assert.NotEqual(t, c1[0], c2[0])
+
+	_, err = CircuitVersion(7).NewHash()
+	assert.Error(t, err)
+	_, err = CircuitVersion(7).MiMCConstants()
+	assert.Error(t, err)
+}
+
+func TestSignCsvCircuitV2(t *testing.T) {
+	signer, err := eddsa.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	pubKey := signer.Public()
+
+	opts := DefaultSignOptions
+	opts.Circuit = CircuitV2
+	sign, err := SignCsvWithOptions("../datasets/framingham_tiny.csv", signer, opts)
+	assert.NoError(t, err)
+	signed := filepath.Join(t.TempDir(), "signed.csv")
+	assert.NoError(t, WriteSignCsv("../datasets/framingham_tiny.csv", signed, sign))
+	check, err := VerifyCsv(signed, pubKey)
+	assert.NoError(t, err)
+	assert.True(t, check)
+
+	// the signature does not verify with the hash of another version
+	sign.CircuitVersion = CircuitV1
+	assert.NoError(t, WriteSignCsv("../datasets/framingham_tiny.csv", signed, sign))
+	check, _ = VerifyCsv(signed, pubKey)
+	assert.False(t, check)
+
+	opts.Circuit = CircuitVersion(7)
+	_, err = SignCsvWithOptions("../datasets/framingham_tiny.csv", signer, opts)
+	assert.Error(t, err)
+}
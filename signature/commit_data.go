@@ -1,10 +1,10 @@
 package signature
 
 import (
+	"context"
 	"crypto/rand"
 	"fmt"
 	"math/big"
-	"strconv"
 
 	"github.com/krakenh2020/ZKPComponent/data_common"
 	"github.com/krakenh2020/ZKPComponent/signature/ec"
@@ -98,13 +98,37 @@ func CreateSharesShamirSpecial(input []*big.Int, r *big.Int) ([][]*big.Int, erro
 	return res, nil
 }
 
+// pedersenGenerators returns the generators h_0, ..., h_{n-1} of the
+// version used to commit to the dataset vectors.
+func pedersenGenerators(version GeneratorVersion, n int) ([]*ec.Ec, error) {
+	g, err := DefaultGenerators(version)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.Generators(n), nil
+}
+
+// CommmitDataset commits to the vector with the generators of
+// CurrentGeneratorVersion.
 func CommmitDataset(vec []*big.Int, r *big.Int) (*ec.Ec, *big.Int, error) {
-	h := make([]*ec.Ec, len(vec))
+	return CommmitDatasetContext(context.Background(), CurrentGeneratorVersion, vec, r)
+}
+
+// CommmitDatasetContext is CommmitDataset with the generators of the
+// version. Since the values and r are secret, the commitment is
+// computed with parallel constant time scalar multiplications, which
+// stop when ctx is cancelled.
+func CommmitDatasetContext(ctx context.Context, version GeneratorVersion, vec []*big.Int, r *big.Int) (*ec.Ec, *big.Int, error) {
+	g, err := DefaultGenerators(version)
+	if err != nil {
+		return nil, nil, err
+	}
 	for i := 0; i < len(vec); i++ {
-		h[i] = ec.HashIntoCurvePoint([]byte(strconv.Itoa(i)))
+		if new(big.Int).Abs(vec[i]).Cmp(data_common.MPCPrimeHalf) > 0 {
+			return nil, nil, fmt.Errorf("error: input value too big")
+		}
 	}
-
-	var err error
 	if r == nil {
 		r, err = rand.Int(rand.Reader, ec.P.Params().N)
 		if err != nil {
@@ -112,39 +136,155 @@ func CommmitDataset(vec []*big.Int, r *big.Int) (*ec.Ec, *big.Int, error) {
 		}
 	}
 
-	res := new(ec.Ec).ScalarBaseMult(r)
-	hITovecI := new(ec.Ec)
-	for i := 0; i < len(vec); i++ {
-		val := new(big.Int).Set(vec[i])
-		if new(big.Int).Abs(val).Cmp(data_common.MPCPrimeHalf) > 0 {
-			return nil, nil, fmt.Errorf("error: input value too big")
-		}
-
-		hITovecI.ScalarMult(h[i], val)
-		res.Add(res, hITovecI)
+	res, err := g.commitConstTime(ctx, vec, r)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	return res, r, nil
 }
 
+// CommitShareSpecial commits to the share with the generators of
+// CurrentGeneratorVersion.
 func CommitShareSpecial(vec []*big.Int) *ec.Ec {
-	h := make([]*ec.Ec, (len(vec)-1)/2)
-	for i := 0; i < len(h); i++ {
-		h[i] = ec.HashIntoCurvePoint([]byte(strconv.Itoa(i)))
+	// the context is never cancelled and the version is valid, hence
+	// there is no error
+	res, _ := CommitShareSpecialContext(context.Background(), CurrentGeneratorVersion, vec)
+
+	return res
+}
+
+// CommitShareSpecialContext is CommitShareSpecial with the generators
+// of the version, computing the commitment with a parallel
+// multi-scalar multiplication that stops when ctx is cancelled. The
+// multiplication is not constant time, which leaks at most the share,
+// and a single share is independent of the data.
+func CommitShareSpecialContext(ctx context.Context, version GeneratorVersion, vec []*big.Int) (*ec.Ec, error) {
+	g, err := DefaultGenerators(version)
+	if err != nil {
+		return nil, err
+	}
+	n := (len(vec) - 1) / 2
+	scalars := make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		scalars[i] = new(big.Int).Mul(vec[i+len(vec)/2], data_common.MPCPrime)
+		scalars[i].Add(scalars[i], vec[i])
+	}
+
+	return g.commit(ctx, scalars, vec[len(vec)-1])
+}
+
+// shareCommitBlock is the number of elements buffered by ShareCommitter
+// before adding them to the commitment.
+const shareCommitBlock = 1 << 14
+
+// ShareCommitter computes CommitShareSpecialContext of a share given
+// block by block, so that the memory use does not depend on the length
+// of the share.
+type ShareCommitter struct {
+	ctx     context.Context
+	g       *GeneratorTable
+	n       int
+	scalars []*big.Int
+	acc     *ec.Ec
+}
+
+func NewShareCommitter(ctx context.Context, version GeneratorVersion) (*ShareCommitter, error) {
+	g, err := DefaultGenerators(version)
+	if err != nil {
+		return nil, err
 	}
 
-	res := new(ec.Ec).ScalarBaseMult(vec[len(vec)-1])
-	tmp := new(ec.Ec)
-	tmpInt := new(big.Int)
-	for i := 0; i < len(h); i++ {
-		tmpInt.Mul(vec[i+len(vec)/2], data_common.MPCPrime)
-		tmpInt.Add(tmpInt, vec[i])
-		tmpInt.Mod(tmpInt, ec.P.Params().N)
-		tmp.ScalarMult(h[i], tmpInt)
-		res.Add(res, tmp)
+	return &ShareCommitter{ctx: ctx, g: g, acc: new(ec.Ec).Unit()}, nil
+}
+
+// Add appends the elements of the share with the values vals and the
+// hide values hides, the two halves of the share of
+// CreateSharesShamirSpecial.
+func (c *ShareCommitter) Add(vals, hides []*big.Int) error {
+	if len(vals) != len(hides) {
+		return fmt.Errorf("number of values and hide values differ")
+	}
+	for i := range vals {
+		s := new(big.Int).Mul(hides[i], data_common.MPCPrime)
+		c.scalars = append(c.scalars, s.Add(s, vals[i]))
+	}
+	if len(c.scalars) >= shareCommitBlock {
+		return c.flush()
 	}
 
-	return res
+	return nil
+}
+
+func (c *ShareCommitter) flush() error {
+	if len(c.scalars) == 0 {
+		return nil
+	}
+	h := c.g.Range(c.n, c.n+len(c.scalars))
+	res, err := ec.MultiScalarMult(c.ctx, h, c.scalars, 0)
+	if err != nil {
+		return err
+	}
+	c.acc = new(ec.Ec).Add(c.acc, res)
+	c.n += len(c.scalars)
+	c.scalars = c.scalars[:0]
+
+	return nil
+}
+
+// Len returns the number of elements added.
+func (c *ShareCommitter) Len() int {
+	return c.n + len(c.scalars)
+}
+
+// Commit returns the commitment of the share with the last element r.
+func (c *ShareCommitter) Commit(r *big.Int) (*ec.Ec, error) {
+	err := c.flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return new(ec.Ec).Add(c.acc, new(ec.Ec).ScalarBaseMult(r)), nil
+}
+
+// CommitSharesCombination returns sum_j weights[j] CommitShareSpecial(vecs[j])
+// with the generators of the version, computing a single commitment to
+// the combination of the shares, which all have the same length.
+func CommitSharesCombination(ctx context.Context, version GeneratorVersion, vecs [][]*big.Int,
+	weights []*big.Int) (*ec.Ec, error) {
+	if len(vecs) == 0 || len(vecs) != len(weights) {
+		return nil, fmt.Errorf("number of shares and weights differ")
+	}
+	g, err := DefaultGenerators(version)
+	if err != nil {
+		return nil, err
+	}
+	l := len(vecs[0])
+	n := (l - 1) / 2
+	if l == 0 {
+		return nil, fmt.Errorf("empty share")
+	}
+	order := ec.P.Params().N
+	scalars := make([]*big.Int, n)
+	for i := range scalars {
+		scalars[i] = new(big.Int)
+	}
+	r := new(big.Int)
+	s := new(big.Int)
+	for j, vec := range vecs {
+		if len(vec) != l {
+			return nil, fmt.Errorf("shares of different lengths")
+		}
+		for i := 0; i < n; i++ {
+			s.Mul(vec[i+l/2], data_common.MPCPrime)
+			s.Add(s, vec[i]).Mul(s, weights[j])
+			scalars[i].Add(scalars[i], s).Mod(scalars[i], order)
+		}
+		s.Mul(vec[l-1], weights[j])
+		r.Add(r, s).Mod(r, order)
+	}
+
+	return g.commit(ctx, scalars, r)
 }
 
 func JoinCommits(hSplit []*ec.Ec) (*ec.Ec, error) {
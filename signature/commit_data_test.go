@@ -1,6 +1,7 @@
 package signature
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 	"testing"
@@ -41,3 +42,104 @@ func TestCommmitDataset(t *testing.T) {
 	}
 	assert.True(t, hCheck.Equal(h))
 }
+
+func TestCommitSharesCombination(t *testing.T) {
+	var splits [][]*big.Int
+	var weights []*big.Int
+	expected := new(ec.Ec).Unit()
+	for j := 0; j < 3; j++ {
+		v, err := data_common.NewUniformRangeRandomVector(50, new(big.Int).Neg(data_common.MPCPrimeHalf), data_common.MPCPrimeHalf)
+		assert.NoError(t, err)
+		_, r, err := CommmitDataset(v, nil)
+		assert.NoError(t, err)
+		split, err := CreateSharesShamirSpecial(v, r)
+		assert.NoError(t, err)
+		w := big.NewInt(int64(j + 5))
+		splits = append(splits, split[j])
+		weights = append(weights, w)
+		expected.Add(expected, new(ec.Ec).ScalarMult(CommitShareSpecial(split[j]), w))
+	}
+
+	res, err := CommitSharesCombination(context.Background(), CurrentGeneratorVersion, splits, weights)
+	assert.NoError(t, err)
+	assert.True(t, res.Equal(expected))
+
+	_, err = CommitSharesCombination(context.Background(), CurrentGeneratorVersion, splits, weights[1:])
+	assert.Error(t, err)
+	_, err = CommitSharesCombination(context.Background(), CurrentGeneratorVersion,
+		[][]*big.Int{splits[0], splits[1][1:]}, weights[:2])
+	assert.Error(t, err)
+}
+
+// commitDatasetSequential is the reference implementation of
+// CommmitDataset with one scalar multiplication per element.
+func commitDatasetSequential(vec []*big.Int, r *big.Int) *ec.Ec {
+	h, _ := pedersenGenerators(CurrentGeneratorVersion, len(vec))
+	res := new(ec.Ec).ScalarBaseMult(r)
+	tmp := new(ec.Ec)
+	for i := 0; i < len(vec); i++ {
+		tmp.ScalarMult(h[i], vec[i])
+		res.Add(res, tmp)
+	}
+
+	return res
+}
+
+func TestCommmitDatasetSequential(t *testing.T) {
+	v, err := data_common.NewUniformRangeRandomVector(3000, new(big.Int).Neg(data_common.MPCPrimeHalf), data_common.MPCPrimeHalf)
+	assert.NoError(t, err)
+
+	h, r, err := CommmitDataset(v, nil)
+	assert.NoError(t, err)
+	assert.True(t, h.Equal(commitDatasetSequential(v, r)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err = CommmitDatasetContext(ctx, CurrentGeneratorVersion, v, r)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func BenchmarkCommmitDataset(b *testing.B) {
+	v, err := data_common.NewUniformRangeRandomVector(10000, new(big.Int).Neg(data_common.MPCPrimeHalf), data_common.MPCPrimeHalf)
+	if err != nil {
+		b.Fatal(err)
+	}
+	r := big.NewInt(42)
+	b.Run("sequential", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			commitDatasetSequential(v, r)
+		}
+	})
+	b.Run("msm", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _, err := CommmitDataset(v, r)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestShareCommitter(t *testing.T) {
+	n := shareCommitBlock + 1000
+	v, err := data_common.NewUniformRangeRandomVector(n, new(big.Int).Neg(data_common.MPCPrimeHalf), data_common.MPCPrimeHalf)
+	assert.NoError(t, err)
+	split, err := CreateSharesShamirSpecial(v, big.NewInt(7))
+	assert.NoError(t, err)
+
+	c, err := NewShareCommitter(context.Background(), CurrentGeneratorVersion)
+	assert.NoError(t, err)
+	for start := 0; start < n; start += 3000 {
+		end := start + 3000
+		if end > n {
+			end = n
+		}
+		assert.NoError(t, c.Add(split[1][start:end], split[1][n+start:n+end]))
+	}
+	assert.Equal(t, n, c.Len())
+	assert.Error(t, c.Add(split[1][:2], split[1][n:n+1]))
+
+	commit, err := c.Commit(split[1][2*n])
+	assert.NoError(t, err)
+	assert.True(t, commit.Equal(CommitShareSpecial(split[1])))
+}
@@ -0,0 +1,105 @@
+package signature
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/krakenh2020/ZKPComponent/data_common"
+)
+
+// CommitScheme identifies the commitment to the dataset in a
+// SignatureZKP, chosen by the data owner when signing.
+type CommitScheme int
+
+const (
+	// CommitSchemeP256 is the Pedersen vector commitment over P-256
+	// (CommitData), whose opening to the shares is checked by the
+	// nodes outside of the circuit.
+	CommitSchemeP256 CommitScheme = 0
+	// CommitSchemeMiMC is the hash commitment MiMC(r, v_0, ..., v_{n-1})
+	// over the scalar field of BN254 (CommitField), whose opening to
+	// the dataset and to the commitments of each node's share is
+	// checked inside the circuit.
+	CommitSchemeMiMC CommitScheme = 1
+)
+
+// FieldElement returns the BN254 scalar field element encoding the
+// signed integer v.
+func FieldElement(v *big.Int) *big.Int {
+	return new(big.Int).Mod(v, fr.Modulus())
+}
+
+// mimcCommit returns MiMC(r, vec...) with the MiMC of the circuit
+// version, each input encoded as a field element of 32 bytes.
+func mimcCommit(version CircuitVersion, vec []*big.Int, r *big.Int) (*big.Int, error) {
+	hs, err := version.NewHash()
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, fr.Bytes)
+	hs.Write(FieldElement(r).FillBytes(buf))
+	for _, e := range vec {
+		hs.Write(FieldElement(e).FillBytes(buf))
+	}
+
+	return new(big.Int).SetBytes(hs.Sum(nil)), nil
+}
+
+// CommitDatasetMiMC commits to the vector with CommitSchemeMiMC and the
+// hash of the circuit version. If r is nil, a random one is chosen.
+func CommitDatasetMiMC(version CircuitVersion, vec []*big.Int, r *big.Int) (*big.Int, *big.Int, error) {
+	for i := 0; i < len(vec); i++ {
+		if new(big.Int).Abs(vec[i]).Cmp(data_common.MPCPrimeHalf) > 0 {
+			return nil, nil, fmt.Errorf("error: input value too big")
+		}
+	}
+	var err error
+	if r == nil {
+		r, err = rand.Int(rand.Reader, fr.Modulus())
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	commit, err := mimcCommit(version, vec, r)
+
+	return commit, r, err
+}
+
+// CreateSharesShamirMiMC splits the vector with
+// data_common.CreateSharesShamir and appends to each share a random
+// r_i. It returns the shares and their commitments MiMC(r_i, share)
+// with the hash of the circuit version.
+func CreateSharesShamirMiMC(version CircuitVersion, vec []*big.Int) ([][]*big.Int, []*big.Int, error) {
+	shares, err := data_common.CreateSharesShamir(vec)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	commits := make([]*big.Int, 3)
+	for i := 0; i < 3; i++ {
+		r, err := rand.Int(rand.Reader, fr.Modulus())
+		if err != nil {
+			return nil, nil, err
+		}
+		shares[i] = append(shares[i], r)
+		commits[i], err = CommitShareMiMC(version, shares[i])
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return shares, commits, nil
+}
+
+// CommitShareMiMC returns the commitment of a share created by
+// CreateSharesShamirMiMC with the circuit version.
+func CommitShareMiMC(version CircuitVersion, split []*big.Int) (*big.Int, error) {
+	if len(split) == 0 {
+		return nil, fmt.Errorf("empty share")
+	}
+
+	return mimcCommit(version, split[:len(split)-1], split[len(split)-1])
+}
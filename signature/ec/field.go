@@ -0,0 +1,282 @@
+package ec
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+// fe is an element of the P-256 base field in Montgomery form
+// (R = 2^256), as little-endian 64-bit limbs. It is used for the fast
+// Jacobian arithmetic of the multi-scalar multiplication, the big.Int
+// affine arithmetic of crypto/elliptic being too slow for many
+// additions.
+type fe [4]uint64
+
+// limbs of the P-256 prime 2^256 - 2^224 + 2^192 + 2^96 - 1; since
+// the lowest limb is 2^64 - 1, -p^{-1} mod 2^64 is 1
+var feP = fe{0xffffffffffffffff, 0x00000000ffffffff, 0x0000000000000000, 0xffffffff00000001}
+
+var feR = new(big.Int).Lsh(big.NewInt(1), 256)
+
+func feFromBig(x *big.Int) fe {
+	t := new(big.Int).Mul(x, feR)
+	t.Mod(t, P.Params().P)
+	var z fe
+	for i, w := range t.Bits() {
+		z[i] = uint64(w)
+	}
+
+	return z
+}
+
+func (x *fe) toBig() *big.Int {
+	var t fe
+	feMul(&t, x, &fe{1, 0, 0, 0})
+	b := make([]byte, 32)
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 8; j++ {
+			b[31-8*i-j] = byte(t[i] >> (8 * j))
+		}
+	}
+
+	return new(big.Int).SetBytes(b)
+}
+
+func (x *fe) isZero() bool {
+	return x[0]|x[1]|x[2]|x[3] == 0
+}
+
+// feReduce subtracts p from t if t (with carry bit hi) is at least p.
+func feReduce(z *fe, t *fe, hi uint64) {
+	var r fe
+	var b uint64
+	r[0], b = bits.Sub64(t[0], feP[0], 0)
+	r[1], b = bits.Sub64(t[1], feP[1], b)
+	r[2], b = bits.Sub64(t[2], feP[2], b)
+	r[3], b = bits.Sub64(t[3], feP[3], b)
+	_, b = bits.Sub64(hi, 0, b)
+	if b != 0 {
+		*z = *t
+		return
+	}
+	*z = r
+}
+
+func feAdd(z, x, y *fe) {
+	var t fe
+	var c uint64
+	t[0], c = bits.Add64(x[0], y[0], 0)
+	t[1], c = bits.Add64(x[1], y[1], c)
+	t[2], c = bits.Add64(x[2], y[2], c)
+	t[3], c = bits.Add64(x[3], y[3], c)
+	feReduce(z, &t, c)
+}
+
+func feSub(z, x, y *fe) {
+	var t fe
+	var b uint64
+	t[0], b = bits.Sub64(x[0], y[0], 0)
+	t[1], b = bits.Sub64(x[1], y[1], b)
+	t[2], b = bits.Sub64(x[2], y[2], b)
+	t[3], b = bits.Sub64(x[3], y[3], b)
+	if b != 0 {
+		var c uint64
+		t[0], c = bits.Add64(t[0], feP[0], 0)
+		t[1], c = bits.Add64(t[1], feP[1], c)
+		t[2], c = bits.Add64(t[2], feP[2], c)
+		t[3], _ = bits.Add64(t[3], feP[3], c)
+	}
+	*z = t
+}
+
+// feMul sets z = x*y/R mod p (CIOS Montgomery multiplication, unrolled).
+func feMul(z, x, y *fe) {
+	x0, x1, x2, x3 := x[0], x[1], x[2], x[3]
+	var t0, t1, t2, t3, t4, t5, c, m, hi, lo, carry uint64
+	// word 0 of y
+	c = 0
+	hi, lo = bits.Mul64(x0, y[0])
+	lo, carry = bits.Add64(lo, t0, 0)
+	hi += carry
+	t0, carry = bits.Add64(lo, c, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(x1, y[0])
+	lo, carry = bits.Add64(lo, t1, 0)
+	hi += carry
+	t1, carry = bits.Add64(lo, c, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(x2, y[0])
+	lo, carry = bits.Add64(lo, t2, 0)
+	hi += carry
+	t2, carry = bits.Add64(lo, c, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(x3, y[0])
+	lo, carry = bits.Add64(lo, t3, 0)
+	hi += carry
+	t3, carry = bits.Add64(lo, c, 0)
+	c = hi + carry
+	t4, carry = bits.Add64(t4, c, 0)
+	t5 = carry
+	m = t0
+	hi, lo = bits.Mul64(m, feP[0])
+	_, carry = bits.Add64(lo, t0, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(m, feP[1])
+	lo, carry = bits.Add64(lo, t1, 0)
+	hi += carry
+	t0, carry = bits.Add64(lo, c, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(m, feP[2])
+	lo, carry = bits.Add64(lo, t2, 0)
+	hi += carry
+	t1, carry = bits.Add64(lo, c, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(m, feP[3])
+	lo, carry = bits.Add64(lo, t3, 0)
+	hi += carry
+	t2, carry = bits.Add64(lo, c, 0)
+	c = hi + carry
+	t3, carry = bits.Add64(t4, c, 0)
+	t4 = t5 + carry
+	// word 1 of y
+	c = 0
+	hi, lo = bits.Mul64(x0, y[1])
+	lo, carry = bits.Add64(lo, t0, 0)
+	hi += carry
+	t0, carry = bits.Add64(lo, c, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(x1, y[1])
+	lo, carry = bits.Add64(lo, t1, 0)
+	hi += carry
+	t1, carry = bits.Add64(lo, c, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(x2, y[1])
+	lo, carry = bits.Add64(lo, t2, 0)
+	hi += carry
+	t2, carry = bits.Add64(lo, c, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(x3, y[1])
+	lo, carry = bits.Add64(lo, t3, 0)
+	hi += carry
+	t3, carry = bits.Add64(lo, c, 0)
+	c = hi + carry
+	t4, carry = bits.Add64(t4, c, 0)
+	t5 = carry
+	m = t0
+	hi, lo = bits.Mul64(m, feP[0])
+	_, carry = bits.Add64(lo, t0, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(m, feP[1])
+	lo, carry = bits.Add64(lo, t1, 0)
+	hi += carry
+	t0, carry = bits.Add64(lo, c, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(m, feP[2])
+	lo, carry = bits.Add64(lo, t2, 0)
+	hi += carry
+	t1, carry = bits.Add64(lo, c, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(m, feP[3])
+	lo, carry = bits.Add64(lo, t3, 0)
+	hi += carry
+	t2, carry = bits.Add64(lo, c, 0)
+	c = hi + carry
+	t3, carry = bits.Add64(t4, c, 0)
+	t4 = t5 + carry
+	// word 2 of y
+	c = 0
+	hi, lo = bits.Mul64(x0, y[2])
+	lo, carry = bits.Add64(lo, t0, 0)
+	hi += carry
+	t0, carry = bits.Add64(lo, c, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(x1, y[2])
+	lo, carry = bits.Add64(lo, t1, 0)
+	hi += carry
+	t1, carry = bits.Add64(lo, c, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(x2, y[2])
+	lo, carry = bits.Add64(lo, t2, 0)
+	hi += carry
+	t2, carry = bits.Add64(lo, c, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(x3, y[2])
+	lo, carry = bits.Add64(lo, t3, 0)
+	hi += carry
+	t3, carry = bits.Add64(lo, c, 0)
+	c = hi + carry
+	t4, carry = bits.Add64(t4, c, 0)
+	t5 = carry
+	m = t0
+	hi, lo = bits.Mul64(m, feP[0])
+	_, carry = bits.Add64(lo, t0, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(m, feP[1])
+	lo, carry = bits.Add64(lo, t1, 0)
+	hi += carry
+	t0, carry = bits.Add64(lo, c, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(m, feP[2])
+	lo, carry = bits.Add64(lo, t2, 0)
+	hi += carry
+	t1, carry = bits.Add64(lo, c, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(m, feP[3])
+	lo, carry = bits.Add64(lo, t3, 0)
+	hi += carry
+	t2, carry = bits.Add64(lo, c, 0)
+	c = hi + carry
+	t3, carry = bits.Add64(t4, c, 0)
+	t4 = t5 + carry
+	// word 3 of y
+	c = 0
+	hi, lo = bits.Mul64(x0, y[3])
+	lo, carry = bits.Add64(lo, t0, 0)
+	hi += carry
+	t0, carry = bits.Add64(lo, c, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(x1, y[3])
+	lo, carry = bits.Add64(lo, t1, 0)
+	hi += carry
+	t1, carry = bits.Add64(lo, c, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(x2, y[3])
+	lo, carry = bits.Add64(lo, t2, 0)
+	hi += carry
+	t2, carry = bits.Add64(lo, c, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(x3, y[3])
+	lo, carry = bits.Add64(lo, t3, 0)
+	hi += carry
+	t3, carry = bits.Add64(lo, c, 0)
+	c = hi + carry
+	t4, carry = bits.Add64(t4, c, 0)
+	t5 = carry
+	m = t0
+	hi, lo = bits.Mul64(m, feP[0])
+	_, carry = bits.Add64(lo, t0, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(m, feP[1])
+	lo, carry = bits.Add64(lo, t1, 0)
+	hi += carry
+	t0, carry = bits.Add64(lo, c, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(m, feP[2])
+	lo, carry = bits.Add64(lo, t2, 0)
+	hi += carry
+	t1, carry = bits.Add64(lo, c, 0)
+	c = hi + carry
+	hi, lo = bits.Mul64(m, feP[3])
+	lo, carry = bits.Add64(lo, t3, 0)
+	hi += carry
+	t2, carry = bits.Add64(lo, c, 0)
+	c = hi + carry
+	t3, carry = bits.Add64(t4, c, 0)
+	t4 = t5 + carry
+	r := fe{t0, t1, t2, t3}
+	feReduce(z, &r, t4)
+}
+
+func feSquare(z, x *fe) {
+	feMul(z, x, x)
+}
@@ -0,0 +1,175 @@
+package ec
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"runtime"
+	"sync"
+)
+
+var pMinus2 = new(big.Int).Sub(P.Params().P, big.NewInt(2))
+
+// feInv sets z = 1/x mod p by Fermat's little theorem.
+func feInv(z, x *fe) {
+	res := feOne
+	base := *x
+	for i := pMinus2.BitLen() - 1; i >= 0; i-- {
+		feSquare(&res, &res)
+		if pMinus2.Bit(i) == 1 {
+			feMul(&res, &res, &base)
+		}
+	}
+	*z = res
+}
+
+// normalize converts the points to z = 1 with a single inversion
+// (Montgomery's trick). The points at infinity are left unchanged.
+func normalize(ps []jac) {
+	prod := make([]fe, len(ps))
+	acc := feOne
+	for i := range ps {
+		prod[i] = acc
+		if !ps[i].z.isZero() {
+			feMul(&acc, &acc, &ps[i].z)
+		}
+	}
+	var inv fe
+	feInv(&inv, &acc)
+	for i := len(ps) - 1; i >= 0; i-- {
+		if ps[i].z.isZero() {
+			continue
+		}
+		var zInv, zInv2, zInv3 fe
+		feMul(&zInv, &inv, &prod[i])
+		feMul(&inv, &inv, &ps[i].z)
+		feSquare(&zInv2, &zInv)
+		feMul(&zInv3, &zInv2, &zInv)
+		feMul(&ps[i].x, &ps[i].x, &zInv2)
+		feMul(&ps[i].y, &ps[i].y, &zInv3)
+		ps[i].z = feOne
+	}
+}
+
+// FixedBaseTable holds the multiples 2^(c*k) P_i of fixed base points
+// P_i for all windows k of the scalars, so that a multi-scalar
+// multiplication with these bases needs no doublings and a single set
+// of buckets for all the windows. It is safe for concurrent use.
+type FixedBaseTable struct {
+	c       int
+	windows int
+	// multiples[i][k] = 2^(c*k) P_i, with z = 1
+	multiples [][]jac
+}
+
+// fixedBaseWindow is the window size of the FixedBaseTable.
+const fixedBaseWindow = 12
+
+func NewFixedBaseTable(points []*Ec) *FixedBaseTable {
+	c := fixedBaseWindow
+	windows := (P.Params().N.BitLen() + c - 1) / c
+	multiples := make([][]jac, len(points))
+
+	workers := runtime.GOMAXPROCS(0)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < len(points); i += workers {
+				m := make([]jac, windows)
+				m[0] = jacFromEc(points[i])
+				for k := 1; k < windows; k++ {
+					m[k] = m[k-1]
+					for j := 0; j < c; j++ {
+						m[k].double(&m[k])
+					}
+				}
+				normalize(m)
+				multiples[i] = m
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	return &FixedBaseTable{c: c, windows: windows, multiples: multiples}
+}
+
+// Len returns the number of base points of the table.
+func (t *FixedBaseTable) Len() int {
+	return len(t.multiples)
+}
+
+// MultiScalarMult returns sum_i scalars[i] * P_i for the first
+// len(scalars) base points P_i of the table, like the function
+// MultiScalarMult, which is not constant time either.
+func (t *FixedBaseTable) MultiScalarMult(ctx context.Context, scalars []*big.Int, workers int) (*Ec, error) {
+	if len(scalars) > t.Len() {
+		return nil, fmt.Errorf("ec: more scalars than base points")
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	chunk := (len(scalars) + workers - 1) / workers
+	if chunk < msmMinChunk {
+		chunk = msmMinChunk
+	}
+	nChunks := (len(scalars) + chunk - 1) / chunk
+
+	results := make([]jac, nChunks)
+	var wg sync.WaitGroup
+	for i := 0; i < nChunks; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			end := (i + 1) * chunk
+			if end > len(scalars) {
+				end = len(scalars)
+			}
+			results[i] = t.msmChunk(ctx, i*chunk, scalars[i*chunk:end])
+		}(i)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var res jac
+	for i := range results {
+		res.add(&res, &results[i])
+	}
+
+	return res.toEc(), nil
+}
+
+func (t *FixedBaseTable) msmChunk(ctx context.Context, start int, scalars []*big.Int) jac {
+	buckets := make([]jac, 1<<t.c)
+	var p jac
+	for i, s := range scalars {
+		if i%msmMinChunk == 0 && ctx.Err() != nil {
+			return jac{}
+		}
+		k, neg := scalarLimbs(s)
+		n := scalarBits(&k)
+		for w := 0; w*t.c < n; w++ {
+			b := window(&k, w*t.c, t.c)
+			if b == 0 {
+				continue
+			}
+			p = t.multiples[start+i][w]
+			if neg {
+				p.neg(&p)
+			}
+			buckets[b].addAffine(&buckets[b], &p)
+		}
+	}
+
+	// sum_b b * buckets[b]
+	var running, sum jac
+	for b := len(buckets) - 1; b > 0; b-- {
+		running.add(&running, &buckets[b])
+		sum.add(&sum, &running)
+	}
+
+	return sum
+}
@@ -0,0 +1,142 @@
+package ec
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+)
+
+// ExpandMessageXMD is expand_message_xmd of RFC 9380 with SHA-256.
+func ExpandMessageXMD(msg, dst []byte, n int) ([]byte, error) {
+	const bInBytes, rInBytes = sha256.Size, sha256.BlockSize
+	ell := (n + bInBytes - 1) / bInBytes
+	if ell > 255 || n > 65535 || len(dst) > 255 {
+		return nil, fmt.Errorf("invalid expand_message_xmd parameters")
+	}
+	dstPrime := append(dst[:len(dst):len(dst)], byte(len(dst)))
+
+	h := sha256.New()
+	h.Write(make([]byte, rInBytes))
+	h.Write(msg)
+	h.Write([]byte{byte(n >> 8), byte(n), 0})
+	h.Write(dstPrime)
+	b0 := h.Sum(nil)
+
+	h.Reset()
+	h.Write(b0)
+	h.Write([]byte{1})
+	h.Write(dstPrime)
+	bi := h.Sum(nil)
+
+	res := make([]byte, 0, ell*bInBytes)
+	res = append(res, bi...)
+	for i := 2; i <= ell; i++ {
+		x := make([]byte, bInBytes)
+		for j := range x {
+			x[j] = b0[j] ^ bi[j]
+		}
+		h.Reset()
+		h.Write(x)
+		h.Write([]byte{byte(i)})
+		h.Write(dstPrime)
+		bi = h.Sum(nil)
+		res = append(res, bi...)
+	}
+
+	return res[:n], nil
+}
+
+// hashToFieldP256 is hash_to_field of RFC 9380 for the P-256 base
+// field (L = 48).
+func hashToFieldP256(msg, dst []byte, count int) ([]*big.Int, error) {
+	const l = 48
+	uniform, err := ExpandMessageXMD(msg, dst, count*l)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*big.Int, count)
+	for i := range res {
+		res[i] = new(big.Int).SetBytes(uniform[i*l : (i+1)*l])
+		res[i].Mod(res[i], P.Params().P)
+	}
+
+	return res, nil
+}
+
+var (
+	sswuZ = big.NewInt(-10)
+	sswuA = big.NewInt(-3)
+	// exponents of the square root and of the Legendre symbol, p = 3 mod 4
+	sqrtExp     = new(big.Int).Rsh(new(big.Int).Add(P.Params().P, big.NewInt(1)), 2)
+	legendreExp = new(big.Int).Rsh(new(big.Int).Sub(P.Params().P, big.NewInt(1)), 1)
+)
+
+func curveRhs(x *big.Int) *big.Int {
+	p := P.Params().P
+	res := new(big.Int).Mul(x, x)
+	res.Mul(res, x)
+	res.Add(res, new(big.Int).Mul(sswuA, x))
+	res.Add(res, P.Params().B)
+
+	return res.Mod(res, p)
+}
+
+func isSquare(x *big.Int) bool {
+	t := new(big.Int).Exp(x, legendreExp, P.Params().P)
+
+	return t.Sign() == 0 || t.Cmp(big.NewInt(1)) == 0
+}
+
+// mapToCurveSSWU is the simplified SWU map of RFC 9380 (section 6.6.2)
+// for P-256. The generators are derived from public data, hence the
+// map is not constant-time.
+func mapToCurveSSWU(u *big.Int) *Ec {
+	p := P.Params().P
+	mod := func(x *big.Int) *big.Int { return x.Mod(x, p) }
+
+	u2 := mod(new(big.Int).Mul(u, u))
+	zu2 := mod(new(big.Int).Mul(sswuZ, u2))
+	// tv1 = inv0(Z^2 u^4 + Z u^2)
+	tv1 := mod(new(big.Int).Mul(zu2, zu2))
+	tv1 = mod(tv1.Add(tv1, zu2))
+	var x1 *big.Int
+	if tv1.Sign() == 0 {
+		// x1 = B / (Z A)
+		x1 = new(big.Int).ModInverse(mod(new(big.Int).Mul(sswuZ, sswuA)), p)
+		x1 = mod(x1.Mul(x1, P.Params().B))
+	} else {
+		tv1.ModInverse(tv1, p)
+		// x1 = (-B / A) (1 + tv1)
+		x1 = new(big.Int).ModInverse(mod(new(big.Int).Neg(sswuA)), p)
+		x1 = mod(x1.Mul(x1, P.Params().B))
+		x1 = mod(x1.Mul(x1, tv1.Add(tv1, big.NewInt(1))))
+	}
+
+	x := x1
+	gx := curveRhs(x1)
+	if !isSquare(gx) {
+		x = mod(new(big.Int).Mul(zu2, x1))
+		gx = curveRhs(x)
+	}
+	y := new(big.Int).Exp(gx, sqrtExp, p)
+	if u.Bit(0) != y.Bit(0) {
+		y = mod(y.Neg(y))
+	}
+
+	return &Ec{X: x, Y: y}
+}
+
+// HashToCurve is the hash_to_curve of the RFC 9380 suite
+// P256_XMD:SHA-256_SSWU_RO_ with the domain separation tag dst.
+func HashToCurve(msg, dst []byte) (*Ec, error) {
+	u, err := hashToFieldP256(msg, dst, 2)
+	if err != nil {
+		return nil, err
+	}
+	q0 := mapToCurveSSWU(u[0])
+	q1 := mapToCurveSSWU(u[1])
+
+	// the cofactor of P-256 is 1
+	return new(Ec).Add(q0, q1), nil
+}
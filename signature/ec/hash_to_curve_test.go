@@ -0,0 +1,31 @@
+package ec
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// test vectors of RFC 9380, appendices J.1.1 and K.1
+func TestExpandMessageXMD(t *testing.T) {
+	res, err := ExpandMessageXMD([]byte(""), []byte("QUUX-V01-CS02-with-expander-SHA256-128"), 0x20)
+	assert.NoError(t, err)
+	assert.Equal(t, "68a985b87eb6b46952128911f2a4412bbc302a9d759667f87f7a21d803f07235", hex.EncodeToString(res))
+}
+
+func TestHashToCurve(t *testing.T) {
+	dst := []byte("QUUX-V01-CS02-with-P256_XMD:SHA-256_SSWU_RO_")
+	for _, v := range []struct{ msg, x, y string }{
+		{"", "2c15230b26dbc6fc9a37051158c95b79656e17a1a920b11394ca91c44247d3e4",
+			"8a7a74985cc5c776cdfe4b1f19884970453912e9d31528c060be9ab5c43e8415"},
+		{"abc", "0bb8b87485551aa43ed54f009230450b492fead5f1cc91658775dac4a3388a0f",
+			"5c41b3d0731a27a7b14bc0bf0ccded2d8751f83493404c84a88e71ffd424212e"},
+	} {
+		p, err := HashToCurve([]byte(v.msg), dst)
+		assert.NoError(t, err)
+		assert.Equal(t, v.x, hex.EncodeToString(p.X.FillBytes(make([]byte, 32))), v.msg)
+		assert.Equal(t, v.y, hex.EncodeToString(p.Y.FillBytes(make([]byte, 32))), v.msg)
+		assert.True(t, P.IsOnCurve(p.X, p.Y))
+	}
+}
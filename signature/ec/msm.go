@@ -0,0 +1,410 @@
+package ec
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"math/bits"
+	"runtime"
+	"sync"
+)
+
+// jac is a point in Jacobian coordinates (x/z^2, y/z^3), the point at
+// infinity having z = 0.
+type jac struct {
+	x, y, z fe
+}
+
+var feOne = feFromBig(big.NewInt(1))
+
+func jacFromEc(p *Ec) jac {
+	if p.X.Sign() == 0 && p.Y.Sign() == 0 {
+		return jac{}
+	}
+
+	return jac{x: feFromBig(p.X), y: feFromBig(p.Y), z: feOne}
+}
+
+func (p *jac) toEc() *Ec {
+	if p.z.isZero() {
+		return new(Ec).Unit()
+	}
+	zInv := new(big.Int).ModInverse(p.z.toBig(), P.Params().P)
+	zInvF := feFromBig(zInv)
+	var zInv2, zInv3, x, y fe
+	feSquare(&zInv2, &zInvF)
+	feMul(&zInv3, &zInv2, &zInvF)
+	feMul(&x, &p.x, &zInv2)
+	feMul(&y, &p.y, &zInv3)
+
+	return &Ec{X: x.toBig(), Y: y.toBig()}
+}
+
+// double sets p = 2q, using dbl-2001-b for a = -3.
+func (p *jac) double(q *jac) {
+	if q.z.isZero() {
+		*p = *q
+		return
+	}
+	var delta, gamma, beta, alpha, t1, t2 fe
+	feSquare(&delta, &q.z)
+	feSquare(&gamma, &q.y)
+	feMul(&beta, &q.x, &gamma)
+	feSub(&t1, &q.x, &delta)
+	feAdd(&t2, &q.x, &delta)
+	feMul(&alpha, &t1, &t2)
+	feAdd(&t1, &alpha, &alpha)
+	feAdd(&alpha, &t1, &alpha)
+
+	var x3, y3, z3 fe
+	// z3 = (y + z)^2 - gamma - delta
+	feAdd(&t1, &q.y, &q.z)
+	feSquare(&z3, &t1)
+	feSub(&z3, &z3, &gamma)
+	feSub(&z3, &z3, &delta)
+	// x3 = alpha^2 - 8 beta
+	feAdd(&beta, &beta, &beta)
+	feAdd(&beta, &beta, &beta)
+	feSquare(&x3, &alpha)
+	feSub(&x3, &x3, &beta)
+	feSub(&x3, &x3, &beta)
+	// y3 = alpha (4 beta - x3) - 8 gamma^2
+	feSub(&t1, &beta, &x3)
+	feMul(&y3, &alpha, &t1)
+	feSquare(&t2, &gamma)
+	feAdd(&t2, &t2, &t2)
+	feAdd(&t2, &t2, &t2)
+	feAdd(&t2, &t2, &t2)
+	feSub(&y3, &y3, &t2)
+
+	p.x, p.y, p.z = x3, y3, z3
+}
+
+// add sets p = q + r, using add-2007-bl.
+func (p *jac) add(q, r *jac) {
+	if q.z.isZero() {
+		*p = *r
+		return
+	}
+	if r.z.isZero() {
+		*p = *q
+		return
+	}
+	var z1z1, z2z2, u1, u2, s1, s2, h, rr, t fe
+	feSquare(&z1z1, &q.z)
+	feSquare(&z2z2, &r.z)
+	feMul(&u1, &q.x, &z2z2)
+	feMul(&u2, &r.x, &z1z1)
+	feMul(&t, &r.z, &z2z2)
+	feMul(&s1, &q.y, &t)
+	feMul(&t, &q.z, &z1z1)
+	feMul(&s2, &r.y, &t)
+	feSub(&h, &u2, &u1)
+	feSub(&rr, &s2, &s1)
+	if h.isZero() {
+		if rr.isZero() {
+			p.double(q)
+		} else {
+			*p = jac{}
+		}
+		return
+	}
+
+	var i, j, v, x3, y3, z3 fe
+	feAdd(&t, &h, &h)
+	feSquare(&i, &t)
+	feMul(&j, &h, &i)
+	feAdd(&rr, &rr, &rr)
+	feMul(&v, &u1, &i)
+	// x3 = r^2 - j - 2v
+	feSquare(&x3, &rr)
+	feSub(&x3, &x3, &j)
+	feSub(&x3, &x3, &v)
+	feSub(&x3, &x3, &v)
+	// y3 = r (v - x3) - 2 s1 j
+	feSub(&t, &v, &x3)
+	feMul(&y3, &rr, &t)
+	feMul(&t, &s1, &j)
+	feAdd(&t, &t, &t)
+	feSub(&y3, &y3, &t)
+	// z3 = ((z1 + z2)^2 - z1z1 - z2z2) h
+	feAdd(&t, &q.z, &r.z)
+	feSquare(&z3, &t)
+	feSub(&z3, &z3, &z1z1)
+	feSub(&z3, &z3, &z2z2)
+	feMul(&z3, &z3, &h)
+
+	p.x, p.y, p.z = x3, y3, z3
+}
+
+// addAffine sets p = q + r for r with z = 1, using madd-2007-bl.
+func (p *jac) addAffine(q, r *jac) {
+	if q.z.isZero() {
+		*p = *r
+		return
+	}
+	if r.z.isZero() {
+		*p = *q
+		return
+	}
+	var z1z1, u2, s2, h, hh, rr, t fe
+	feSquare(&z1z1, &q.z)
+	feMul(&u2, &r.x, &z1z1)
+	feMul(&t, &q.z, &z1z1)
+	feMul(&s2, &r.y, &t)
+	feSub(&h, &u2, &q.x)
+	feSub(&rr, &s2, &q.y)
+	if h.isZero() {
+		if rr.isZero() {
+			p.double(q)
+		} else {
+			*p = jac{}
+		}
+		return
+	}
+
+	var i, j, v, x3, y3, z3 fe
+	feSquare(&hh, &h)
+	feAdd(&i, &hh, &hh)
+	feAdd(&i, &i, &i)
+	feMul(&j, &h, &i)
+	feAdd(&rr, &rr, &rr)
+	feMul(&v, &q.x, &i)
+	// x3 = r^2 - j - 2v
+	feSquare(&x3, &rr)
+	feSub(&x3, &x3, &j)
+	feSub(&x3, &x3, &v)
+	feSub(&x3, &x3, &v)
+	// y3 = r (v - x3) - 2 y1 j
+	feSub(&t, &v, &x3)
+	feMul(&y3, &rr, &t)
+	feMul(&t, &q.y, &j)
+	feAdd(&t, &t, &t)
+	feSub(&y3, &y3, &t)
+	// z3 = (z1 + h)^2 - z1z1 - hh
+	feAdd(&t, &q.z, &h)
+	feSquare(&z3, &t)
+	feSub(&z3, &z3, &z1z1)
+	feSub(&z3, &z3, &hh)
+
+	p.x, p.y, p.z = x3, y3, z3
+}
+
+// msmMinChunk is the minimal number of points processed by one task
+// of the worker pool.
+const msmMinChunk = 256
+
+// MultiScalarMult returns sum_i scalars[i] * points[i] using Pippenger's
+// bucket method. The points are split into one chunk per worker
+// (GOMAXPROCS workers if workers is not positive). The scalars are
+// taken modulo the group order, so negative scalars are supported. An
+// error is returned if the numbers of points and scalars differ or if
+// the context is cancelled.
+//
+// It is not constant time: the running time and the memory accesses
+// depend on the scalars. It must not be used on secret scalars where
+// the timing can be observed, see MultiScalarMultConstTime.
+func MultiScalarMult(ctx context.Context, points []*Ec, scalars []*big.Int, workers int) (*Ec, error) {
+	if len(points) != len(scalars) {
+		return nil, fmt.Errorf("ec: number of points and scalars differ")
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	chunk := (len(points) + workers - 1) / workers
+	if chunk < msmMinChunk {
+		chunk = msmMinChunk
+	}
+	nChunks := (len(points) + chunk - 1) / chunk
+	if workers > nChunks {
+		workers = nChunks
+	}
+
+	tasks := make(chan int, nChunks)
+	for i := 0; i < nChunks; i++ {
+		tasks <- i
+	}
+	close(tasks)
+
+	results := make([]jac, nChunks)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range tasks {
+				if ctx.Err() != nil {
+					return
+				}
+				end := (i + 1) * chunk
+				if end > len(points) {
+					end = len(points)
+				}
+				results[i] = msmChunk(ctx, points[i*chunk:end], scalars[i*chunk:end])
+			}
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var res jac
+	for i := range results {
+		res.add(&res, &results[i])
+	}
+
+	return res.toEc(), nil
+}
+
+// MultiScalarMultConstTime is MultiScalarMult for secret scalars,
+// computing every scalars[i] * points[i] with the constant time P-256
+// scalar multiplication of crypto/elliptic. It is about 5 times slower
+// than MultiScalarMult.
+func MultiScalarMultConstTime(ctx context.Context, points []*Ec, scalars []*big.Int, workers int) (*Ec, error) {
+	if len(points) != len(scalars) {
+		return nil, fmt.Errorf("ec: number of points and scalars differ")
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	results := make([]*Ec, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			res := new(Ec).Unit()
+			k := new(big.Int)
+			buf := make([]byte, 32)
+			for i := w; i < len(points); i += workers {
+				if ctx.Err() != nil {
+					return
+				}
+				// fixed length scalars
+				k.Mod(scalars[i], P.Params().N).FillBytes(buf)
+				x, y := P.ScalarMult(points[i].X, points[i].Y, buf)
+				res.X, res.Y = P.Add(res.X, res.Y, x, y)
+			}
+			results[w] = res
+		}(w)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	res := new(Ec).Unit()
+	for _, e := range results {
+		res.Add(res, e)
+	}
+
+	return res, nil
+}
+
+var halfN = new(big.Int).Rsh(P.Params().N, 1)
+
+// scalarLimbs returns k mod N as little-endian 64-bit limbs. If k mod N
+// is above N/2, it returns the limbs of N - (k mod N) and neg, so that
+// small negative scalars stay small.
+func scalarLimbs(k *big.Int) (res [4]uint64, neg bool) {
+	t := k
+	if k.Sign() < 0 || k.Cmp(halfN) > 0 {
+		t = new(big.Int).Mod(k, P.Params().N)
+		if t.Cmp(halfN) > 0 {
+			t.Sub(P.Params().N, t)
+			neg = true
+		}
+	}
+	b := t.FillBytes(make([]byte, 32))
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 8; j++ {
+			res[i] |= uint64(b[31-8*i-j]) << (8 * j)
+		}
+	}
+
+	return res, neg
+}
+
+func scalarBits(k *[4]uint64) int {
+	for l := 3; l >= 0; l-- {
+		if k[l] != 0 {
+			return 64*l + bits.Len64(k[l])
+		}
+	}
+
+	return 0
+}
+
+func (p *jac) neg(q *jac) {
+	*p = *q
+	feSub(&p.y, &fe{}, &q.y)
+}
+
+// window returns the c bits of k starting at bit offset.
+func window(k *[4]uint64, offset, c int) int {
+	i, s := offset/64, offset%64
+	w := k[i] >> s
+	if s+c > 64 && i+1 < 4 {
+		w |= k[i+1] << (64 - s)
+	}
+
+	return int(w & (1<<c - 1))
+}
+
+func msmChunk(ctx context.Context, points []*Ec, scalars []*big.Int) jac {
+	n := len(points)
+	// c is about ln(n)
+	c := bits.Len(uint(n)) * 2 / 3
+	if c < 2 {
+		c = 2
+	}
+	if c > 16 {
+		c = 16
+	}
+
+	ps := make([]jac, n)
+	ks := make([][4]uint64, n)
+	maxBits := 0
+	for i := 0; i < n; i++ {
+		ps[i] = jacFromEc(points[i])
+		var neg bool
+		ks[i], neg = scalarLimbs(scalars[i])
+		if neg {
+			ps[i].neg(&ps[i])
+		}
+		if b := scalarBits(&ks[i]); b > maxBits {
+			maxBits = b
+		}
+	}
+
+	var res jac
+	buckets := make([]jac, 1<<c)
+	for offset := (maxBits - 1) / c * c; offset >= 0; offset -= c {
+		if ctx.Err() != nil {
+			return jac{}
+		}
+		for j := 0; j < c; j++ {
+			res.double(&res)
+		}
+		for b := range buckets {
+			buckets[b] = jac{}
+		}
+		for i := 0; i < n; i++ {
+			b := window(&ks[i], offset, c)
+			if b != 0 {
+				buckets[b].addAffine(&buckets[b], &ps[i])
+			}
+		}
+		// sum_b b * buckets[b]
+		var running, sum jac
+		for b := len(buckets) - 1; b > 0; b-- {
+			running.add(&running, &buckets[b])
+			sum.add(&sum, &running)
+		}
+		res.add(&res, &sum)
+	}
+
+	return res
+}
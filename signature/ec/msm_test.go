@@ -0,0 +1,198 @@
+package ec
+
+import (
+	"context"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFieldMul(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		a, err := rand.Int(rand.Reader, P.Params().P)
+		assert.NoError(t, err)
+		b, err := rand.Int(rand.Reader, P.Params().P)
+		assert.NoError(t, err)
+
+		aF, bF := feFromBig(a), feFromBig(b)
+		var c fe
+		feMul(&c, &aF, &bF)
+		expected := new(big.Int).Mul(a, b)
+		expected.Mod(expected, P.Params().P)
+		assert.Equal(t, 0, expected.Cmp(c.toBig()))
+
+		feSub(&c, &aF, &bF)
+		expected.Sub(a, b)
+		expected.Mod(expected, P.Params().P)
+		assert.Equal(t, 0, expected.Cmp(c.toBig()))
+	}
+}
+
+func scalarMultSequential(points []*Ec, scalars []*big.Int) *Ec {
+	res := new(Ec).Unit()
+	tmp := new(Ec)
+	for i := range points {
+		tmp.ScalarMult(points[i], new(big.Int).Mod(scalars[i], P.Params().N))
+		res.Add(res, tmp)
+	}
+
+	return res
+}
+
+func randomMsmInput(n int) ([]*Ec, []*big.Int, error) {
+	points := make([]*Ec, n)
+	scalars := make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		var err error
+		points[i], err = new(Ec).Random()
+		if err != nil {
+			return nil, nil, err
+		}
+		scalars[i], err = rand.Int(rand.Reader, P.Params().N)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return points, scalars, nil
+}
+
+func TestMultiScalarMult(t *testing.T) {
+	for _, n := range []int{1, 5, 100, 2500} {
+		points, scalars, err := randomMsmInput(n)
+		assert.NoError(t, err)
+		// special cases: small, zero, negative and large scalars,
+		// repeated and opposite points
+		scalars[0] = big.NewInt(3)
+		if n > 4 {
+			scalars[1] = big.NewInt(0)
+			scalars[2].Neg(scalars[2])
+			scalars[3].Add(scalars[3], P.Params().N)
+			points[4] = points[0]
+		}
+		if n > 100 {
+			points[50] = new(Ec).Neg(points[49])
+			scalars[50] = scalars[49]
+			points[51] = new(Ec).Unit()
+		}
+
+		res, err := MultiScalarMult(context.Background(), points, scalars, 0)
+		assert.NoError(t, err)
+		assert.True(t, res.Equal(scalarMultSequential(points, scalars)), n)
+	}
+
+	res, err := MultiScalarMult(context.Background(), nil, nil, 0)
+	assert.NoError(t, err)
+	assert.True(t, res.Equal(new(Ec).Unit()))
+
+	points, scalars, err := randomMsmInput(3)
+	assert.NoError(t, err)
+	scalars[1] = new(big.Int).Neg(scalars[0])
+	points[1] = points[0]
+	scalars[2] = big.NewInt(0)
+	res, err = MultiScalarMult(context.Background(), points, scalars, 0)
+	assert.NoError(t, err)
+	assert.True(t, res.Equal(new(Ec).Unit()))
+}
+
+func TestMultiScalarMultCancel(t *testing.T) {
+	points, scalars, err := randomMsmInput(2000)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = MultiScalarMult(ctx, points, scalars, 0)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func benchmarkMsm(b *testing.B, n int, parallel bool) {
+	points, scalars, err := randomMsmInput(n)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if parallel {
+			_, err = MultiScalarMult(context.Background(), points, scalars, 0)
+			if err != nil {
+				b.Fatal(err)
+			}
+		} else {
+			scalarMultSequential(points, scalars)
+		}
+	}
+}
+
+func BenchmarkScalarMultSequential10000(b *testing.B) {
+	benchmarkMsm(b, 10000, false)
+}
+
+func BenchmarkMultiScalarMult10000(b *testing.B) {
+	benchmarkMsm(b, 10000, true)
+}
+
+func BenchmarkFeMul(b *testing.B) {
+	x := feFromBig(P.Params().Gx)
+	for i := 0; i < b.N; i++ {
+		feMul(&x, &x, &x)
+	}
+}
+
+func BenchmarkJacAddAffine(b *testing.B) {
+	p := jacFromEc(new(Ec).Gen())
+	var q jac
+	q.double(&p)
+	for i := 0; i < b.N; i++ {
+		q.addAffine(&q, &p)
+	}
+}
+
+func TestFixedBaseTable(t *testing.T) {
+	points, scalars, err := randomMsmInput(600)
+	assert.NoError(t, err)
+	scalars[0] = big.NewInt(-5)
+	scalars[1] = big.NewInt(0)
+	points[2] = new(Ec).Unit()
+
+	table := NewFixedBaseTable(points)
+	assert.Equal(t, 600, table.Len())
+	for _, n := range []int{0, 1, 300, 600} {
+		res, err := table.MultiScalarMult(context.Background(), scalars[:n], 0)
+		assert.NoError(t, err)
+		assert.True(t, res.Equal(scalarMultSequential(points[:n], scalars[:n])), n)
+	}
+}
+
+func TestMultiScalarMultConstTime(t *testing.T) {
+	points, scalars, err := randomMsmInput(300)
+	assert.NoError(t, err)
+	scalars[0] = big.NewInt(-5)
+	scalars[1] = big.NewInt(0)
+	scalars[2].Add(scalars[2], P.Params().N)
+	points[3] = new(Ec).Unit()
+
+	res, err := MultiScalarMultConstTime(context.Background(), points, scalars, 0)
+	assert.NoError(t, err)
+	assert.True(t, res.Equal(scalarMultSequential(points, scalars)))
+	res, err = MultiScalarMultConstTime(context.Background(), points[:2], scalars[:2], 4)
+	assert.NoError(t, err)
+	assert.True(t, res.Equal(scalarMultSequential(points[:2], scalars[:2])))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = MultiScalarMultConstTime(ctx, points, scalars, 0)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestMultiScalarMultLengths(t *testing.T) {
+	points, scalars, err := randomMsmInput(3)
+	assert.NoError(t, err)
+	_, err = MultiScalarMult(context.Background(), points, scalars[:2], 0)
+	assert.Error(t, err)
+	_, err = MultiScalarMultConstTime(context.Background(), points[:2], scalars, 0)
+	assert.Error(t, err)
+	_, err = NewFixedBaseTable(points[:2]).MultiScalarMult(context.Background(), scalars, 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,124 @@
+package signature
+
+import (
+	"crypto/elliptic"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/krakenh2020/ZKPComponent/signature/ec"
+)
+
+// TextFieldDST is the domain separation tag of the hash to field of the
+// signed text from CircuitV2 on.
+const TextFieldDST = "KRAKEN-ZKPComponent-V02-TEXT_XMD:SHA-256_BN254FR_"
+
+// textFieldLen is L of hash_to_field of RFC 9380 for the scalar field
+// of BN254: ceil((254 + 128) / 8)
+const textFieldLen = 48
+
+// hashToField is hash_to_field of RFC 9380 with expand_message_xmd
+// SHA-256 for the scalar field of BN254, the label separating the
+// encoded values.
+func hashToField(label string, msg []byte) (*big.Int, error) {
+	buf := append([]byte(label), 0)
+	buf = append(buf, msg...)
+	uniform, err := ec.ExpandMessageXMD(buf, []byte(TextFieldDST), textFieldLen)
+	if err != nil {
+		return nil, err
+	}
+
+	return FieldElement(new(big.Int).SetBytes(uniform)), nil
+}
+
+// textField encodes the text in the version as an element of the
+// scalar field of BN254. In CircuitV1 it is the SHA-256 digest, which
+// the circuit silently reduces modulo the field, so that digests above
+// the modulus collide with their reduction. From CircuitV2 on it is
+// hash_to_field of the text, hence always canonical.
+func textField(version CircuitVersion, label string, text []byte) (*big.Int, error) {
+	switch version {
+	case CircuitV1:
+		digest := sha256.Sum256(text)
+		return FieldElement(new(big.Int).SetBytes(digest[:])), nil
+	case CircuitV2:
+		return hashToField(label, text)
+	}
+
+	return nil, version.check()
+}
+
+// ColumnsField returns the field element of the columns in the version.
+func ColumnsField(version CircuitVersion, columns []string) (*big.Int, error) {
+	return textField(version, "columns", []byte(strings.Join(columns, ",")))
+}
+
+// PrivateTextField returns the field element of the private text in the
+// version.
+func PrivateTextField(version CircuitVersion, privateText string) (*big.Int, error) {
+	return textField(version, "private", []byte(privateText))
+}
+
+// CommitDataField returns the field element of a commitment of
+// CommitSchemeP256 in the version. From CircuitV2 on, it encodes the
+// compressed point, not only its X coordinate.
+func CommitDataField(version CircuitVersion, commit *ec.Ec) (*big.Int, error) {
+	if commit == nil {
+		return nil, fmt.Errorf("missing commitment")
+	}
+	switch version {
+	case CircuitV1:
+		return FieldElement(commit.X), nil
+	case CircuitV2:
+		if !ec.P.IsOnCurve(commit.X, commit.Y) {
+			return nil, fmt.Errorf("commitment not on the curve")
+		}
+		return hashToField("commit", elliptic.MarshalCompressed(ec.P, commit.X, commit.Y))
+	}
+
+	return nil, version.check()
+}
+
+// CommitFieldElement checks that a commitment of CommitSchemeMiMC is a
+// canonical field element, as computed by the circuit.
+func CommitFieldElement(commit *big.Int) (*big.Int, error) {
+	if commit == nil {
+		return nil, fmt.Errorf("missing commitment")
+	}
+	if commit.Sign() < 0 || commit.Cmp(fr.Modulus()) >= 0 {
+		return nil, fmt.Errorf("commitment not in the field")
+	}
+
+	return commit, nil
+}
+
+// textFields returns the field elements of the columns, the commitment
+// to the data and the private text signed in s.
+func (s *SignatureZKP) textFields(columns []string, privateText string) ([]*big.Int, error) {
+	var commit *big.Int
+	var err error
+	switch s.Scheme {
+	case CommitSchemeP256:
+		commit, err = CommitDataField(s.CircuitVersion, s.CommitData)
+	case CommitSchemeMiMC:
+		commit, err = CommitFieldElement(s.CommitField)
+	default:
+		err = fmt.Errorf("unknown commitment scheme %d", s.Scheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	colsHash, err := ColumnsField(s.CircuitVersion, columns)
+	if err != nil {
+		return nil, err
+	}
+	secTextHash, err := PrivateTextField(s.CircuitVersion, privateText)
+	if err != nil {
+		return nil, err
+	}
+
+	return []*big.Int{colsHash, commit, secTextHash}, nil
+}
@@ -0,0 +1,92 @@
+package signature
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/krakenh2020/ZKPComponent/signature/ec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommitDataField(t *testing.T) {
+	// a commitment whose X coordinate is above the modulus
+	var commit *ec.Ec
+	for {
+		p, err := new(ec.Ec).Random()
+		assert.NoError(t, err)
+		if p.X.Cmp(fr.Modulus()) >= 0 {
+			commit = p
+			break
+		}
+	}
+	reduced := &ec.Ec{X: new(big.Int).Sub(commit.X, fr.Modulus()), Y: commit.Y}
+	neg := new(ec.Ec).Neg(commit)
+
+	// in CircuitV1 it collides with its reduction and its negation
+	f1, err := CommitDataField(CircuitV1, commit)
+	assert.NoError(t, err)
+	f2, err := CommitDataField(CircuitV1, reduced)
+	assert.NoError(t, err)
+	assert.Equal(t, f1, f2)
+	f2, err = CommitDataField(CircuitV1, neg)
+	assert.NoError(t, err)
+	assert.Equal(t, f1, f2)
+
+	// in CircuitV2 it is canonical
+	f1, err = CommitDataField(CircuitV2, commit)
+	assert.NoError(t, err)
+	assert.True(t, f1.Cmp(fr.Modulus()) < 0)
+	f2, err = CommitDataField(CircuitV2, neg)
+	assert.NoError(t, err)
+	assert.NotEqual(t, f1, f2)
+	_, err = CommitDataField(CircuitV2, reduced)
+	assert.Error(t, err)
+
+	_, err = CommitDataField(CircuitV2, nil)
+	assert.Error(t, err)
+	_, err = CommitDataField(CircuitVersion(7), commit)
+	assert.Error(t, err)
+}
+
+func TestCommitFieldElement(t *testing.T) {
+	r := fr.Modulus()
+	_, err := CommitFieldElement(new(big.Int).Sub(r, big.NewInt(1)))
+	assert.NoError(t, err)
+	for _, v := range []*big.Int{r, new(big.Int).Add(r, big.NewInt(1)), big.NewInt(-1), nil} {
+		_, err = CommitFieldElement(v)
+		assert.Error(t, err)
+	}
+}
+
+func TestTextField(t *testing.T) {
+	cols, err := ColumnsField(CircuitV2, []string{"a", "b"})
+	assert.NoError(t, err)
+	assert.True(t, cols.Cmp(fr.Modulus()) < 0)
+	// the values are separated
+	text, err := PrivateTextField(CircuitV2, "a,b")
+	assert.NoError(t, err)
+	assert.NotEqual(t, cols, text)
+
+	// CircuitV1 is the SHA-256 digest reduced modulo the field
+	text, err = PrivateTextField(CircuitV1, "a,b")
+	assert.NoError(t, err)
+	cols, err = ColumnsField(CircuitV1, []string{"a", "b"})
+	assert.NoError(t, err)
+	assert.Equal(t, cols, text)
+	textBytes, err := ColumnsCommitTextToBytes(CircuitV1, []string{"a", "b"}, new(ec.Ec).Gen(), "")
+	assert.NoError(t, err)
+	assert.Equal(t, cols.FillBytes(make([]byte, fr.Bytes)), textBytes[0])
+
+	// in CircuitV2 the commitment is the hash of the compressed point
+	textBytes, err = ColumnsCommitTextToBytes(CircuitV2, []string{"a", "b"}, new(ec.Ec).Gen(), "")
+	assert.NoError(t, err)
+	cols, err = ColumnsField(CircuitV2, []string{"a", "b"})
+	assert.NoError(t, err)
+	assert.Equal(t, cols.FillBytes(make([]byte, fr.Bytes)), textBytes[0])
+	commit, err := CommitDataField(CircuitV2, new(ec.Ec).Gen())
+	assert.NoError(t, err)
+	assert.Equal(t, commit.FillBytes(make([]byte, fr.Bytes)), textBytes[1])
+	_, err = ColumnsCommitTextToBytes(CircuitVersion(2), []string{"a", "b"}, new(ec.Ec).Gen(), "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,307 @@
+package signature
+
+import (
+	"context"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"runtime"
+	"strconv"
+	"sync"
+
+	"github.com/krakenh2020/ZKPComponent/signature/ec"
+)
+
+// GeneratorVersion identifies the derivation of the Pedersen
+// generators. It is recorded in SignatureZKP, so that the commitments
+// created with the legacy derivation still verify.
+type GeneratorVersion int
+
+const (
+	// GeneratorsLegacy derives h_i by try-and-increment over SHA-256
+	// of the zero-padded decimal index, see ec.HashIntoCurvePoint.
+	GeneratorsLegacy GeneratorVersion = 0
+	// GeneratorsHashToCurve derives h_i with the RFC 9380 suite
+	// P256_XMD:SHA-256_SSWU_RO_ applied to the 8 bytes big-endian
+	// index, with the domain separation tag GeneratorDST.
+	GeneratorsHashToCurve GeneratorVersion = 1
+
+	// CurrentGeneratorVersion is used for new signatures.
+	CurrentGeneratorVersion = GeneratorsHashToCurve
+)
+
+// GeneratorDST is the domain separation tag of the RFC 9380 generator
+// derivation.
+const GeneratorDST = "KRAKEN-ZKPComponent-V01-CS01-with-P256_XMD:SHA-256_SSWU_RO_"
+
+func (v GeneratorVersion) check() error {
+	if v != GeneratorsLegacy && v != GeneratorsHashToCurve {
+		return fmt.Errorf("unknown generator version %d", v)
+	}
+
+	return nil
+}
+
+// GeneratorTable derives the Pedersen generators h_0, h_1, ... used to
+// commit to the dataset vectors and caches them, since hashing into
+// the curve is as costly as the commitment itself. Optionally it holds
+// precomputed multiples of the generators for fixed-base
+// multiplication. It is safe for concurrent use.
+type GeneratorTable struct {
+	version GeneratorVersion
+	mu      sync.RWMutex
+	h       []*ec.Ec
+	fixed   *ec.FixedBaseTable
+}
+
+func NewGeneratorTable(version GeneratorVersion) (*GeneratorTable, error) {
+	err := version.check()
+	if err != nil {
+		return nil, err
+	}
+
+	return &GeneratorTable{version: version}, nil
+}
+
+func (g *GeneratorTable) Version() GeneratorVersion {
+	return g.version
+}
+
+var (
+	defaultGeneratorsMu sync.RWMutex
+	defaultGenerators   = map[GeneratorVersion]*GeneratorTable{
+		GeneratorsLegacy:      {version: GeneratorsLegacy},
+		GeneratorsHashToCurve: {version: GeneratorsHashToCurve},
+	}
+)
+
+// DefaultGenerators returns the table used by the commitment functions
+// for the version.
+func DefaultGenerators(version GeneratorVersion) (*GeneratorTable, error) {
+	err := version.check()
+	if err != nil {
+		return nil, err
+	}
+	defaultGeneratorsMu.RLock()
+	defer defaultGeneratorsMu.RUnlock()
+
+	return defaultGenerators[version], nil
+}
+
+// SetDefaultGenerators replaces the table used by the commitment
+// functions for the version of g, for example with a table loaded
+// from disk. The commitments computed concurrently use either table.
+func SetDefaultGenerators(g *GeneratorTable) {
+	defaultGeneratorsMu.Lock()
+	defer defaultGeneratorsMu.Unlock()
+
+	defaultGenerators[g.version] = g
+}
+
+func deriveGenerator(version GeneratorVersion, i int) *ec.Ec {
+	if version == GeneratorsLegacy {
+		return ec.HashIntoCurvePoint([]byte(strconv.Itoa(i)))
+	}
+
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(i))
+	h, err := ec.HashToCurve(msg, []byte(GeneratorDST))
+	if err != nil {
+		// the parameters of the hash are constant
+		panic(err)
+	}
+
+	return h
+}
+
+// Generators returns the first n generators. The returned points are
+// shared and must not be modified.
+func (g *GeneratorTable) Generators(n int) []*ec.Ec {
+	g.mu.RLock()
+	if n <= len(g.h) {
+		res := g.h[:n:n]
+		g.mu.RUnlock()
+		return res
+	}
+	g.mu.RUnlock()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if n > len(g.h) {
+		start := len(g.h)
+		h := make([]*ec.Ec, n)
+		copy(h, g.h)
+		deriveGenerators(g.version, h[start:], start)
+		g.h = h
+	}
+
+	return g.h[:n:n]
+}
+
+// deriveGenerators sets h[i] to the generator start+i, in parallel.
+func deriveGenerators(version GeneratorVersion, h []*ec.Ec, start int) {
+	workers := runtime.GOMAXPROCS(0)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < len(h); i += workers {
+				h[i] = deriveGenerator(version, start+i)
+			}
+		}(w)
+	}
+	wg.Wait()
+}
+
+// Range returns the generators h_start, ..., h_{end-1}. The generators
+// not in the cache are derived without being added to it, so that
+// commitments computed block by block use bounded memory.
+func (g *GeneratorTable) Range(start, end int) []*ec.Ec {
+	res := make([]*ec.Ec, end-start)
+	g.mu.RLock()
+	cached := 0
+	if start < len(g.h) {
+		cached = copy(res, g.h[start:])
+	}
+	g.mu.RUnlock()
+	deriveGenerators(g.version, res[cached:], start+cached)
+
+	return res
+}
+
+// Precompute derives the first n generators and the multiples for
+// fixed-base multiplication, used by commitments of vectors of length
+// at most n.
+func (g *GeneratorTable) Precompute(n int) {
+	h := g.Generators(n)
+	fixed := ec.NewFixedBaseTable(append(h, new(ec.Ec).Gen()))
+
+	g.mu.Lock()
+	if g.fixed == nil || g.fixed.Len() < fixed.Len() {
+		g.fixed = fixed
+	}
+	g.mu.Unlock()
+}
+
+// commit returns r G + sum_i vec[i] h_i, using the precomputed
+// multiples if available. It is not constant time, see commitConstTime.
+func (g *GeneratorTable) commit(ctx context.Context, vec []*big.Int, r *big.Int) (*ec.Ec, error) {
+	g.mu.RLock()
+	fixed := g.fixed
+	g.mu.RUnlock()
+
+	// the multiples of G are stored after the generators
+	if fixed != nil && fixed.Len() == len(vec)+1 {
+		return fixed.MultiScalarMult(ctx, append(vec[:len(vec):len(vec)], r), 0)
+	}
+	if fixed != nil && fixed.Len() > len(vec)+1 {
+		res, err := fixed.MultiScalarMult(ctx, vec, 0)
+		if err != nil {
+			return nil, err
+		}
+		return res.Add(res, new(ec.Ec).ScalarBaseMult(r)), nil
+	}
+
+	h := g.Generators(len(vec))
+
+	return ec.MultiScalarMult(ctx, append(h, new(ec.Ec).Gen()), append(vec[:len(vec):len(vec)], r), 0)
+}
+
+// commitConstTime is commit with constant time scalar multiplications,
+// for the secret values and randomness of the data owner.
+func (g *GeneratorTable) commitConstTime(ctx context.Context, vec []*big.Int, r *big.Int) (*ec.Ec, error) {
+	h := g.Generators(len(vec))
+
+	return ec.MultiScalarMultConstTime(ctx, append(h, new(ec.Ec).Gen()), append(vec[:len(vec):len(vec)], r), 0)
+}
+
+// generatorFile is the format of the files of the generator tables.
+type generatorFile struct {
+	Version    GeneratorVersion
+	Generators []*ec.Ec
+}
+
+// Save writes the generators to file. The precomputed multiples are
+// not saved.
+func (g *GeneratorTable) Save(file string) error {
+	g.mu.RLock()
+	data, err := json.Marshal(&generatorFile{Version: g.version, Generators: g.h})
+	g.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(file, data, 0644)
+}
+
+// Digest returns the hex SHA-256 digest of the version and of the
+// generators derived so far, which binds a table saved with Save.
+func (g *GeneratorTable) Digest() string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return generatorsDigest(g.version, g.h)
+}
+
+func generatorsDigest(version GeneratorVersion, h []*ec.Ec) string {
+	d := sha256.New()
+	d.Write([]byte(GeneratorDST))
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(version))
+	d.Write(buf[:])
+	binary.BigEndian.PutUint64(buf[:], uint64(len(h)))
+	d.Write(buf[:])
+	for _, e := range h {
+		d.Write(elliptic.Marshal(ec.P, e.X, e.Y))
+	}
+
+	return hex.EncodeToString(d.Sum(nil))
+}
+
+// LoadGeneratorTable reads generators written by Save. If digest is
+// not empty, the generators must have this Digest, obtained from a
+// trusted source. Otherwise every generator is derived again and
+// compared, which costs as much as deriving the table.
+func LoadGeneratorTable(file, digest string) (*GeneratorTable, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var gf generatorFile
+	err = json.Unmarshal(data, &gf)
+	if err != nil {
+		return nil, err
+	}
+	err = gf.Version.check()
+	if err != nil {
+		return nil, err
+	}
+	h := gf.Generators
+
+	for i, e := range h {
+		if e == nil || e.X == nil || e.Y == nil || !ec.P.IsOnCurve(e.X, e.Y) {
+			return nil, fmt.Errorf("generator %d not on the curve", i)
+		}
+	}
+	if digest != "" {
+		if generatorsDigest(gf.Version, h) != digest {
+			return nil, fmt.Errorf("digest of the generators does not match")
+		}
+	} else {
+		expected := make([]*ec.Ec, len(h))
+		deriveGenerators(gf.Version, expected, 0)
+		for i := range h {
+			if !h[i].Equal(expected[i]) {
+				return nil, fmt.Errorf("generator %d not derived as expected", i)
+			}
+		}
+	}
+
+	return &GeneratorTable{version: gf.Version, h: h}, nil
+}
@@ -0,0 +1,186 @@
+package signature
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/krakenh2020/ZKPComponent/data_common"
+	"github.com/krakenh2020/ZKPComponent/signature/ec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeneratorTable(t *testing.T) {
+	g, err := NewGeneratorTable(CurrentGeneratorVersion)
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 8; i++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			h := g.Generators(n)
+			assert.Equal(t, n, len(h))
+		}(10 * i)
+	}
+	wg.Wait()
+
+	h := g.Generators(80)
+	for i := range h {
+		assert.True(t, h[i].Equal(deriveGenerator(CurrentGeneratorVersion, i)))
+	}
+
+	file := filepath.Join(t.TempDir(), "generators.json")
+	assert.NoError(t, g.Save(file))
+	for _, digest := range []string{"", g.Digest()} {
+		g2, err := LoadGeneratorTable(file, digest)
+		assert.NoError(t, err)
+		assert.Equal(t, g.Digest(), g2.Digest())
+		h2 := g2.Generators(100)
+		for i := range h2 {
+			assert.True(t, h2[i].Equal(deriveGenerator(CurrentGeneratorVersion, i)))
+		}
+	}
+
+	// a table with a wrong generator in the middle is refused
+	g3, err := NewGeneratorTable(CurrentGeneratorVersion)
+	assert.NoError(t, err)
+	g3.h = append([]*ec.Ec{}, g.Generators(80)...)
+	g3.h[40] = deriveGenerator(CurrentGeneratorVersion, 100)
+	assert.NoError(t, g3.Save(file))
+	_, err = LoadGeneratorTable(file, "")
+	assert.Error(t, err)
+	_, err = LoadGeneratorTable(file, g.Digest())
+	assert.Error(t, err)
+	// unless its own digest is trusted
+	_, err = LoadGeneratorTable(file, g3.Digest())
+	assert.NoError(t, err)
+
+	// as well as a table of another version
+	g3.h = g.Generators(80)
+	g3.version = GeneratorsLegacy
+	assert.NoError(t, g3.Save(file))
+	_, err = LoadGeneratorTable(file, "")
+	assert.Error(t, err)
+	_, err = LoadGeneratorTable(file, g.Digest())
+	assert.Error(t, err)
+
+	assert.NoError(t, os.WriteFile(file, []byte(`{"Version":1,"Generators":[{"X":1,"Y":2}]}`), 0644))
+	_, err = LoadGeneratorTable(file, "")
+	assert.Error(t, err)
+
+	_, err = NewGeneratorTable(2)
+	assert.Error(t, err)
+}
+
+func TestGeneratorVersions(t *testing.T) {
+	v, err := data_common.NewUniformRangeRandomVector(100, new(big.Int).Neg(data_common.MPCPrimeHalf), data_common.MPCPrimeHalf)
+	assert.NoError(t, err)
+
+	hLegacy, r, err := CommmitDatasetContext(context.Background(), GeneratorsLegacy, v, nil)
+	assert.NoError(t, err)
+	h, _, err := CommmitDatasetContext(context.Background(), GeneratorsHashToCurve, v, r)
+	assert.NoError(t, err)
+	assert.False(t, h.Equal(hLegacy))
+
+	for _, version := range []GeneratorVersion{GeneratorsLegacy, GeneratorsHashToCurve} {
+		split, err := CreateSharesShamirSpecial(v, r)
+		assert.NoError(t, err)
+		hSplit := make([]*ec.Ec, 3)
+		for i := 0; i < 3; i++ {
+			hSplit[i], err = CommitShareSpecialContext(context.Background(), version, split[i])
+			assert.NoError(t, err)
+		}
+		hCheck, err := JoinCommits(hSplit)
+		assert.NoError(t, err)
+		if version == GeneratorsLegacy {
+			assert.True(t, hCheck.Equal(hLegacy))
+		} else {
+			assert.True(t, hCheck.Equal(h))
+		}
+	}
+
+	_, _, err = CommmitDatasetContext(context.Background(), 5, v, r)
+	assert.Error(t, err)
+}
+
+func TestGeneratorTablePrecompute(t *testing.T) {
+	v, err := data_common.NewUniformRangeRandomVector(500, new(big.Int).Neg(data_common.MPCPrimeHalf), data_common.MPCPrimeHalf)
+	assert.NoError(t, err)
+	r := big.NewInt(7)
+	expected := commitDatasetSequential(v, r)
+
+	g, err := NewGeneratorTable(CurrentGeneratorVersion)
+	assert.NoError(t, err)
+	g.Precompute(500)
+	for _, n := range []int{500, 200} {
+		res, err := g.commit(context.Background(), v[:n], r)
+		assert.NoError(t, err)
+		if n == 500 {
+			assert.True(t, res.Equal(expected))
+		} else {
+			assert.True(t, res.Equal(commitDatasetSequential(v[:n], r)))
+		}
+	}
+}
+
+func BenchmarkCommmitDatasetPrecomputed(b *testing.B) {
+	v, err := data_common.NewUniformRangeRandomVector(10000, new(big.Int).Neg(data_common.MPCPrimeHalf), data_common.MPCPrimeHalf)
+	if err != nil {
+		b.Fatal(err)
+	}
+	g, err := NewGeneratorTable(CurrentGeneratorVersion)
+	if err != nil {
+		b.Fatal(err)
+	}
+	g.Precompute(len(v))
+	r := big.NewInt(42)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := g.commit(context.Background(), v, r)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestGeneratorTableRange(t *testing.T) {
+	g, err := NewGeneratorTable(CurrentGeneratorVersion)
+	assert.NoError(t, err)
+	g.Generators(10)
+
+	h := g.Range(5, 20)
+	assert.Equal(t, 15, len(h))
+	for i := range h {
+		assert.True(t, h[i].Equal(deriveGenerator(CurrentGeneratorVersion, 5+i)))
+	}
+	// the generators out of the cache are not added to it
+	assert.Equal(t, 10, len(g.h))
+}
+
+func TestSetDefaultGenerators(t *testing.T) {
+	old, err := DefaultGenerators(CurrentGeneratorVersion)
+	assert.NoError(t, err)
+	defer SetDefaultGenerators(old)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			g, err := NewGeneratorTable(CurrentGeneratorVersion)
+			assert.NoError(t, err)
+			SetDefaultGenerators(g)
+		}()
+		go func() {
+			defer wg.Done()
+			g, err := DefaultGenerators(CurrentGeneratorVersion)
+			assert.NoError(t, err)
+			assert.Equal(t, CurrentGeneratorVersion, g.Version())
+		}()
+	}
+	wg.Wait()
+}
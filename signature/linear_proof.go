@@ -0,0 +1,193 @@
+package signature
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+
+	"github.com/krakenh2020/ZKPComponent/data_common"
+	"github.com/krakenh2020/ZKPComponent/signature/ec"
+)
+
+// LinearProof is a zero-knowledge proof by an MPC node that Y is the
+// result of a public linear function w applied to the share committed
+// in the node's commit (see CommitShareSpecial): the node knows v and
+// r such that commit = r G + sum_j v_j h_j and Y = <w, v> mod N.
+type LinearProof struct {
+	Y   *big.Int
+	A   *ec.Ec
+	Tau *big.Int
+	Z   []*big.Int
+	ZR  *big.Int
+}
+
+// LiftShareSpecial returns the vector committed by CommitShareSpecial
+// for a share created by CreateSharesShamirSpecial, together with the
+// share of the randomness of the commitment. The lifted shares of all
+// the nodes join to the dataset over the integers modulo N.
+func LiftShareSpecial(split []*big.Int) ([]*big.Int, *big.Int) {
+	n := (len(split) - 1) / 2
+	v := make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		v[i] = new(big.Int).Mul(split[i+n], data_common.MPCPrime)
+		v[i].Add(v[i], split[i])
+		v[i].Mod(v[i], ec.P.Params().N)
+	}
+
+	return v, split[2*n]
+}
+
+// ColumnSumWeights returns the weights of the linear function summing
+// column col of a dataset of length n with nCols columns.
+func ColumnSumWeights(n, nCols, col int) []*big.Int {
+	w := make([]*big.Int, n)
+	for i := range w {
+		if i%nCols == col {
+			w[i] = big.NewInt(1)
+		} else {
+			w[i] = big.NewInt(0)
+		}
+	}
+
+	return w
+}
+
+func innerProductN(w, v []*big.Int) *big.Int {
+	res := new(big.Int)
+	tmp := new(big.Int)
+	for i := range w {
+		res.Add(res, tmp.Mul(w[i], v[i]))
+	}
+
+	return res.Mod(res, ec.P.Params().N)
+}
+
+func linearChallenge(commit *ec.Ec, p *LinearProof, w []*big.Int) *big.Int {
+	hashSha := sha256.New()
+	for _, e := range []*big.Int{commit.X, commit.Y, p.A.X, p.A.Y, p.Tau, p.Y} {
+		hashSha.Write(e.Bytes())
+		hashSha.Write([]byte{0})
+	}
+	for _, e := range w {
+		hashSha.Write(e.Bytes())
+		hashSha.Write([]byte{0})
+	}
+	e := new(big.Int).SetBytes(hashSha.Sum(nil))
+
+	return e.Mod(e, ec.P.Params().N)
+}
+
+// ProveLinearShare creates a LinearProof for the share split of a node
+// and its commit, using a Schnorr-like protocol made non-interactive
+// with the Fiat-Shamir heuristic. The version is the one of the
+// generators of the commit, see SignatureZKP.
+func ProveLinearShare(split []*big.Int, commit *ec.Ec, w []*big.Int, version GeneratorVersion) (*LinearProof, error) {
+	v, r := LiftShareSpecial(split)
+	if len(w) != len(v) {
+		return nil, fmt.Errorf("weights and share of different length")
+	}
+	order := ec.P.Params().N
+
+	t, err := data_common.NewUniformRandomVector(len(v), order)
+	if err != nil {
+		return nil, err
+	}
+	s, err := rand.Int(rand.Reader, order)
+	if err != nil {
+		return nil, err
+	}
+
+	h, err := pedersenGenerators(version, len(v))
+	if err != nil {
+		return nil, err
+	}
+	// t and s hide v, hence they are secret
+	a, err := ec.MultiScalarMultConstTime(context.Background(), append(h, new(ec.Ec).Gen()), append(t, s), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	proof := &LinearProof{Y: innerProductN(w, v), A: a, Tau: innerProductN(w, t)}
+	e := linearChallenge(commit, proof, w)
+
+	proof.Z = make([]*big.Int, len(v))
+	for i := range v {
+		proof.Z[i] = new(big.Int).Mul(e, v[i])
+		proof.Z[i].Add(proof.Z[i], t[i])
+		proof.Z[i].Mod(proof.Z[i], order)
+	}
+	proof.ZR = new(big.Int).Mul(e, r)
+	proof.ZR.Add(proof.ZR, s)
+	proof.ZR.Mod(proof.ZR, order)
+
+	return proof, nil
+}
+
+// VerifyLinearShare verifies a LinearProof against the commit of the
+// node's share, made with the generators of the version, and the
+// weights w.
+func VerifyLinearShare(proof *LinearProof, commit *ec.Ec, w []*big.Int, version GeneratorVersion) (bool, error) {
+	if len(proof.Z) != len(w) {
+		return false, fmt.Errorf("weights and proof of different length")
+	}
+	e := linearChallenge(commit, proof, w)
+
+	// zR G + sum_j z_j h_j = A + e commit
+	h, err := pedersenGenerators(version, len(w))
+	if err != nil {
+		return false, err
+	}
+	lhs, err := ec.MultiScalarMult(context.Background(), append(h, new(ec.Ec).Gen()),
+		append(proof.Z[:len(proof.Z):len(proof.Z)], proof.ZR), 0)
+	if err != nil {
+		return false, err
+	}
+	rhs := new(ec.Ec).ScalarMult(commit, e)
+	rhs.Add(rhs, proof.A)
+	if lhs.Equal(rhs) == false {
+		return false, fmt.Errorf("linear proof does not match the commit")
+	}
+
+	// <w, z> = tau + e Y
+	check := new(big.Int).Mul(e, proof.Y)
+	check.Add(check, proof.Tau)
+	check.Mod(check, ec.P.Params().N)
+	if innerProductN(w, proof.Z).Cmp(check) != 0 {
+		return false, fmt.Errorf("linear proof does not match the result")
+	}
+
+	return true, nil
+}
+
+// JoinLinearResults joins the results Y of the LinearProofs of the
+// three nodes, checking their consistency as in JoinCommits. The
+// result is given as a signed value.
+func JoinLinearResults(ys []*big.Int) (*big.Int, error) {
+	order := ec.P.Params().N
+
+	res := new(big.Int).Mul(ys[0], big.NewInt(2))
+	res.Sub(res, ys[1])
+	res.Mod(res, order)
+
+	check1 := new(big.Int).Mul(ys[1], big.NewInt(3))
+	check1.Sub(check1, new(big.Int).Mul(ys[2], big.NewInt(2)))
+	check1.Mod(check1, order)
+	if check1.Cmp(res) != 0 {
+		return nil, fmt.Errorf("linear results do not match")
+	}
+
+	check2 := new(big.Int).Mul(ys[0], big.NewInt(3))
+	check2.Sub(check2, ys[2])
+	check2.Mod(check2, order)
+	if check2.Cmp(new(big.Int).Mod(new(big.Int).Lsh(res, 1), order)) != 0 {
+		return nil, fmt.Errorf("linear results do not match")
+	}
+
+	if res.Cmp(new(big.Int).Rsh(order, 1)) > 0 {
+		res.Sub(res, order)
+	}
+
+	return res, nil
+}
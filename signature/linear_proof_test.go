@@ -0,0 +1,67 @@
+package signature
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/krakenh2020/ZKPComponent/data_common"
+	"github.com/krakenh2020/ZKPComponent/signature/ec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinearProof(t *testing.T) {
+	vec, cols, _, _, _, err := CsvToVecAuth("../datasets/framingham_tiny.csv")
+	assert.NoError(t, err)
+
+	commit, r, err := CommmitDataset(vec, nil)
+	assert.NoError(t, err)
+	split, err := CreateSharesShamirSpecial(vec, r)
+	assert.NoError(t, err)
+
+	commits := make([]*ec.Ec, 3)
+	for i := 0; i < 3; i++ {
+		commits[i] = CommitShareSpecial(split[i])
+	}
+	joined, err := JoinCommits(commits)
+	assert.NoError(t, err)
+	assert.True(t, joined.Equal(commit))
+
+	col := 10
+	w := ColumnSumWeights(len(vec), len(cols), col)
+	ys := make([]*big.Int, 3)
+	for i := 0; i < 3; i++ {
+		proof, err := ProveLinearShare(split[i], commits[i], w, CurrentGeneratorVersion)
+		assert.NoError(t, err)
+		check, err := VerifyLinearShare(proof, commits[i], w, CurrentGeneratorVersion)
+		assert.NoError(t, err)
+		assert.True(t, check)
+		ys[i] = proof.Y
+
+		// the proof does not verify for another node or another function
+		_, err = VerifyLinearShare(proof, commits[(i+1)%3], w, CurrentGeneratorVersion)
+		assert.Error(t, err)
+		_, err = VerifyLinearShare(proof, commits[i], ColumnSumWeights(len(vec), len(cols), col+1), CurrentGeneratorVersion)
+		assert.Error(t, err)
+	}
+
+	y, err := JoinLinearResults(ys)
+	assert.NoError(t, err)
+	sum := new(big.Int)
+	for i := col; i < len(vec); i += len(cols) {
+		sum.Add(sum, vec[i])
+	}
+	assert.Equal(t, 0, sum.Cmp(y))
+
+	// the sum computed by the MPC nodes on the first half of the shares
+	mpcShares := make([][]*big.Int, 3)
+	for i := 0; i < 3; i++ {
+		s := new(big.Int)
+		for j := col; j < len(vec); j += len(cols) {
+			s.Add(s, split[i][j])
+		}
+		mpcShares[i] = []*big.Int{s.Mod(s, data_common.MPCPrime)}
+	}
+	mpcSum, err := data_common.JoinSharesShamir(mpcShares)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, mpcSum[0].Cmp(y))
+}
@@ -1,13 +1,17 @@
 package signature
 
 import (
+	"bytes"
+	"context"
 	"crypto/rand"
 
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
 	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
 	"github.com/krakenh2020/ZKPComponent/data_common"
 	"github.com/krakenh2020/ZKPComponent/signature/ec"
 
 	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"math/big"
@@ -15,7 +19,6 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/consensys/gnark-crypto/hash"
 	"github.com/consensys/gnark-crypto/signature"
 )
 
@@ -41,8 +44,29 @@ type SignatureZKP struct {
 	CommitData *ec.Ec
 	RData      *big.Int
 	PubKey     []byte
+	// GenVersion is the version of the generators of CommitData
+	GenVersion GeneratorVersion `json:",omitempty"`
+	// Scheme is the commitment to the data, CommitData for
+	// CommitSchemeP256 or CommitField for CommitSchemeMiMC
+	Scheme      CommitScheme `json:",omitempty"`
+	CommitField *big.Int     `json:",omitempty"`
+	// CircuitVersion is the hash of Commit and Sig, see CircuitVersion
+	CircuitVersion CircuitVersion `json:",omitempty"`
+	// SigScheme is the scheme of Sig and PubKey, signing Commit.C.X
+	SigScheme SignatureScheme `json:",omitempty"`
 }
 
+// SignOptions are the choices of the data owner when signing a dataset.
+type SignOptions struct {
+	Generators GeneratorVersion
+	Scheme     CommitScheme
+	Circuit    CircuitVersion
+}
+
+// DefaultSignOptions are the options of SignCsv.
+var DefaultSignOptions = SignOptions{Generators: CurrentGeneratorVersion, Scheme: CommitSchemeP256,
+	Circuit: CurrentCircuitVersion}
+
 func ParsePoint(buf []byte) twistededwards.PointAffine {
 	var pointbn254 twistededwards.PointAffine
 	pointbn254.SetBytes(buf[:32])
@@ -72,15 +96,26 @@ func PedersenCommit(x *big.Int, rr *big.Int) (*PedCommit, error) {
 		}
 	}
 
-	gToX := new(twistededwards.PointAffine).ScalarMul(g, x)
-	hToR := new(twistededwards.PointAffine).ScalarMul(h, r)
+	gToX := new(twistededwards.PointAffine).ScalarMultiplication(g, x)
+	hToR := new(twistededwards.PointAffine).ScalarMultiplication(h, r)
 	c := new(twistededwards.PointAffine).Add(gToX, hToR)
 
 	return &PedCommit{R: r, C: c}, nil
 }
 
+// MiMCPedersen is MiMCPedersenVersion of CircuitV1, to verify the
+// signatures made before CircuitV2.
 func MiMCPedersen(text [][]byte, rr *big.Int) (*PedCommit, error) {
-	hs := hash.MIMC_BN254.New() // todo add seed to struct
+	return MiMCPedersenVersion(CircuitV1, text, rr)
+}
+
+// MiMCPedersenVersion is MiMCPedersen with the hash of the circuit
+// version.
+func MiMCPedersenVersion(version CircuitVersion, text [][]byte, rr *big.Int) (*PedCommit, error) {
+	hs, err := version.NewHash()
+	if err != nil {
+		return nil, err
+	}
 	for i := 0; i < len(text); i++ {
 		hs.Write(text[i])
 	}
@@ -153,36 +188,30 @@ func CsvToVecAuth(file string) ([]*big.Int, []string, []float64, string, []byte,
 	return vec, cols, vecFloat, addText, sig, err
 }
 
-func ColumnsCommitTextToBytes(columns []string, commit *ec.Ec, privateText string) ([][]byte, error) {
-	textBytes := make([][]byte, 0)
+// ColumnsCommitTextToBytes returns the signed text of the circuit version
+// for a commitment of CommitSchemeP256, as hashed by MiMCPedersenVersion.
+func ColumnsCommitTextToBytes(version CircuitVersion, columns []string, commit *ec.Ec, privateText string) ([][]byte, error) {
+	s := &SignatureZKP{CommitData: commit, Scheme: CommitSchemeP256, CircuitVersion: version}
 
-	var valBytes []byte
-	hashSha := sha256.New()
-	_, err := hashSha.Write([]byte(strings.Join(columns, ",")))
-	if err != nil {
-		return nil, err
-	}
-	columnsHash := hashSha.Sum(nil)
-	textBytes = append(textBytes, columnsHash)
-
-	commitBytes := commit.XBytes()
-	valBytes = make([]byte, 32)
-	copy(valBytes[32-len(commitBytes):], commitBytes)
-	textBytes = append(textBytes, valBytes)
+	return s.columnsTextToBytes(columns, privateText)
+}
 
-	hashSha.Reset()
-	_, err = hashSha.Write([]byte(privateText))
-	if err != nil {
-		return nil, err
-	}
-	privateTextHash := hashSha.Sum(nil)
+func SignCsv(file string, signer signature.Signer) (*SignatureZKP, error) {
+	return SignCsvWithOptions(file, signer, DefaultSignOptions)
+}
 
-	textBytes = append(textBytes, privateTextHash)
+// SignCsvVersion is SignCsv committing to the data with the generators
+// of the version.
+func SignCsvVersion(file string, signer signature.Signer, version GeneratorVersion) (*SignatureZKP, error) {
+	opts := DefaultSignOptions
+	opts.Generators = version
 
-	return textBytes, nil
+	return SignCsvWithOptions(file, signer, opts)
 }
 
-func SignCsv(file string, signer signature.Signer) (*SignatureZKP, error) {
+// SignCsvWithOptions is SignCsv with the commitment scheme and the
+// generators of the options.
+func SignCsvWithOptions(file string, signer signature.Signer, opts SignOptions) (*SignatureZKP, error) {
 	vec, cols, _, privateText, sigTest, err := CsvToVecAuth(file)
 	if err != nil {
 		return nil, err
@@ -191,30 +220,67 @@ func SignCsv(file string, signer signature.Signer) (*SignatureZKP, error) {
 		return nil, fmt.Errorf("data already signed")
 	}
 
-	commit, r, err := CommmitDataset(vec, nil)
+	sigScheme, err := SchemeOf(signer.Public())
 	if err != nil {
 		return nil, err
 	}
+	if sigScheme != SignatureSchemeEdDSABN254 && opts.Scheme != CommitSchemeP256 {
+		return nil, fmt.Errorf("signature scheme %s not supported with commitment scheme %d", sigScheme, opts.Scheme)
+	}
 
-	textBytes, err := ColumnsCommitTextToBytes(cols, commit, privateText)
+	sign := &SignatureZKP{Scheme: opts.Scheme, CircuitVersion: opts.Circuit, SigScheme: sigScheme}
+	hFunc, err := opts.Circuit.NewHash()
+	if err != nil {
+		return nil, err
+	}
+	switch opts.Scheme {
+	case CommitSchemeP256:
+		sign.CommitData, sign.RData, err = CommmitDatasetContext(context.Background(), opts.Generators, vec, nil)
+		sign.GenVersion = opts.Generators
+	case CommitSchemeMiMC:
+		sign.CommitField, sign.RData, err = CommitDatasetMiMC(opts.Circuit, vec, nil)
+	default:
+		err = fmt.Errorf("unknown commitment scheme %d", opts.Scheme)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	mimcPed, err := MiMCPedersen(textBytes, nil)
+	textBytes, err := sign.columnsTextToBytes(cols, privateText)
 	if err != nil {
 		return nil, err
 	}
 
-	hFunc := hash.MIMC_BN254.New()
+	mimcPed, err := MiMCPedersenVersion(opts.Circuit, textBytes, nil)
+	if err != nil {
+		return nil, err
+	}
 
 	hashed := mimcPed.C.X.Bytes()
-	sign, err := signer.Sign(hashed[:], hFunc)
+	sign.Sig, err = signer.Sign(hashed[:], hFunc)
+	if err != nil {
+		return nil, err
+	}
+	sign.Commit = *mimcPed
+	sign.PubKey = signer.Public().Bytes()
+
+	return sign, nil
+}
+
+// columnsTextToBytes returns the field elements of the signed text as
+// the 32-byte blocks hashed by MiMCPedersenVersion.
+func (s *SignatureZKP) columnsTextToBytes(columns []string, privateText string) ([][]byte, error) {
+	fields, err := s.textFields(columns, privateText)
 	if err != nil {
 		return nil, err
 	}
 
-	return &SignatureZKP{Sig: sign, Commit: *mimcPed, CommitData: commit, RData: r, PubKey: signer.Public().Bytes()}, nil
+	textBytes := make([][]byte, len(fields))
+	for i, e := range fields {
+		textBytes[i] = e.FillBytes(make([]byte, fr.Bytes))
+	}
+
+	return textBytes, nil
 }
 
 func WriteSignCsv(fileInput, fileOutput string, s *SignatureZKP) error {
@@ -269,28 +335,76 @@ func VerifyCsv(file string, pubKey signature.PublicKey) (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	for i, e := range pubKey.Bytes() {
-		if sign.PubKey[i] != e {
-			return false, fmt.Errorf("public keys do not match")
-		}
+	sigScheme, err := SchemeOf(pubKey)
+	if err != nil {
+		return false, err
+	}
+	if sigScheme != sign.SigScheme || !bytes.Equal(pubKey.Bytes(), sign.PubKey) {
+		return false, fmt.Errorf("public keys do not match")
 	}
 
-	commit, _, err := CommmitDataset(vec, sign.RData)
-
-	if commit.Equal(sign.CommitData) == false {
-		return false, fmt.Errorf("commit value and data do not match")
+	if sign.RData == nil {
+		return false, fmt.Errorf("missing commitment randomness")
+	}
+	switch sign.Scheme {
+	case CommitSchemeP256:
+		commit, _, err := CommmitDatasetContext(context.Background(), sign.GenVersion, vec, sign.RData)
+		if err != nil {
+			return false, err
+		}
+		if sign.CommitData == nil || commit.Equal(sign.CommitData) == false {
+			return false, fmt.Errorf("commit value and data do not match")
+		}
+	case CommitSchemeMiMC:
+		commit, _, err := CommitDatasetMiMC(sign.CircuitVersion, vec, sign.RData)
+		if err != nil {
+			return false, err
+		}
+		if sign.CommitField == nil || commit.Cmp(sign.CommitField) != 0 {
+			return false, fmt.Errorf("commit value and data do not match")
+		}
+	default:
+		return false, fmt.Errorf("unknown commitment scheme %d", sign.Scheme)
 	}
 
-	textBytes, err := ColumnsCommitTextToBytes(cols, commit, privateText)
+	textBytes, err := sign.columnsTextToBytes(cols, privateText)
+	if err != nil {
+		return false, err
+	}
 
-	mimcPed, err := MiMCPedersen(textBytes, sign.Commit.R)
+	mimcPed, err := MiMCPedersenVersion(sign.CircuitVersion, textBytes, sign.Commit.R)
 	if err != nil {
 		return false, err
 	}
 
-	hFunc := hash.MIMC_BN254.New()
+	hFunc, err := sign.CircuitVersion.NewHash()
+	if err != nil {
+		return false, err
+	}
 	hashed := mimcPed.C.X.Bytes()
 	check, err := pubKey.Verify(sign.Sig, hashed[:], hFunc)
 
 	return check, err
 }
+
+// DatasetId returns an identifier of the signed dataset, derived from
+// the commitment to the data and the public key of the owner.
+func DatasetId(s *SignatureZKP) string {
+	hashSha := sha256.New()
+	if s.Scheme == CommitSchemeMiMC {
+		// prefixed to differ from the identifiers of CommitSchemeP256
+		hashSha.Write([]byte("mimc"))
+		hashSha.Write([]byte{0})
+		if s.CommitField != nil {
+			hashSha.Write(s.CommitField.Bytes())
+		}
+	} else if s.CommitData != nil {
+		hashSha.Write(s.CommitData.X.Bytes())
+		hashSha.Write([]byte{0})
+		hashSha.Write(s.CommitData.Y.Bytes())
+	}
+	hashSha.Write([]byte{0})
+	hashSha.Write(s.PubKey)
+
+	return hex.EncodeToString(hashSha.Sum(nil))
+}
@@ -1,7 +1,11 @@
 package signature
 
 import (
+	"crypto/ecdsa"
 	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+	"path/filepath"
 	"testing"
 
 	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
@@ -10,8 +14,7 @@ import (
 )
 
 func TestSignCsv(t *testing.T) {
-	signature.Register(signature.EDDSA_BN254, eddsa.GenerateKeyInterfaces)
-	signer, err := signature.EDDSA_BN254.New(rand.Reader)
+	signer, err := eddsa.GenerateKey(rand.Reader)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -33,4 +36,114 @@ func TestSignCsv(t *testing.T) {
 	}
 	assert.True(t, check)
 
+	// the EdDSA signature is parsed with ParseSignature
+	assert.NoError(t, sign.CheckSizes())
+	sign.Sig = sign.Sig[:3]
+	assert.Error(t, sign.CheckSizes())
+}
+
+func TestSignCsvLegacy(t *testing.T) {
+	signer, err := eddsa.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	pubKey := signer.Public()
+
+	sign, err := SignCsvVersion("../datasets/framingham_tiny.csv", signer, GeneratorsLegacy)
+	assert.NoError(t, err)
+	signed := filepath.Join(t.TempDir(), "signed.csv")
+	assert.NoError(t, WriteSignCsv("../datasets/framingham_tiny.csv", signed, sign))
+	check, err := VerifyCsv(signed, pubKey)
+	assert.NoError(t, err)
+	assert.True(t, check)
+
+	// the commitment does not open with the generators of another version
+	sign.GenVersion = GeneratorsHashToCurve
+	assert.NoError(t, WriteSignCsv("../datasets/framingham_tiny.csv", signed, sign))
+	_, err = VerifyCsv(signed, pubKey)
+	assert.Error(t, err)
+}
+
+func TestSignCsvMiMC(t *testing.T) {
+	signer, err := eddsa.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	pubKey := signer.Public()
+
+	opts := DefaultSignOptions
+	opts.Scheme = CommitSchemeMiMC
+	sign, err := SignCsvWithOptions("../datasets/framingham_tiny.csv", signer, opts)
+	assert.NoError(t, err)
+	assert.Nil(t, sign.CommitData)
+	signed := filepath.Join(t.TempDir(), "signed.csv")
+	assert.NoError(t, WriteSignCsv("../datasets/framingham_tiny.csv", signed, sign))
+	check, err := VerifyCsv(signed, pubKey)
+	assert.NoError(t, err)
+	assert.True(t, check)
+
+	// the commitment does not open with another randomness
+	sign.RData = new(big.Int).Add(sign.RData, big.NewInt(1))
+	assert.NoError(t, WriteSignCsv("../datasets/framingham_tiny.csv", signed, sign))
+	_, err = VerifyCsv(signed, pubKey)
+	assert.Error(t, err)
+}
+
+func TestSignCsvSchemes(t *testing.T) {
+	ecdsaKey, err := GenerateECDSAP256(rand.Reader)
+	assert.NoError(t, err)
+	ed25519Key, err := GenerateEd25519(rand.Reader)
+	assert.NoError(t, err)
+	other, err := GenerateECDSAP256(rand.Reader)
+	assert.NoError(t, err)
+
+	for _, c := range []struct {
+		signer signature.Signer
+		scheme SignatureScheme
+	}{{ecdsaKey, SignatureSchemeECDSAP256}, {ed25519Key, SignatureSchemeEd25519}} {
+		sign, err := SignCsv("../datasets/framingham_tiny.csv", c.signer)
+		assert.NoError(t, err)
+		assert.Equal(t, c.scheme, sign.SigScheme)
+		assert.Equal(t, c.scheme == SignatureSchemeECDSAP256, sign.SigScheme.InCircuit())
+		signed := filepath.Join(t.TempDir(), "signed.csv")
+		assert.NoError(t, WriteSignCsv("../datasets/framingham_tiny.csv", signed, sign))
+		check, err := VerifyCsv(signed, c.signer.Public())
+		assert.NoError(t, err)
+		assert.True(t, check)
+
+		pubKey, err := ParsePublicKey(sign.SigScheme, sign.PubKey)
+		assert.NoError(t, err)
+		assert.True(t, pubKey.Equal(c.signer.Public()))
+		assert.NoError(t, sign.CheckSizes())
+		short := *sign
+		short.PubKey = short.PubKey[:3]
+		assert.Error(t, short.CheckSizes())
+
+		_, err = VerifyCsv(signed, other.Public())
+		assert.Error(t, err)
+
+		// another signature
+		sign.Sig[len(sign.Sig)-1] ^= 1
+		assert.NoError(t, WriteSignCsv("../datasets/framingham_tiny.csv", signed, sign))
+		check, _ = VerifyCsv(signed, c.signer.Public())
+		assert.False(t, check)
+	}
+
+	// the private key round trips
+	var k ECDSAP256PrivateKey
+	n, err := k.SetBytes(ecdsaKey.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, len(ecdsaKey.Bytes()), n)
+	assert.Equal(t, ecdsaKey.D, k.D)
+
+	sigBin, err := ecdsaKey.Sign([]byte("message"), nil)
+	assert.NoError(t, err)
+	r, s, err := ParseECDSASignature(sigBin)
+	assert.NoError(t, err)
+	digest := sha256.Sum256([]byte("message"))
+	assert.True(t, ecdsa.Verify(&ecdsaKey.PublicKey, digest[:], r, s))
+	_, _, err = ParseECDSASignature(sigBin[:len(sigBin)-1])
+	assert.Error(t, err)
+
+	// the MiMC commitment is only for EdDSA
+	opts := DefaultSignOptions
+	opts.Scheme = CommitSchemeMiMC
+	_, err = SignCsvWithOptions("../datasets/framingham_tiny.csv", ecdsaKey, opts)
+	assert.Error(t, err)
 }
@@ -0,0 +1,292 @@
+package signature
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"io"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	"github.com/consensys/gnark-crypto/signature"
+	"golang.org/x/crypto/cryptobyte"
+	"golang.org/x/crypto/cryptobyte/asn1"
+)
+
+// SignatureScheme identifies the signature of the data owner in a
+// SignatureZKP, given by the key of the signer.
+type SignatureScheme int
+
+const (
+	// SignatureSchemeEdDSABN254 is EdDSA over the twisted Edwards curve
+	// of BN254 with the hash of the circuit version, verified inside
+	// the circuit.
+	SignatureSchemeEdDSABN254 SignatureScheme = 0
+	// SignatureSchemeECDSAP256 is ECDSA over P-256 with SHA-256, the
+	// signature in ASN.1 DER.
+	SignatureSchemeECDSAP256 SignatureScheme = 1
+	// SignatureSchemeEd25519 is Ed25519.
+	SignatureSchemeEd25519 SignatureScheme = 2
+)
+
+// InCircuit tells whether the signature is verified inside the
+// circuit, ECDSA with the emulated arithmetic of P-256. The other
+// signatures are verified outside of it on the public commitment
+// Commit.C, the circuit proving its opening.
+func (s SignatureScheme) InCircuit() bool {
+	return s == SignatureSchemeEdDSABN254 || s == SignatureSchemeECDSAP256
+}
+
+func (s SignatureScheme) String() string {
+	switch s {
+	case SignatureSchemeEdDSABN254:
+		return "eddsa-bn254"
+	case SignatureSchemeECDSAP256:
+		return "ecdsa-p256"
+	case SignatureSchemeEd25519:
+		return "ed25519"
+	}
+
+	return fmt.Sprintf("SignatureScheme(%d)", int(s))
+}
+
+// SchemeOf returns the scheme of the public key.
+func SchemeOf(pubKey signature.PublicKey) (SignatureScheme, error) {
+	switch pubKey.(type) {
+	case *eddsa.PublicKey:
+		return SignatureSchemeEdDSABN254, nil
+	case *ECDSAP256PublicKey:
+		return SignatureSchemeECDSAP256, nil
+	case *Ed25519PublicKey:
+		return SignatureSchemeEd25519, nil
+	}
+
+	return 0, fmt.Errorf("unsupported public key %T", pubKey)
+}
+
+// ParsePublicKey returns the public key of the scheme in its binary
+// form.
+func ParsePublicKey(scheme SignatureScheme, buf []byte) (signature.PublicKey, error) {
+	var pubKey signature.PublicKey
+	switch scheme {
+	case SignatureSchemeEdDSABN254:
+		pubKey = new(eddsa.PublicKey)
+	case SignatureSchemeECDSAP256:
+		pubKey = new(ECDSAP256PublicKey)
+	case SignatureSchemeEd25519:
+		pubKey = new(Ed25519PublicKey)
+	default:
+		return nil, fmt.Errorf("unknown signature scheme %d", scheme)
+	}
+	n, err := pubKey.SetBytes(buf)
+	if err != nil {
+		return nil, err
+	}
+	if n != len(buf) {
+		return nil, fmt.Errorf("invalid public key")
+	}
+
+	return pubKey, nil
+}
+
+// CheckSizes checks the lengths of the public key and of the signature
+// of s for its scheme, the ECDSA signature being of variable length.
+// The EdDSA ones are parsed with ParsePoint and ParseSignature.
+func (s *SignatureZKP) CheckSizes() error {
+	var pubKeySize, sigSize int
+	switch s.SigScheme {
+	case SignatureSchemeEdDSABN254:
+		pubKeySize, sigSize = 32, 64
+	case SignatureSchemeECDSAP256:
+		pubKeySize = 1 + 2*32
+	case SignatureSchemeEd25519:
+		pubKeySize, sigSize = ed25519.PublicKeySize, ed25519.SignatureSize
+	default:
+		return fmt.Errorf("unknown signature scheme %d", s.SigScheme)
+	}
+	if len(s.PubKey) != pubKeySize {
+		return fmt.Errorf("public key of %d bytes, expected %d", len(s.PubKey), pubKeySize)
+	}
+	if sigSize != 0 && len(s.Sig) != sigSize {
+		return fmt.Errorf("signature of %d bytes, expected %d", len(s.Sig), sigSize)
+	}
+
+	return nil
+}
+
+// ECDSAP256PublicKey is a P-256 public key, in the uncompressed form of
+// SEC 1.
+type ECDSAP256PublicKey struct {
+	ecdsa.PublicKey
+}
+
+// Verify verifies the signature of the SHA-256 of the message, hFunc
+// is not used.
+func (pk *ECDSAP256PublicKey) Verify(sigBin, message []byte, hFunc hash.Hash) (bool, error) {
+	digest := sha256.Sum256(message)
+
+	return ecdsa.VerifyASN1(&pk.PublicKey, digest[:], sigBin), nil
+}
+
+func (pk *ECDSAP256PublicKey) SetBytes(buf []byte) (int, error) {
+	size := 1 + 2*32
+	if len(buf) < size {
+		return 0, io.ErrShortBuffer
+	}
+	x, y := elliptic.Unmarshal(elliptic.P256(), buf[:size])
+	if x == nil {
+		return 0, fmt.Errorf("invalid P-256 public key")
+	}
+	pk.Curve, pk.X, pk.Y = elliptic.P256(), x, y
+
+	return size, nil
+}
+
+func (pk *ECDSAP256PublicKey) Bytes() []byte {
+	return elliptic.Marshal(elliptic.P256(), pk.X, pk.Y)
+}
+
+func (pk *ECDSAP256PublicKey) Equal(other signature.PublicKey) bool {
+	return bytes.Equal(pk.Bytes(), other.Bytes())
+}
+
+// ParseECDSASignature returns r and s of an ECDSA signature in ASN.1
+// DER.
+func ParseECDSASignature(sigBin []byte) (*big.Int, *big.Int, error) {
+	r, s := new(big.Int), new(big.Int)
+	var inner cryptobyte.String
+	input := cryptobyte.String(sigBin)
+	if !input.ReadASN1(&inner, asn1.SEQUENCE) || !input.Empty() || !inner.ReadASN1Integer(r) ||
+		!inner.ReadASN1Integer(s) || !inner.Empty() {
+		return nil, nil, fmt.Errorf("invalid ECDSA signature")
+	}
+	if r.Sign() <= 0 || s.Sign() <= 0 {
+		return nil, nil, fmt.Errorf("invalid ECDSA signature")
+	}
+
+	return r, s, nil
+}
+
+// ECDSAP256PrivateKey signs datasets with ECDSA over P-256.
+type ECDSAP256PrivateKey struct {
+	ecdsa.PrivateKey
+}
+
+// GenerateECDSAP256 generates a P-256 key with the randomness of r.
+func GenerateECDSAP256(r io.Reader) (*ECDSAP256PrivateKey, error) {
+	k, err := ecdsa.GenerateKey(elliptic.P256(), r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ECDSAP256PrivateKey{*k}, nil
+}
+
+func (k *ECDSAP256PrivateKey) Public() signature.PublicKey {
+	return &ECDSAP256PublicKey{k.PublicKey}
+}
+
+// Sign signs the SHA-256 of the message, hFunc is not used.
+func (k *ECDSAP256PrivateKey) Sign(message []byte, hFunc hash.Hash) ([]byte, error) {
+	digest := sha256.Sum256(message)
+
+	return ecdsa.SignASN1(rand.Reader, &k.PrivateKey, digest[:])
+}
+
+// Bytes returns the public key followed by the scalar of 32 bytes.
+func (k *ECDSAP256PrivateKey) Bytes() []byte {
+	return append(k.Public().Bytes(), k.D.FillBytes(make([]byte, 32))...)
+}
+
+func (k *ECDSAP256PrivateKey) SetBytes(buf []byte) (int, error) {
+	var pk ECDSAP256PublicKey
+	n, err := pk.SetBytes(buf)
+	if err != nil {
+		return 0, err
+	}
+	if len(buf) < n+32 {
+		return 0, io.ErrShortBuffer
+	}
+	k.PublicKey = pk.PublicKey
+	k.D = new(big.Int).SetBytes(buf[n : n+32])
+
+	return n + 32, nil
+}
+
+// Ed25519PublicKey is an Ed25519 public key.
+type Ed25519PublicKey struct {
+	Key ed25519.PublicKey
+}
+
+// Verify verifies the signature of the message, hFunc is not used.
+func (pk *Ed25519PublicKey) Verify(sigBin, message []byte, hFunc hash.Hash) (bool, error) {
+	if len(pk.Key) != ed25519.PublicKeySize {
+		return false, fmt.Errorf("invalid Ed25519 public key")
+	}
+
+	return ed25519.Verify(pk.Key, message, sigBin), nil
+}
+
+func (pk *Ed25519PublicKey) SetBytes(buf []byte) (int, error) {
+	if len(buf) < ed25519.PublicKeySize {
+		return 0, io.ErrShortBuffer
+	}
+	pk.Key = append(ed25519.PublicKey{}, buf[:ed25519.PublicKeySize]...)
+
+	return ed25519.PublicKeySize, nil
+}
+
+func (pk *Ed25519PublicKey) Bytes() []byte {
+	return append([]byte{}, pk.Key...)
+}
+
+func (pk *Ed25519PublicKey) Equal(other signature.PublicKey) bool {
+	return bytes.Equal(pk.Bytes(), other.Bytes())
+}
+
+// Ed25519PrivateKey signs datasets with Ed25519.
+type Ed25519PrivateKey struct {
+	Key ed25519.PrivateKey
+}
+
+// GenerateEd25519 generates an Ed25519 key with the randomness of r.
+func GenerateEd25519(r io.Reader) (*Ed25519PrivateKey, error) {
+	_, k, err := ed25519.GenerateKey(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Ed25519PrivateKey{k}, nil
+}
+
+func (k *Ed25519PrivateKey) Public() signature.PublicKey {
+	return &Ed25519PublicKey{Key: k.Key.Public().(ed25519.PublicKey)}
+}
+
+// Sign signs the message, hFunc is not used.
+func (k *Ed25519PrivateKey) Sign(message []byte, hFunc hash.Hash) ([]byte, error) {
+	return ed25519.Sign(k.Key, message), nil
+}
+
+// Bytes returns the seed followed by the public key, as in
+// crypto/ed25519.
+func (k *Ed25519PrivateKey) Bytes() []byte {
+	return append([]byte{}, k.Key...)
+}
+
+func (k *Ed25519PrivateKey) SetBytes(buf []byte) (int, error) {
+	if len(buf) < ed25519.PrivateKeySize {
+		return 0, io.ErrShortBuffer
+	}
+	k.Key = ed25519.NewKeyFromSeed(buf[:ed25519.SeedSize])
+	if !bytes.Equal(k.Key, buf[:ed25519.PrivateKeySize]) {
+		return 0, fmt.Errorf("invalid Ed25519 private key")
+	}
+
+	return ed25519.PrivateKeySize, nil
+}
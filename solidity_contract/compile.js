@@ -0,0 +1,34 @@
+// Compiles Verifier.sol with a soljson build of solc and writes the
+// ABI and the deployment bytecode of the contract Verifier. The
+// bytecode targets the Paris EVM, without the PUSH0 of Shanghai.
+//
+//	node compile.js soljson-v0.8.21+commit.d9974bed.js Verifier.sol
+const fs = require('fs');
+const path = require('path');
+
+const [soljson, source] = process.argv.slice(2);
+const solc = require(path.resolve(soljson));
+const compile = solc.cwrap('solidity_compile', 'string', ['string', 'number', 'number']);
+const version = solc.cwrap('solidity_version', 'string', []);
+
+const input = {
+  language: 'Solidity',
+  sources: { 'Verifier.sol': { content: fs.readFileSync(source, 'utf8') } },
+  settings: {
+    optimizer: { enabled: true, runs: 200 },
+    evmVersion: 'paris',
+    outputSelection: { '*': { Verifier: ['abi', 'evm.bytecode.object'] } },
+  },
+};
+const output = JSON.parse(compile(JSON.stringify(input), 0, 0));
+for (const e of output.errors || []) {
+  console.error(e.formattedMessage);
+}
+if ((output.errors || []).some((e) => e.severity === 'error')) {
+  process.exit(1);
+}
+const contract = output.contracts['Verifier.sol'].Verifier;
+const dir = path.dirname(source);
+fs.writeFileSync(path.join(dir, 'Verifier.abi'), JSON.stringify(contract.abi) + '\n');
+fs.writeFileSync(path.join(dir, 'Verifier.bin'), contract.evm.bytecode.object + '\n');
+console.log('compiled with solc ' + version());
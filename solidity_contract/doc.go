@@ -0,0 +1,18 @@
+// Package solidity_contract is the Go binding of the Solidity verifier
+// contract of the dataset proofs, as exported by
+// ZKPComponent.ExportSolidityVerifier for verifyKey.txt. The gnark
+// template needs solc 0.8.19 or later, Verifier.abi and Verifier.bin
+// were compiled with solc 0.8.21 (soljson run by node) for the Paris
+// EVM:
+//
+//	go run ./cmd/solidity_verifier -vk verifyKey.txt -out solidity_contract/Verifier.sol
+//	node solidity_contract/compile.js soljson-v0.8.21+commit.d9974bed.js solidity_contract/Verifier.sol
+//	abigen --abi solidity_contract/Verifier.abi --bin solidity_contract/Verifier.bin \
+//		--pkg solidity_contract --type Verifier --out solidity_contract/verifier.go
+//
+// The contract is exported and compiled again whenever verifyKey.txt
+// changes. The binding and its test on a simulated chain are a separate
+// module, keeping go-ethereum out of the dependencies of ZKPComponent:
+//
+//	cd solidity_contract && go test ./...
+package solidity_contract
@@ -0,0 +1,292 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package solidity_contract
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+	_ = abi.ConvertType
+)
+
+// VerifierMetaData contains all meta data concerning the Verifier contract.
+var VerifierMetaData = &bind.MetaData{
+	ABI: "[{\"inputs\":[],\"name\":\"ProofInvalid\",\"type\":\"error\"},{\"inputs\":[],\"name\":\"PublicInputNotInField\",\"type\":\"error\"},{\"inputs\":[{\"internalType\":\"uint256[8]\",\"name\":\"proof\",\"type\":\"uint256[8]\"}],\"name\":\"compressProof\",\"outputs\":[{\"internalType\":\"uint256[4]\",\"name\":\"compressed\",\"type\":\"uint256[4]\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256[4]\",\"name\":\"compressedProof\",\"type\":\"uint256[4]\"},{\"internalType\":\"uint256[7]\",\"name\":\"input\",\"type\":\"uint256[7]\"}],\"name\":\"verifyCompressedProof\",\"outputs\":[],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256[8]\",\"name\":\"proof\",\"type\":\"uint256[8]\"},{\"internalType\":\"uint256[7]\",\"name\":\"input\",\"type\":\"uint256[7]\"}],\"name\":\"verifyProof\",\"outputs\":[],\"stateMutability\":\"view\",\"type\":\"function\"}]",
+	Bin: "0x608060405234801561001057600080fd5b50611607806100206000396000f3fe608060405234801561001057600080fd5b50600436106100415760003560e01c80631042664e1461004657806344f636921461005b578063f6d3293a14610084575b600080fd5b610059610054366004611492565b610097565b005b61006e6100693660046114c9565b610317565b60405161007b91906114ed565b60405180910390f35b61005961009236600461151e565b610374565b6000806100a383610692565b9150915060006040516101008682377f2263418fbfeec05c7d7e6c5d58618f3adaa4cc619b98b1948fe76abd1111596d6101008201527f19c8488095fc76bcaebda6fabdb011aeb1cf4373b3ca276c178ce3ccbe6ec38c6101208201527f22b765780f8f5fdc00b2c1ae0a812af0fb450ef8929700b6bbc6eef3d8c3e3736101408201527f1fda301b227d1cd3f2c31115f3c7ea6f254ab5c16c77068bc1a56bfe8425b9566101608201527f143e1bb3dc241c17400e894062809caf07cbd1e2e03109c4ae191072ba8364c76101808201527f13c7575e86bc01e31f3582824eb65fd68dca508f1acd63acbb20131496d4f1c06101a08201527f2e61f46e1693baebfa6bb9a9c7fcade44e24d3a557a237bc1fc6bb04802369016101c08201527f1226976af626cb6b2d6039dafcc2f085382039a3fa0c814632834e66fe8c0f586101e08201527f1993050a207df714efe838bc12d009d09efe3c2150e1931a68e0b0f4b86503776102008201527f01746d4d5ce1a2166f0cbb74cc4dd18266c0d5e8fd3849db96dcfc2ee579bfdc61022082015283610240820152826102608201527f198e9393920d483a7260bfb731fb5d25f1aa493335a9e71297e485b7aef312c26102808201527f1800deef121f1e76426a00665e5c4479674322d4f75edadd46debd5cd992f6ed6102a08201527f275dc4a288d1afb3cbb1ac09187524c7db36395df7be3b99e673b13a075a65ec6102c08201527f1d9befcd05a5323e6da4d435f3b617cdb3af83285c2df711ef39c01571827f9d6102e08201526020816103008360085afa90511690508061031057604051631ff3747d60e21b815260040160405180910390fd5b5050505050565b61031f611414565b61033282358360015b6020020135610aca565b81526103506060830135604084013560a08501356080860135610bbf565b6020830152604082015261036a60c0830135836007610328565b6060820152919050565b60008061038784825b6020020135610eb6565b909250905060008080806103a3604089013560208a0135610f5b565b929650909450925090506000806103bb8a600361037d565b915091506000806103cb8b610692565b915091506103d7611432565b8a8152602081018a905260408101889052606081018990526080810186905260a0810187905260c0810185905260e081018490527f2263418fbfeec05c7d7e6c5d58618f3adaa4cc619b98b1948fe76abd1111596d6101008201527f19c8488095fc76bcaebda6fabdb011aeb1cf4373b3ca276c178ce3ccbe6ec38c6101208201527f22b765780f8f5fdc00b2c1ae0a812af0fb450ef8929700b6bbc6eef3d8c3e3736101408201527f1fda301b227d1cd3f2c31115f3c7ea6f254ab5c16c77068bc1a56bfe8425b9566101608201527f143e1bb3dc241c17400e894062809caf07cbd1e2e03109c4ae191072ba8364c76101808201527f13c7575e86bc01e31f3582824eb65fd68dca508f1acd63acbb20131496d4f1c06101a08201527f2e61f46e1693baebfa6bb9a9c7fcade44e24d3a557a237bc1fc6bb04802369016101c08201527f1226976af626cb6b2d6039dafcc2f085382039a3fa0c814632834e66fe8c0f586101e08201527f1993050a207df714efe838bc12d009d09efe3c2150e1931a68e0b0f4b86503776102008201527f01746d4d5ce1a2166f0cbb74cc4dd18266c0d5e8fd3849db96dcfc2ee579bfdc610220820152610240810183905261026081018290527f198e9393920d483a7260bfb731fb5d25f1aa493335a9e71297e485b7aef312c26102808201527f1800deef121f1e76426a00665e5c4479674322d4f75edadd46debd5cd992f6ed6102a08201527f275dc4a288d1afb3cbb1ac09187524c7db36395df7be3b99e673b13a075a65ec6102c08201527f1d9befcd05a5323e6da4d435f3b617cdb3af83285c2df711ef39c01571827f9d6102e08201526000610647611451565b6020816103008560085afa915081158061066357508051600114155b1561068157604051631ff3747d60e21b815260040160405180910390fd5b505050505050505050505050505050565b6000806000600190506040516040810160007f182618a4184c4fbad0d1ebc9cb71498d85c4bdff077ffd19f6a38d4e35c74c5983527f03bd7797fe1ccfaf2f55b330d611f3440fa0ff720b4b3dc058d75583908f06f260208401527f030b076a5a990c513cbd687684d36aab39c7be56012cb05052cede835006975e82527f0aec9728857b963bb43ff97a888628b3f4d2ecbee9fbc13252efb2e2581253616020830152863590508060408301526000805160206115b283398151915281108416935060408260608460075afa8416935060408360808560065afa841693507f10985b5e333fe5d8309e24155ef46cc2005f30f9c5ea57ec75635e02fc0d6b5b82527f097ed3be94fc9ea5f440914cda3c6756ef5969bc84274f1eb90c9e148570d9dd6020830152602087013590508060408301526000805160206115b283398151915281108416935060408260608460075afa8416935060408360808560065afa841693507f19c6b937652ced2412f416a138563c6277b3a1cbc2f7667215d4c81dc7745c1582527f1462af4b156679c1a81462bfd331c63e4ed9f0f6f14f91415339c59e66b728816020830152604087013590508060408301526000805160206115b283398151915281108416935060408260608460075afa8416935060408360808560065afa841693507f2824359ccf784fb2d474988b5abde1739820301ae203acc50fa3bb500789996982527f1c98afbcea898fde75468f927a730fd638433a2fab4b4994e1b24ec3f29e9a486020830152606087013590508060408301526000805160206115b283398151915281108416935060408260608460075afa8416935060408360808560065afa841693507f1edb252ed842bbdae2d1ad493ec5b36682e86c78d77e4391afd1201064de1fc882527f22410c4f1e0ff0eee983ad6de9f51cdbd978b310bda65677cf83efc0f1b18ca26020830152608087013590508060408301526000805160206115b283398151915281108416935060408260608460075afa8416935060408360808560065afa841693507f17816134e3f466e24c6ddd2b291b6e2aca4c54e529fd27f51e44d7ecc531b77a82527f0cc4107c2eff48b9ca03a20dbe98ebf495b2a4516d01313bf38c68699add5ac3602083015260a087013590508060408301526000805160206115b283398151915281108416935060408260608460075afa8416935060408360808560065afa7f0c84198ed8d494f796a90f2e01ec124d62d1dc9fa6663f7b4c12d3402881ce7583527f11f543f128ea80d280999678a6164091ba01a998b08989745f8ffa265e9b4d2d602084015260c088013560408085018290526000805160206115b283398151915290911091909516169390508160608160075afa831692505060408160808360065afa81516020909201519194509092501680610ac45760405163a54f8e2760e01b815260040160405180910390fd5b50915091565b600060008051602061159283398151915283101580610af757506000805160206115928339815191528210155b15610b1557604051631ff3747d60e21b815260040160405180910390fd5b82158015610b21575081155b15610b2e57506000610bb9565b6000610b6d600080516020611592833981519152600360008051602061159283398151915287600080516020611592833981519152898a090908611141565b9050808303610b82575050600182901b610bb9565b610b8b816111a5565b8303610b9e575050600182811b17610bb9565b604051631ff3747d60e21b815260040160405180910390fd5b505b92915050565b60008060008051602061159283398151915286101580610bed57506000805160206115928339815191528510155b80610c0657506000805160206115928339815191528410155b80610c1f57506000805160206115928339815191528310155b15610c3d57604051631ff3747d60e21b815260040160405180910390fd5b82848688171717600003610c5657506000905080610ead565b60008080600080516020611592833981519152610c826003600080516020611592833981519152611570565b6000805160206115928339815191528a8c0909905060006000805160206115928339815191528a6000805160206115928339815191528c8d0909905060006000805160206115928339815191528a6000805160206115928339815191528c8d09099050600080516020611592833981519152806000805160206115928339815191528c860984087f2b149d40ceb8aaae81be18991be06ac3b5b4c5e559dbefa33267e6dc24a138e5089450610d7b600080516020611592833981519152806000805160206115928339815191528e870984087f2fcd3ac2a640a154eb23960892a85a68f031ca0c8344b23a577dcf1052b9e775086111a5565b9350505050600080610dcc60008051602061159283398151915280610da257610da261155a565b60008051602061159283398151915285860960008051602061159283398151915287880908611141565b9050610e196000805160206115928339815191527f183227397098d014dc2822db40c0ac2ecbc0b548b438e5469e10460b6c3e7ea4600080516020611592833981519152848808096111be565b15915050610e28838383611208565b90935091508683148015610e3b57508186145b15610e655780610e4c576000610e4f565b60025b60ff1660028a901b176000179450879350610ea9565b610e6e836111a5565b87148015610e835750610e80826111a5565b86145b15610b9e5780610e94576000610e97565b60025b60ff1660028a901b1760011794508793505b5050505b94509492505050565b60008082600003610ecc57506000928392509050565b600183811c9250808416146000805160206115928339815191528310610f0557604051631ff3747d60e21b815260040160405180910390fd5b610f426000805160206115928339815191526003600080516020611592833981519152866000805160206115928339815191528889090908611141565b91508015610ac457610f53826111a5565b915050915091565b600080808085158015610f6c575084155b15610f8257506000925082915081905080611138565b600286811c9450859350600180881614908088161460008051602061159283398151915286101580610fc257506000805160206115928339815191528510155b15610fe057604051631ff3747d60e21b815260040160405180910390fd5b600060008051602061159283398151915261100a6003600080516020611592833981519152611570565b600080516020611592833981519152888a090990506000600080516020611592833981519152886000805160206115928339815191528a8b090990506000600080516020611592833981519152886000805160206115928339815191528a8b09099050600080516020611592833981519152806000805160206115928339815191528a860984087f2b149d40ceb8aaae81be18991be06ac3b5b4c5e559dbefa33267e6dc24a138e5089650611103600080516020611592833981519152806000805160206115928339815191528c870984087f2fcd3ac2a640a154eb23960892a85a68f031ca0c8344b23a577dcf1052b9e775086111a5565b9550611110878786611208565b9097509550841561113257611124876111a5565b965061112f866111a5565b95505b50505050505b92959194509250565b600061116d827f0c19139cb84c680a6e14116da060561765e05aa45a1c72a34f082305b61f3f5261134f565b905081600080516020611592833981519152828309146111a057604051631ff3747d60e21b815260040160405180910390fd5b919050565b6000805160206115928339815191529081900681030690565b6000806111eb837f0c19139cb84c680a6e14116da060561765e05aa45a1c72a34f082305b61f3f5261134f565b905082600080516020611592833981519152828309149392505050565b6000808061123a60008051602061159283398151915280878809600080516020611592833981519152898a0908611141565b9050831561124e5761124b816111a5565b90505b6112996000805160206115928339815191527f183227397098d014dc2822db40c0ac2ecbc0b548b438e5469e10460b6c3e7ea4600080516020611592833981519152848a0809611141565b92506000805160206115928339815191526112c5600080516020611592833981519152600286096113b4565b860991506000805160206115928339815191526112f26000805160206115928339815191528485096111a5565b60008051602061159283398151915285860908861415806113285750600080516020611592833981519152808385096002098514155b1561134657604051631ff3747d60e21b815260040160405180910390fd5b50935093915050565b600080604051602081526020808201526020604082015284606082015283608082015260008051602061159283398151915260a082015260208160c08360055afa90519250905080610bb757604051631ff3747d60e21b815260040160405180910390fd5b60006113e0827f30644e72e131a029b85045b68181585d97816a916871ca8d3c208c16d87cfd4561134f565b90506000805160206115928339815191528183096001146111a057604051631ff3747d60e21b815260040160405180910390fd5b60405180608001604052806004906020820280368337509192915050565b6040518061030001604052806018906020820280368337509192915050565b60405180602001604052806001906020820280368337509192915050565b806101008101831015610bb957600080fd5b8060e08101831015610bb957600080fd5b6000806101e083850312156114a657600080fd5b6114b0848461146f565b91506114c0846101008501611481565b90509250929050565b600061010082840312156114dc57600080fd5b6114e6838361146f565b9392505050565b60808101818360005b60048110156115155781518352602092830192909101906001016114f6565b50505092915050565b600080610160838503121561153257600080fd5b608083018481111561154357600080fd5b8392506115508582611481565b9150509250929050565b634e487b7160e01b600052601260045260246000fd5b81810381811115610bb957634e487b7160e01b600052601160045260246000fdfe30644e72e131a029b85045b68181585d97816a916871ca8d3c208c16d87cfd4730644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001a26469706673582212203481e78507c5fff2d80dc5b7f829456fde9de324d68e4f68562ef48b9dc3771064736f6c63430008150033",
+}
+
+// VerifierABI is the input ABI used to generate the binding from.
+// Deprecated: Use VerifierMetaData.ABI instead.
+var VerifierABI = VerifierMetaData.ABI
+
+// VerifierBin is the compiled bytecode used for deploying new contracts.
+// Deprecated: Use VerifierMetaData.Bin instead.
+var VerifierBin = VerifierMetaData.Bin
+
+// DeployVerifier deploys a new Ethereum contract, binding an instance of Verifier to it.
+func DeployVerifier(auth *bind.TransactOpts, backend bind.ContractBackend) (common.Address, *types.Transaction, *Verifier, error) {
+	parsed, err := VerifierMetaData.GetAbi()
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	if parsed == nil {
+		return common.Address{}, nil, nil, errors.New("GetABI returned nil")
+	}
+
+	address, tx, contract, err := bind.DeployContract(auth, *parsed, common.FromHex(VerifierBin), backend)
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	return address, tx, &Verifier{VerifierCaller: VerifierCaller{contract: contract}, VerifierTransactor: VerifierTransactor{contract: contract}, VerifierFilterer: VerifierFilterer{contract: contract}}, nil
+}
+
+// Verifier is an auto generated Go binding around an Ethereum contract.
+type Verifier struct {
+	VerifierCaller     // Read-only binding to the contract
+	VerifierTransactor // Write-only binding to the contract
+	VerifierFilterer   // Log filterer for contract events
+}
+
+// VerifierCaller is an auto generated read-only Go binding around an Ethereum contract.
+type VerifierCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// VerifierTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type VerifierTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// VerifierFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type VerifierFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// VerifierSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type VerifierSession struct {
+	Contract     *Verifier         // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// VerifierCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type VerifierCallerSession struct {
+	Contract *VerifierCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts   // Call options to use throughout this session
+}
+
+// VerifierTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type VerifierTransactorSession struct {
+	Contract     *VerifierTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts   // Transaction auth options to use throughout this session
+}
+
+// VerifierRaw is an auto generated low-level Go binding around an Ethereum contract.
+type VerifierRaw struct {
+	Contract *Verifier // Generic contract binding to access the raw methods on
+}
+
+// VerifierCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type VerifierCallerRaw struct {
+	Contract *VerifierCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// VerifierTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type VerifierTransactorRaw struct {
+	Contract *VerifierTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewVerifier creates a new instance of Verifier, bound to a specific deployed contract.
+func NewVerifier(address common.Address, backend bind.ContractBackend) (*Verifier, error) {
+	contract, err := bindVerifier(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &Verifier{VerifierCaller: VerifierCaller{contract: contract}, VerifierTransactor: VerifierTransactor{contract: contract}, VerifierFilterer: VerifierFilterer{contract: contract}}, nil
+}
+
+// NewVerifierCaller creates a new read-only instance of Verifier, bound to a specific deployed contract.
+func NewVerifierCaller(address common.Address, caller bind.ContractCaller) (*VerifierCaller, error) {
+	contract, err := bindVerifier(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &VerifierCaller{contract: contract}, nil
+}
+
+// NewVerifierTransactor creates a new write-only instance of Verifier, bound to a specific deployed contract.
+func NewVerifierTransactor(address common.Address, transactor bind.ContractTransactor) (*VerifierTransactor, error) {
+	contract, err := bindVerifier(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &VerifierTransactor{contract: contract}, nil
+}
+
+// NewVerifierFilterer creates a new log filterer instance of Verifier, bound to a specific deployed contract.
+func NewVerifierFilterer(address common.Address, filterer bind.ContractFilterer) (*VerifierFilterer, error) {
+	contract, err := bindVerifier(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &VerifierFilterer{contract: contract}, nil
+}
+
+// bindVerifier binds a generic wrapper to an already deployed contract.
+func bindVerifier(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := VerifierMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, *parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Verifier *VerifierRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _Verifier.Contract.VerifierCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Verifier *VerifierRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Verifier.Contract.VerifierTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Verifier *VerifierRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Verifier.Contract.VerifierTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Verifier *VerifierCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _Verifier.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Verifier *VerifierTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Verifier.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Verifier *VerifierTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Verifier.Contract.contract.Transact(opts, method, params...)
+}
+
+// CompressProof is a free data retrieval call binding the contract method 0x44f63692.
+//
+// Solidity: function compressProof(uint256[8] proof) view returns(uint256[4] compressed)
+func (_Verifier *VerifierCaller) CompressProof(opts *bind.CallOpts, proof [8]*big.Int) ([4]*big.Int, error) {
+	var out []interface{}
+	err := _Verifier.contract.Call(opts, &out, "compressProof", proof)
+
+	if err != nil {
+		return *new([4]*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([4]*big.Int)).(*[4]*big.Int)
+
+	return out0, err
+
+}
+
+// CompressProof is a free data retrieval call binding the contract method 0x44f63692.
+//
+// Solidity: function compressProof(uint256[8] proof) view returns(uint256[4] compressed)
+func (_Verifier *VerifierSession) CompressProof(proof [8]*big.Int) ([4]*big.Int, error) {
+	return _Verifier.Contract.CompressProof(&_Verifier.CallOpts, proof)
+}
+
+// CompressProof is a free data retrieval call binding the contract method 0x44f63692.
+//
+// Solidity: function compressProof(uint256[8] proof) view returns(uint256[4] compressed)
+func (_Verifier *VerifierCallerSession) CompressProof(proof [8]*big.Int) ([4]*big.Int, error) {
+	return _Verifier.Contract.CompressProof(&_Verifier.CallOpts, proof)
+}
+
+// VerifyCompressedProof is a free data retrieval call binding the contract method 0xf6d3293a.
+//
+// Solidity: function verifyCompressedProof(uint256[4] compressedProof, uint256[7] input) view returns()
+func (_Verifier *VerifierCaller) VerifyCompressedProof(opts *bind.CallOpts, compressedProof [4]*big.Int, input [7]*big.Int) error {
+	var out []interface{}
+	err := _Verifier.contract.Call(opts, &out, "verifyCompressedProof", compressedProof, input)
+
+	if err != nil {
+		return err
+	}
+
+	return err
+
+}
+
+// VerifyCompressedProof is a free data retrieval call binding the contract method 0xf6d3293a.
+//
+// Solidity: function verifyCompressedProof(uint256[4] compressedProof, uint256[7] input) view returns()
+func (_Verifier *VerifierSession) VerifyCompressedProof(compressedProof [4]*big.Int, input [7]*big.Int) error {
+	return _Verifier.Contract.VerifyCompressedProof(&_Verifier.CallOpts, compressedProof, input)
+}
+
+// VerifyCompressedProof is a free data retrieval call binding the contract method 0xf6d3293a.
+//
+// Solidity: function verifyCompressedProof(uint256[4] compressedProof, uint256[7] input) view returns()
+func (_Verifier *VerifierCallerSession) VerifyCompressedProof(compressedProof [4]*big.Int, input [7]*big.Int) error {
+	return _Verifier.Contract.VerifyCompressedProof(&_Verifier.CallOpts, compressedProof, input)
+}
+
+// VerifyProof is a free data retrieval call binding the contract method 0x1042664e.
+//
+// Solidity: function verifyProof(uint256[8] proof, uint256[7] input) view returns()
+func (_Verifier *VerifierCaller) VerifyProof(opts *bind.CallOpts, proof [8]*big.Int, input [7]*big.Int) error {
+	var out []interface{}
+	err := _Verifier.contract.Call(opts, &out, "verifyProof", proof, input)
+
+	if err != nil {
+		return err
+	}
+
+	return err
+
+}
+
+// VerifyProof is a free data retrieval call binding the contract method 0x1042664e.
+//
+// Solidity: function verifyProof(uint256[8] proof, uint256[7] input) view returns()
+func (_Verifier *VerifierSession) VerifyProof(proof [8]*big.Int, input [7]*big.Int) error {
+	return _Verifier.Contract.VerifyProof(&_Verifier.CallOpts, proof, input)
+}
+
+// VerifyProof is a free data retrieval call binding the contract method 0x1042664e.
+//
+// Solidity: function verifyProof(uint256[8] proof, uint256[7] input) view returns()
+func (_Verifier *VerifierCallerSession) VerifyProof(proof [8]*big.Int, input [7]*big.Int) error {
+	return _Verifier.Contract.VerifyProof(&_Verifier.CallOpts, proof, input)
+}
@@ -0,0 +1,128 @@
+package solidity_contract
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/krakenh2020/ZKPComponent"
+	"github.com/krakenh2020/ZKPComponent/key_management"
+	"github.com/krakenh2020/ZKPComponent/signature"
+	"github.com/stretchr/testify/assert"
+)
+
+// publicInputs is the number of public inputs of the dataset proofs,
+// the length of the input of verifyProof
+const publicInputs = 7
+
+// deployVerifier deploys the compiled verifier contract of
+// verifyKey.txt on a simulated chain.
+func deployVerifier(t *testing.T) (*backends.SimulatedBackend, common.Address, *Verifier) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	auth, err := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337))
+	assert.NoError(t, err)
+	backend := backends.NewSimulatedBackend(core.GenesisAlloc{auth.From: {Balance: big.NewInt(1e18)}}, 30_000_000)
+	address, _, contract, err := DeployVerifier(auth, backend)
+	assert.NoError(t, err)
+	backend.Commit()
+
+	return backend, address, contract
+}
+
+func TestSolidityProof(t *testing.T) {
+	signer, err := eddsa.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	dir := t.TempDir()
+	signed := filepath.Join(dir, "signed.csv")
+	sign, err := signature.SignCsv("../datasets/framingham_tiny.csv", signer)
+	assert.NoError(t, err)
+	assert.NoError(t, signature.WriteSignCsv("../datasets/framingham_tiny.csv", signed, sign))
+
+	pk, err := ZKPComponent.LoadProvingKey("../proofKey.txt")
+	assert.NoError(t, err)
+	vk, err := ZKPComponent.LoadVerifyingKey("../verifyKey.txt")
+	assert.NoError(t, err)
+	r1cs, err := ZKPComponent.CompileCircuitDataset()
+	assert.NoError(t, err)
+	pubKey, err := key_management.LoadPubKey("test", "../key_management/keys")
+	assert.NoError(t, err)
+	container := filepath.Join(dir, "container.txt")
+	_, _, _, _, _, err = ZKPComponent.DatasetSplitEncryptAndZkpCsvToFile(signed, container, pk, r1cs, [][]byte{pubKey, pubKey, pubKey})
+	assert.NoError(t, err)
+
+	f, err := os.Open(container)
+	assert.NoError(t, err)
+	defer f.Close()
+	aProof, cols, err := ZKPComponent.ReadAuthColsFrom(f)
+	assert.NoError(t, err)
+
+	// the compiled contract is the one of the verifying key
+	var contract bytes.Buffer
+	assert.NoError(t, ZKPComponent.ExportSolidityVerifier(vk, &contract))
+	compiled, err := os.ReadFile("Verifier.sol")
+	assert.NoError(t, err)
+	assert.Equal(t, string(compiled), contract.String())
+
+	p, err := ZKPComponent.NewSolidityProof(aProof, cols)
+	assert.NoError(t, err)
+	assert.Len(t, p.Input, publicInputs)
+	calldata, err := p.Calldata()
+	assert.NoError(t, err)
+	assert.Len(t, calldata, 4+(8+publicInputs)*32)
+
+	backend, address, verifier := deployVerifier(t)
+	defer backend.Close()
+	call := func(calldata []byte) error {
+		_, err := backend.CallContract(context.Background(), ethereum.CallMsg{To: &address, Data: calldata}, nil)
+		return err
+	}
+	assert.NoError(t, call(calldata))
+
+	// the calldata is the ABI encoding of the arguments
+	var proof [8]*big.Int
+	copy(proof[:], []*big.Int{p.A[0], p.A[1], p.B[0][0], p.B[0][1], p.B[1][0], p.B[1][1], p.C[0], p.C[1]})
+	var input [publicInputs]*big.Int
+	copy(input[:], p.Input)
+	assert.NoError(t, verifier.VerifyProof(nil, proof, input))
+	contractAbi, err := VerifierMetaData.GetAbi()
+	assert.NoError(t, err)
+	packed, err := contractAbi.Pack("verifyProof", proof, input)
+	assert.NoError(t, err)
+	assert.Equal(t, packed, calldata)
+
+	// another dataset
+	p.Input[0].Add(p.Input[0], big.NewInt(1))
+	calldata, err = p.Calldata()
+	assert.NoError(t, err)
+	assert.Error(t, call(calldata))
+
+	// an input not in the field
+	p.Input[0].Add(p.Input[0], fr.Modulus())
+	calldata, err = p.Calldata()
+	assert.NoError(t, err)
+	assert.Error(t, call(calldata))
+
+	p.Input[0] = big.NewInt(-1)
+	_, err = p.Calldata()
+	assert.Error(t, err)
+
+	// a proof of the opening circuit
+	other := *aProof.Sign
+	other.SigScheme = signature.SignatureSchemeEd25519
+	aProof.Sign = &other
+	_, err = ZKPComponent.NewSolidityProof(aProof, cols)
+	assert.Error(t, err)
+}
@@ -0,0 +1,248 @@
+package wire_format
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards"
+	"github.com/krakenh2020/ZKPComponent"
+	"github.com/krakenh2020/ZKPComponent/encryption"
+	"github.com/krakenh2020/ZKPComponent/signature"
+	"github.com/krakenh2020/ZKPComponent/signature/ec"
+)
+
+// coordinateLen is the length of the encoding of a coordinate of P-256
+// and of a scalar.
+const coordinateLen = 32
+
+// intToBytes encodes a non-negative integer as fixed length big-endian
+// bytes. A nil integer is encoded as empty bytes.
+func intToBytes(x *big.Int) ([]byte, error) {
+	if x == nil {
+		return nil, nil
+	}
+	if x.Sign() < 0 || x.BitLen() > 8*coordinateLen {
+		return nil, fmt.Errorf("integer out of range")
+	}
+
+	return x.FillBytes(make([]byte, coordinateLen)), nil
+}
+
+func intFromBytes(b []byte) (*big.Int, error) {
+	if len(b) == 0 {
+		return nil, nil
+	}
+	if len(b) != coordinateLen {
+		return nil, fmt.Errorf("integer of wrong length %d", len(b))
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
+
+func EcToProto(e *ec.Ec) (*Ec, error) {
+	if e == nil {
+		return nil, nil
+	}
+	x, err := intToBytes(e.X)
+	if err != nil {
+		return nil, err
+	}
+	y, err := intToBytes(e.Y)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Ec{X: x, Y: y}, nil
+}
+
+func EcFromProto(e *Ec) (*ec.Ec, error) {
+	if e == nil {
+		return nil, nil
+	}
+	if len(e.X) != coordinateLen || len(e.Y) != coordinateLen {
+		return nil, fmt.Errorf("point coordinates of wrong length")
+	}
+	res := &ec.Ec{X: new(big.Int).SetBytes(e.X), Y: new(big.Int).SetBytes(e.Y)}
+	unit := res.X.Sign() == 0 && res.Y.Sign() == 0
+	if !unit && !ec.P.IsOnCurve(res.X, res.Y) {
+		return nil, fmt.Errorf("point not on the curve")
+	}
+
+	return res, nil
+}
+
+func PedCommitToProto(c *signature.PedCommit) (*PedCommit, error) {
+	r, err := intToBytes(c.R)
+	if err != nil {
+		return nil, err
+	}
+	res := &PedCommit{R: r}
+	if c.C != nil {
+		cBytes := c.C.Bytes()
+		res.C = cBytes[:]
+	}
+
+	return res, nil
+}
+
+func PedCommitFromProto(c *PedCommit) (*signature.PedCommit, error) {
+	if c == nil {
+		return &signature.PedCommit{}, nil
+	}
+	r, err := intFromBytes(c.R)
+	if err != nil {
+		return nil, err
+	}
+	res := &signature.PedCommit{R: r}
+	if len(c.C) != 0 {
+		res.C = new(twistededwards.PointAffine)
+		_, err = res.C.SetBytes(c.C)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+func SignatureZKPToProto(s *signature.SignatureZKP) (*SignatureZKP, error) {
+	if s == nil {
+		return nil, nil
+	}
+	commit, err := PedCommitToProto(&s.Commit)
+	if err != nil {
+		return nil, err
+	}
+	commitData, err := EcToProto(s.CommitData)
+	if err != nil {
+		return nil, err
+	}
+	rData, err := intToBytes(s.RData)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.GenVersion < 0 {
+		return nil, fmt.Errorf("invalid generator version %d", s.GenVersion)
+	}
+	if s.Scheme < 0 {
+		return nil, fmt.Errorf("invalid commitment scheme %d", s.Scheme)
+	}
+	if s.CircuitVersion < 0 {
+		return nil, fmt.Errorf("invalid circuit version %d", s.CircuitVersion)
+	}
+	if s.SigScheme < 0 {
+		return nil, fmt.Errorf("invalid signature scheme %d", s.SigScheme)
+	}
+	commitField, err := intToBytes(s.CommitField)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SignatureZKP{Sig: s.Sig, Commit: commit, CommitData: commitData, RData: rData, PubKey: s.PubKey,
+		GenVersion: uint32(s.GenVersion), Scheme: uint32(s.Scheme), CommitField: commitField,
+		CircuitVersion: uint32(s.CircuitVersion), SigScheme: uint32(s.SigScheme)}, nil
+}
+
+func SignatureZKPFromProto(s *SignatureZKP) (*signature.SignatureZKP, error) {
+	if s == nil {
+		return nil, nil
+	}
+	commit, err := PedCommitFromProto(s.Commit)
+	if err != nil {
+		return nil, err
+	}
+	commitData, err := EcFromProto(s.CommitData)
+	if err != nil {
+		return nil, err
+	}
+	rData, err := intFromBytes(s.RData)
+	if err != nil {
+		return nil, err
+	}
+	commitField, err := intFromBytes(s.CommitField)
+	if err != nil {
+		return nil, err
+	}
+
+	return &signature.SignatureZKP{Sig: s.Sig, Commit: *commit, CommitData: commitData, RData: rData, PubKey: s.PubKey,
+		GenVersion: signature.GeneratorVersion(s.GenVersion), Scheme: signature.CommitScheme(s.Scheme),
+		CommitField: commitField, CircuitVersion: signature.CircuitVersion(s.CircuitVersion),
+		SigScheme: signature.SignatureScheme(s.SigScheme)}, nil
+}
+
+func AuthProofToProto(a *ZKPComponent.AuthProof) (*AuthProof, error) {
+	commits := make([]*Ec, len(a.Commits))
+	var err error
+	for i, e := range a.Commits {
+		commits[i], err = EcToProto(e)
+		if err != nil {
+			return nil, err
+		}
+	}
+	sign, err := SignatureZKPToProto(a.Sign)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuthProof{ZkProof: a.ZkProof, Commits: commits, Sign: sign}, nil
+}
+
+func AuthProofFromProto(a *AuthProof) (*ZKPComponent.AuthProof, error) {
+	if a == nil {
+		return nil, fmt.Errorf("missing authentication proof")
+	}
+	commits := make([]*ec.Ec, len(a.Commits))
+	var err error
+	for i, e := range a.Commits {
+		commits[i], err = EcFromProto(e)
+		if err != nil {
+			return nil, err
+		}
+	}
+	sign, err := SignatureZKPFromProto(a.Sign)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ZKPComponent.AuthProof{ZkProof: a.ZkProof, Commits: commits, Sign: sign}, nil
+}
+
+func VecEncToProto(v *encryption.VecEnc) *VecEnc {
+	return &VecEnc{Key: v.Key, Iv: v.Iv, Val: v.Val, Encoding: v.Encoding}
+}
+
+func VecEncFromProto(v *VecEnc) *encryption.VecEnc {
+	return &encryption.VecEnc{Key: v.Key, Iv: v.Iv, Val: v.Val, Encoding: v.Encoding}
+}
+
+// VecToProto encodes the elements of a share, which are smaller than
+// the order of P-256.
+func VecToProto(vec []*big.Int) ([][]byte, error) {
+	res := make([][]byte, len(vec))
+	var err error
+	for i, e := range vec {
+		res[i], err = intToBytes(e)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+func VecFromProto(vec [][]byte) ([]*big.Int, error) {
+	res := make([]*big.Int, len(vec))
+	var err error
+	for i, e := range vec {
+		res[i], err = intFromBytes(e)
+		if err != nil {
+			return nil, err
+		}
+		if res[i] == nil {
+			return nil, fmt.Errorf("missing share element %d", i)
+		}
+	}
+
+	return res, nil
+}
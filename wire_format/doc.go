@@ -0,0 +1,6 @@
+// Package wire_format defines the protobuf messages and the gRPC
+// services of the prover and verifier operations, together with the
+// conversion functions from and to the types of the other packages.
+package wire_format
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative zkp.proto
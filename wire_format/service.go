@@ -0,0 +1,143 @@
+package wire_format
+
+import (
+	"context"
+
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/constraint"
+	"github.com/krakenh2020/ZKPComponent"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ProverConfig bounds the resources used by the ProverService, as
+// prover_service.Config does for the HTTP handler.
+type ProverConfig struct {
+	// MaxRequestBytes is the maximal size of a request, applied by the
+	// options of ServerOptions.
+	MaxRequestBytes int
+	// MaxConcurrent is the maximal number of proofs computed at the
+	// same time, further requests are refused with ResourceExhausted.
+	MaxConcurrent int
+}
+
+var DefaultProverConfig = ProverConfig{MaxRequestBytes: 32 << 20, MaxConcurrent: 2}
+
+// ProverService implements the Prover gRPC service with a proving key
+// and a compiled CircuitDataset loaded once.
+type ProverService struct {
+	UnimplementedProverServer
+	proofKey groth16.ProvingKey
+	r1cs     constraint.ConstraintSystem
+	cfg      ProverConfig
+	sem      chan struct{}
+}
+
+func NewProverService(proofKey groth16.ProvingKey, r1cs constraint.ConstraintSystem) *ProverService {
+	return NewProverServiceWithConfig(proofKey, r1cs, DefaultProverConfig)
+}
+
+func NewProverServiceWithConfig(proofKey groth16.ProvingKey, r1cs constraint.ConstraintSystem, cfg ProverConfig) *ProverService {
+	if cfg.MaxRequestBytes <= 0 {
+		cfg.MaxRequestBytes = DefaultProverConfig.MaxRequestBytes
+	}
+	if cfg.MaxConcurrent <= 0 {
+		cfg.MaxConcurrent = DefaultProverConfig.MaxConcurrent
+	}
+
+	return &ProverService{proofKey: proofKey, r1cs: r1cs, cfg: cfg, sem: make(chan struct{}, cfg.MaxConcurrent)}
+}
+
+// ServerOptions returns the options of the gRPC server serving s,
+// refusing the requests larger than its MaxRequestBytes.
+func (s *ProverService) ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{grpc.MaxRecvMsgSize(s.cfg.MaxRequestBytes)}
+}
+
+func (s *ProverService) Prove(ctx context.Context, req *ProveRequest) (*ProveResponse, error) {
+	if len(req.PubKeys) != 3 {
+		return nil, status.Error(codes.InvalidArgument, "expected 3 public keys")
+	}
+	for _, e := range req.PubKeys {
+		if len(e) != 32 {
+			return nil, status.Error(codes.InvalidArgument, "public keys must have 32 bytes")
+		}
+	}
+
+	select {
+	case s.sem <- struct{}{}:
+		defer func() { <-s.sem }()
+	default:
+		return nil, status.Error(codes.ResourceExhausted, "too many concurrent requests")
+	}
+
+	shares, proof, commits, cols, sign, err := ZKPComponent.CsvTextSplitAndZkpCsvText(req.Csv, s.proofKey, s.r1cs)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	encShares, err := ZKPComponent.EncryptShares(shares, req.PubKeys)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	aProof, err := ZKPComponent.NewAuthProof(proof, commits, sign)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	auth, err := AuthProofToProto(aProof)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	res := &ProveResponse{Cols: cols, Auth: auth}
+	for _, e := range encShares {
+		res.Shares = append(res.Shares, VecEncToProto(e))
+	}
+
+	return res, nil
+}
+
+// VerifierService implements the Verifier gRPC service. It is meant to
+// run locally at a node, since the node sends it its decrypted share.
+type VerifierService struct {
+	UnimplementedVerifierServer
+	keys ZKPComponent.VerifyingKeys
+}
+
+// NewVerifierService returns the service verifying the proofs with the
+// key of keys for the circuit of their signature.
+func NewVerifierService(keys ZKPComponent.VerifyingKeys) *VerifierService {
+	return &VerifierService{keys: keys}
+}
+
+func (s *VerifierService) Verify(ctx context.Context, req *VerifyRequest) (*VerifyResponse, error) {
+	if req.NodeId >= 3 {
+		return nil, status.Error(codes.InvalidArgument, "invalid node id")
+	}
+	share, err := VecFromProto(req.Share)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	aProof, err := AuthProofFromProto(req.Auth)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if aProof.Sign == nil || len(aProof.Commits) != 3 {
+		return nil, status.Error(codes.InvalidArgument, "incomplete authentication proof")
+	}
+
+	proof, commits, sign, pubKey, err := ZKPComponent.ExpandAuthProof(aProof)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	verKey, err := s.keys.VerifyingKey(ZKPComponent.DatasetCircuitId(sign))
+	if err != nil {
+		return &VerifyResponse{Valid: false, Error: err.Error()}, nil
+	}
+	check, err := ZKPComponent.VerifyDatasetSplitAndZKpCsv(proof, verKey, share, int(req.NodeId), commits, req.Cols, sign, pubKey)
+	if err != nil {
+		return &VerifyResponse{Valid: false, Error: err.Error()}, nil
+	}
+
+	return &VerifyResponse{Valid: check}, nil
+}
@@ -0,0 +1,131 @@
+package wire_format
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	"github.com/krakenh2020/ZKPComponent"
+	"github.com/krakenh2020/ZKPComponent/encryption"
+	"github.com/krakenh2020/ZKPComponent/key_management"
+	"github.com/krakenh2020/ZKPComponent/signature"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestProveVerify(t *testing.T) {
+	pk, err := ZKPComponent.LoadProvingKey("../proofKey.txt")
+	assert.NoError(t, err)
+	keys, err := ZKPComponent.LoadLegacyVerifyingKeys("../verifyKey.txt")
+	assert.NoError(t, err)
+	r1cs, err := ZKPComponent.CompileCircuitDataset()
+	assert.NoError(t, err)
+
+	lis := bufconn.Listen(1 << 20)
+	prover := NewProverService(pk, r1cs)
+	srv := grpc.NewServer(prover.ServerOptions()...)
+	RegisterProverServer(srv, prover)
+	RegisterVerifierServer(srv, NewVerifierService(keys))
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	conn, err := grpc.Dial("bufnet", grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+		return lis.Dial()
+	}), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	signer, err := eddsa.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	signed := filepath.Join(t.TempDir(), "signed.csv")
+	sign, err := signature.SignCsv("../datasets/framingham_tiny.csv", signer)
+	assert.NoError(t, err)
+	assert.NoError(t, signature.WriteSignCsv("../datasets/framingham_tiny.csv", signed, sign))
+	csvText, err := os.ReadFile(signed)
+	assert.NoError(t, err)
+
+	pubKey, secKey := key_management.GenerateKeypair()
+	res, err := NewProverClient(conn).Prove(context.Background(), &ProveRequest{Csv: string(csvText), PubKeys: [][]byte{pubKey, pubKey, pubKey}})
+	assert.NoError(t, err)
+	assert.Len(t, res.Shares, 3)
+
+	// the protobuf encoding is more compact than the JSON one
+	aProof, err := AuthProofFromProto(res.Auth)
+	assert.NoError(t, err)
+	jsonBytes, err := json.Marshal(aProof)
+	assert.NoError(t, err)
+	protoBytes, err := proto.Marshal(res.Auth)
+	assert.NoError(t, err)
+	assert.Less(t, len(protoBytes), len(jsonBytes))
+	auth2, err := AuthProofToProto(aProof)
+	assert.NoError(t, err)
+	assert.True(t, proto.Equal(res.Auth, auth2))
+
+	verifier := NewVerifierClient(conn)
+	for i := 0; i < 3; i++ {
+		share, err := encryption.DecVec(VecEncFromProto(res.Shares[i]), pubKey, secKey)
+		assert.NoError(t, err)
+		shareProto, err := VecToProto(share)
+		assert.NoError(t, err)
+
+		ver, err := verifier.Verify(context.Background(), &VerifyRequest{NodeId: uint32(i), Share: shareProto, Cols: res.Cols, Auth: res.Auth})
+		assert.NoError(t, err)
+		assert.True(t, ver.Valid)
+
+		ver, err = verifier.Verify(context.Background(), &VerifyRequest{NodeId: uint32((i + 1) % 3), Share: shareProto, Cols: res.Cols, Auth: res.Auth})
+		assert.NoError(t, err)
+		assert.False(t, ver.Valid)
+	}
+
+	// a signature too short to parse
+	shortSig := proto.Clone(res.Auth).(*AuthProof)
+	shortSig.Sign.Sig = shortSig.Sign.Sig[:3]
+	shareProto, err := VecToProto(make([]*big.Int, 0))
+	assert.NoError(t, err)
+	_, err = verifier.Verify(context.Background(), &VerifyRequest{NodeId: 0, Share: shareProto, Cols: res.Cols, Auth: shortSig})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = NewProverClient(conn).Prove(context.Background(), &ProveRequest{Csv: string(csvText)})
+	assert.Error(t, err)
+	_, err = NewProverClient(conn).Prove(context.Background(), &ProveRequest{Csv: string(csvText),
+		PubKeys: [][]byte{pubKey, pubKey, pubKey[:31]}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestProverServiceLimits(t *testing.T) {
+	prover := NewProverServiceWithConfig(nil, nil, ProverConfig{MaxRequestBytes: 1 << 10, MaxConcurrent: 1})
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(prover.ServerOptions()...)
+	RegisterProverServer(srv, prover)
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	conn, err := grpc.Dial("bufnet", grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+		return lis.Dial()
+	}), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	defer conn.Close()
+	client := NewProverClient(conn)
+	pubKeys := [][]byte{make([]byte, 32), make([]byte, 32), make([]byte, 32)}
+
+	// a request above MaxRequestBytes
+	_, err = client.Prove(context.Background(), &ProveRequest{Csv: string(make([]byte, 2<<10)), PubKeys: pubKeys})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// a request while MaxConcurrent proofs are computed
+	prover.sem <- struct{}{}
+	_, err = client.Prove(context.Background(), &ProveRequest{Csv: "a", PubKeys: pubKeys})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	<-prover.sem
+}
@@ -0,0 +1,914 @@
+// Wire format of the artifacts exchanged between data owners, the
+// prover and the MPC nodes. Big integers are encoded as unsigned
+// big-endian bytes of fixed length, points of P-256 by their affine
+// coordinates and points of the BN254 twisted Edwards curve in
+// compressed form.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.31.0
+// 	protoc        (unknown)
+// source: zkp.proto
+
+package wire_format
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Point of P-256, the coordinates are 32 bytes long.
+type Ec struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	X []byte `protobuf:"bytes,1,opt,name=x,proto3" json:"x,omitempty"`
+	Y []byte `protobuf:"bytes,2,opt,name=y,proto3" json:"y,omitempty"`
+}
+
+func (x *Ec) Reset() {
+	*x = Ec{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zkp_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Ec) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Ec) ProtoMessage() {}
+
+func (x *Ec) ProtoReflect() protoreflect.Message {
+	mi := &file_zkp_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Ec.ProtoReflect.Descriptor instead.
+func (*Ec) Descriptor() ([]byte, []int) {
+	return file_zkp_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Ec) GetX() []byte {
+	if x != nil {
+		return x.X
+	}
+	return nil
+}
+
+func (x *Ec) GetY() []byte {
+	if x != nil {
+		return x.Y
+	}
+	return nil
+}
+
+// Pedersen commitment over the BN254 twisted Edwards curve.
+type PedCommit struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// randomness of the commitment, empty once made public
+	R []byte `protobuf:"bytes,1,opt,name=r,proto3" json:"r,omitempty"`
+	// compressed point, 32 bytes
+	C []byte `protobuf:"bytes,2,opt,name=c,proto3" json:"c,omitempty"`
+}
+
+func (x *PedCommit) Reset() {
+	*x = PedCommit{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zkp_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PedCommit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PedCommit) ProtoMessage() {}
+
+func (x *PedCommit) ProtoReflect() protoreflect.Message {
+	mi := &file_zkp_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PedCommit.ProtoReflect.Descriptor instead.
+func (*PedCommit) Descriptor() ([]byte, []int) {
+	return file_zkp_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PedCommit) GetR() []byte {
+	if x != nil {
+		return x.R
+	}
+	return nil
+}
+
+func (x *PedCommit) GetC() []byte {
+	if x != nil {
+		return x.C
+	}
+	return nil
+}
+
+type SignatureZKP struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Sig        []byte     `protobuf:"bytes,1,opt,name=sig,proto3" json:"sig,omitempty"`
+	Commit     *PedCommit `protobuf:"bytes,2,opt,name=commit,proto3" json:"commit,omitempty"`
+	CommitData *Ec        `protobuf:"bytes,3,opt,name=commit_data,json=commitData,proto3" json:"commit_data,omitempty"`
+	// randomness of commit_data, empty once made public
+	RData  []byte `protobuf:"bytes,4,opt,name=r_data,json=rData,proto3" json:"r_data,omitempty"`
+	PubKey []byte `protobuf:"bytes,5,opt,name=pub_key,json=pubKey,proto3" json:"pub_key,omitempty"`
+	// version of the generators of commit_data, see
+	// signature.GeneratorVersion
+	GenVersion uint32 `protobuf:"varint,6,opt,name=gen_version,json=genVersion,proto3" json:"gen_version,omitempty"`
+	// commitment scheme of the dataset, see signature.CommitScheme
+	Scheme uint32 `protobuf:"varint,7,opt,name=scheme,proto3" json:"scheme,omitempty"`
+	// commitment of signature.CommitSchemeMiMC, replacing commit_data
+	CommitField []byte `protobuf:"bytes,8,opt,name=commit_field,json=commitField,proto3" json:"commit_field,omitempty"`
+	// hash of commit and sig, see signature.CircuitVersion
+	CircuitVersion uint32 `protobuf:"varint,9,opt,name=circuit_version,json=circuitVersion,proto3" json:"circuit_version,omitempty"`
+	// signature scheme of sig and pub_key, see signature.SignatureScheme
+	SigScheme uint32 `protobuf:"varint,10,opt,name=sig_scheme,json=sigScheme,proto3" json:"sig_scheme,omitempty"`
+}
+
+func (x *SignatureZKP) Reset() {
+	*x = SignatureZKP{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zkp_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SignatureZKP) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignatureZKP) ProtoMessage() {}
+
+func (x *SignatureZKP) ProtoReflect() protoreflect.Message {
+	mi := &file_zkp_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignatureZKP.ProtoReflect.Descriptor instead.
+func (*SignatureZKP) Descriptor() ([]byte, []int) {
+	return file_zkp_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SignatureZKP) GetSig() []byte {
+	if x != nil {
+		return x.Sig
+	}
+	return nil
+}
+
+func (x *SignatureZKP) GetCommit() *PedCommit {
+	if x != nil {
+		return x.Commit
+	}
+	return nil
+}
+
+func (x *SignatureZKP) GetCommitData() *Ec {
+	if x != nil {
+		return x.CommitData
+	}
+	return nil
+}
+
+func (x *SignatureZKP) GetRData() []byte {
+	if x != nil {
+		return x.RData
+	}
+	return nil
+}
+
+func (x *SignatureZKP) GetPubKey() []byte {
+	if x != nil {
+		return x.PubKey
+	}
+	return nil
+}
+
+func (x *SignatureZKP) GetGenVersion() uint32 {
+	if x != nil {
+		return x.GenVersion
+	}
+	return 0
+}
+
+func (x *SignatureZKP) GetScheme() uint32 {
+	if x != nil {
+		return x.Scheme
+	}
+	return 0
+}
+
+func (x *SignatureZKP) GetCommitField() []byte {
+	if x != nil {
+		return x.CommitField
+	}
+	return nil
+}
+
+func (x *SignatureZKP) GetCircuitVersion() uint32 {
+	if x != nil {
+		return x.CircuitVersion
+	}
+	return 0
+}
+
+func (x *SignatureZKP) GetSigScheme() uint32 {
+	if x != nil {
+		return x.SigScheme
+	}
+	return 0
+}
+
+type AuthProof struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Groth16 proof in gnark's binary format
+	ZkProof []byte        `protobuf:"bytes,1,opt,name=zk_proof,json=zkProof,proto3" json:"zk_proof,omitempty"`
+	Commits []*Ec         `protobuf:"bytes,2,rep,name=commits,proto3" json:"commits,omitempty"`
+	Sign    *SignatureZKP `protobuf:"bytes,3,opt,name=sign,proto3" json:"sign,omitempty"`
+}
+
+func (x *AuthProof) Reset() {
+	*x = AuthProof{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zkp_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AuthProof) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuthProof) ProtoMessage() {}
+
+func (x *AuthProof) ProtoReflect() protoreflect.Message {
+	mi := &file_zkp_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuthProof.ProtoReflect.Descriptor instead.
+func (*AuthProof) Descriptor() ([]byte, []int) {
+	return file_zkp_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *AuthProof) GetZkProof() []byte {
+	if x != nil {
+		return x.ZkProof
+	}
+	return nil
+}
+
+func (x *AuthProof) GetCommits() []*Ec {
+	if x != nil {
+		return x.Commits
+	}
+	return nil
+}
+
+func (x *AuthProof) GetSign() *SignatureZKP {
+	if x != nil {
+		return x.Sign
+	}
+	return nil
+}
+
+type VecEnc struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key []byte `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Iv  []byte `protobuf:"bytes,2,opt,name=iv,proto3" json:"iv,omitempty"`
+	Val []byte `protobuf:"bytes,3,opt,name=val,proto3" json:"val,omitempty"`
+	// encoding of the vector before encryption, see encryption.VecEnc
+	Encoding string `protobuf:"bytes,4,opt,name=encoding,proto3" json:"encoding,omitempty"`
+}
+
+func (x *VecEnc) Reset() {
+	*x = VecEnc{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zkp_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VecEnc) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VecEnc) ProtoMessage() {}
+
+func (x *VecEnc) ProtoReflect() protoreflect.Message {
+	mi := &file_zkp_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VecEnc.ProtoReflect.Descriptor instead.
+func (*VecEnc) Descriptor() ([]byte, []int) {
+	return file_zkp_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *VecEnc) GetKey() []byte {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *VecEnc) GetIv() []byte {
+	if x != nil {
+		return x.Iv
+	}
+	return nil
+}
+
+func (x *VecEnc) GetVal() []byte {
+	if x != nil {
+		return x.Val
+	}
+	return nil
+}
+
+func (x *VecEnc) GetEncoding() string {
+	if x != nil {
+		return x.Encoding
+	}
+	return ""
+}
+
+type ProveRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// signed csv, as written by signature.WriteSignCsv
+	Csv string `protobuf:"bytes,1,opt,name=csv,proto3" json:"csv,omitempty"`
+	// NaCl public keys of the 3 MPC nodes
+	PubKeys [][]byte `protobuf:"bytes,2,rep,name=pub_keys,json=pubKeys,proto3" json:"pub_keys,omitempty"`
+}
+
+func (x *ProveRequest) Reset() {
+	*x = ProveRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zkp_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProveRequest) ProtoMessage() {}
+
+func (x *ProveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_zkp_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProveRequest.ProtoReflect.Descriptor instead.
+func (*ProveRequest) Descriptor() ([]byte, []int) {
+	return file_zkp_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ProveRequest) GetCsv() string {
+	if x != nil {
+		return x.Csv
+	}
+	return ""
+}
+
+func (x *ProveRequest) GetPubKeys() [][]byte {
+	if x != nil {
+		return x.PubKeys
+	}
+	return nil
+}
+
+type ProveResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// encrypted shares, one for each node
+	Shares []*VecEnc  `protobuf:"bytes,1,rep,name=shares,proto3" json:"shares,omitempty"`
+	Cols   []string   `protobuf:"bytes,2,rep,name=cols,proto3" json:"cols,omitempty"`
+	Auth   *AuthProof `protobuf:"bytes,3,opt,name=auth,proto3" json:"auth,omitempty"`
+}
+
+func (x *ProveResponse) Reset() {
+	*x = ProveResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zkp_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ProveResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProveResponse) ProtoMessage() {}
+
+func (x *ProveResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_zkp_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProveResponse.ProtoReflect.Descriptor instead.
+func (*ProveResponse) Descriptor() ([]byte, []int) {
+	return file_zkp_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ProveResponse) GetShares() []*VecEnc {
+	if x != nil {
+		return x.Shares
+	}
+	return nil
+}
+
+func (x *ProveResponse) GetCols() []string {
+	if x != nil {
+		return x.Cols
+	}
+	return nil
+}
+
+func (x *ProveResponse) GetAuth() *AuthProof {
+	if x != nil {
+		return x.Auth
+	}
+	return nil
+}
+
+type VerifyRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	NodeId uint32 `protobuf:"varint,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	// decrypted share of the node, one element per entry
+	Share [][]byte   `protobuf:"bytes,2,rep,name=share,proto3" json:"share,omitempty"`
+	Cols  []string   `protobuf:"bytes,3,rep,name=cols,proto3" json:"cols,omitempty"`
+	Auth  *AuthProof `protobuf:"bytes,4,opt,name=auth,proto3" json:"auth,omitempty"`
+}
+
+func (x *VerifyRequest) Reset() {
+	*x = VerifyRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zkp_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VerifyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyRequest) ProtoMessage() {}
+
+func (x *VerifyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_zkp_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyRequest.ProtoReflect.Descriptor instead.
+func (*VerifyRequest) Descriptor() ([]byte, []int) {
+	return file_zkp_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *VerifyRequest) GetNodeId() uint32 {
+	if x != nil {
+		return x.NodeId
+	}
+	return 0
+}
+
+func (x *VerifyRequest) GetShare() [][]byte {
+	if x != nil {
+		return x.Share
+	}
+	return nil
+}
+
+func (x *VerifyRequest) GetCols() []string {
+	if x != nil {
+		return x.Cols
+	}
+	return nil
+}
+
+func (x *VerifyRequest) GetAuth() *AuthProof {
+	if x != nil {
+		return x.Auth
+	}
+	return nil
+}
+
+type VerifyResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Valid bool   `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+	Error string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (x *VerifyResponse) Reset() {
+	*x = VerifyResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_zkp_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VerifyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyResponse) ProtoMessage() {}
+
+func (x *VerifyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_zkp_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyResponse.ProtoReflect.Descriptor instead.
+func (*VerifyResponse) Descriptor() ([]byte, []int) {
+	return file_zkp_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *VerifyResponse) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *VerifyResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+var File_zkp_proto protoreflect.FileDescriptor
+
+var file_zkp_proto_rawDesc = []byte{
+	0x0a, 0x09, 0x7a, 0x6b, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0d, 0x6b, 0x72, 0x61,
+	0x6b, 0x65, 0x6e, 0x2e, 0x7a, 0x6b, 0x70, 0x2e, 0x76, 0x31, 0x22, 0x20, 0x0a, 0x02, 0x45, 0x63,
+	0x12, 0x0c, 0x0a, 0x01, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x01, 0x78, 0x12, 0x0c,
+	0x0a, 0x01, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x01, 0x79, 0x22, 0x27, 0x0a, 0x09,
+	0x50, 0x65, 0x64, 0x43, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x12, 0x0c, 0x0a, 0x01, 0x72, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x01, 0x72, 0x12, 0x0c, 0x0a, 0x01, 0x63, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x01, 0x63, 0x22, 0xda, 0x02, 0x0a, 0x0c, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74,
+	0x75, 0x72, 0x65, 0x5a, 0x4b, 0x50, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x69, 0x67, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x03, 0x73, 0x69, 0x67, 0x12, 0x30, 0x0a, 0x06, 0x63, 0x6f, 0x6d, 0x6d,
+	0x69, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x6b, 0x72, 0x61, 0x6b, 0x65,
+	0x6e, 0x2e, 0x7a, 0x6b, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x65, 0x64, 0x43, 0x6f, 0x6d, 0x6d,
+	0x69, 0x74, 0x52, 0x06, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x12, 0x32, 0x0a, 0x0b, 0x63, 0x6f,
+	0x6d, 0x6d, 0x69, 0x74, 0x5f, 0x64, 0x61, 0x74, 0x61, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x11, 0x2e, 0x6b, 0x72, 0x61, 0x6b, 0x65, 0x6e, 0x2e, 0x7a, 0x6b, 0x70, 0x2e, 0x76, 0x31, 0x2e,
+	0x45, 0x63, 0x52, 0x0a, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x44, 0x61, 0x74, 0x61, 0x12, 0x15,
+	0x0a, 0x06, 0x72, 0x5f, 0x64, 0x61, 0x74, 0x61, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05,
+	0x72, 0x44, 0x61, 0x74, 0x61, 0x12, 0x17, 0x0a, 0x07, 0x70, 0x75, 0x62, 0x5f, 0x6b, 0x65, 0x79,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x70, 0x75, 0x62, 0x4b, 0x65, 0x79, 0x12, 0x1f,
+	0x0a, 0x0b, 0x67, 0x65, 0x6e, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x0a, 0x67, 0x65, 0x6e, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12,
+	0x16, 0x0a, 0x06, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x06, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x6f, 0x6d, 0x6d, 0x69,
+	0x74, 0x5f, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0b, 0x63,
+	0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x12, 0x27, 0x0a, 0x0f, 0x63, 0x69,
+	0x72, 0x63, 0x75, 0x69, 0x74, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x09, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x0e, 0x63, 0x69, 0x72, 0x63, 0x75, 0x69, 0x74, 0x56, 0x65, 0x72, 0x73,
+	0x69, 0x6f, 0x6e, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x69, 0x67, 0x5f, 0x73, 0x63, 0x68, 0x65, 0x6d,
+	0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x73, 0x69, 0x67, 0x53, 0x63, 0x68, 0x65,
+	0x6d, 0x65, 0x22, 0x84, 0x01, 0x0a, 0x09, 0x41, 0x75, 0x74, 0x68, 0x50, 0x72, 0x6f, 0x6f, 0x66,
+	0x12, 0x19, 0x0a, 0x08, 0x7a, 0x6b, 0x5f, 0x70, 0x72, 0x6f, 0x6f, 0x66, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x07, 0x7a, 0x6b, 0x50, 0x72, 0x6f, 0x6f, 0x66, 0x12, 0x2b, 0x0a, 0x07, 0x63,
+	0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x6b,
+	0x72, 0x61, 0x6b, 0x65, 0x6e, 0x2e, 0x7a, 0x6b, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x63, 0x52,
+	0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x73, 0x12, 0x2f, 0x0a, 0x04, 0x73, 0x69, 0x67, 0x6e,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x6b, 0x72, 0x61, 0x6b, 0x65, 0x6e, 0x2e,
+	0x7a, 0x6b, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65,
+	0x5a, 0x4b, 0x50, 0x52, 0x04, 0x73, 0x69, 0x67, 0x6e, 0x22, 0x58, 0x0a, 0x06, 0x56, 0x65, 0x63,
+	0x45, 0x6e, 0x63, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x76, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x02, 0x69, 0x76, 0x12, 0x10, 0x0a, 0x03, 0x76, 0x61, 0x6c, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x03, 0x76, 0x61, 0x6c, 0x12, 0x1a, 0x0a, 0x08, 0x65, 0x6e, 0x63, 0x6f, 0x64,
+	0x69, 0x6e, 0x67, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x65, 0x6e, 0x63, 0x6f, 0x64,
+	0x69, 0x6e, 0x67, 0x22, 0x3b, 0x0a, 0x0c, 0x50, 0x72, 0x6f, 0x76, 0x65, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x63, 0x73, 0x76, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x03, 0x63, 0x73, 0x76, 0x12, 0x19, 0x0a, 0x08, 0x70, 0x75, 0x62, 0x5f, 0x6b, 0x65, 0x79,
+	0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x07, 0x70, 0x75, 0x62, 0x4b, 0x65, 0x79, 0x73,
+	0x22, 0x80, 0x01, 0x0a, 0x0d, 0x50, 0x72, 0x6f, 0x76, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x2d, 0x0a, 0x06, 0x73, 0x68, 0x61, 0x72, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x15, 0x2e, 0x6b, 0x72, 0x61, 0x6b, 0x65, 0x6e, 0x2e, 0x7a, 0x6b, 0x70, 0x2e,
+	0x76, 0x31, 0x2e, 0x56, 0x65, 0x63, 0x45, 0x6e, 0x63, 0x52, 0x06, 0x73, 0x68, 0x61, 0x72, 0x65,
+	0x73, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f, 0x6c, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x04, 0x63, 0x6f, 0x6c, 0x73, 0x12, 0x2c, 0x0a, 0x04, 0x61, 0x75, 0x74, 0x68, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x6b, 0x72, 0x61, 0x6b, 0x65, 0x6e, 0x2e, 0x7a, 0x6b, 0x70,
+	0x2e, 0x76, 0x31, 0x2e, 0x41, 0x75, 0x74, 0x68, 0x50, 0x72, 0x6f, 0x6f, 0x66, 0x52, 0x04, 0x61,
+	0x75, 0x74, 0x68, 0x22, 0x80, 0x01, 0x0a, 0x0d, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x6e, 0x6f, 0x64, 0x65, 0x5f, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x6e, 0x6f, 0x64, 0x65, 0x49, 0x64, 0x12, 0x14,
+	0x0a, 0x05, 0x73, 0x68, 0x61, 0x72, 0x65, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x05, 0x73,
+	0x68, 0x61, 0x72, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f, 0x6c, 0x73, 0x18, 0x03, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x04, 0x63, 0x6f, 0x6c, 0x73, 0x12, 0x2c, 0x0a, 0x04, 0x61, 0x75, 0x74, 0x68,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x6b, 0x72, 0x61, 0x6b, 0x65, 0x6e, 0x2e,
+	0x7a, 0x6b, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x75, 0x74, 0x68, 0x50, 0x72, 0x6f, 0x6f, 0x66,
+	0x52, 0x04, 0x61, 0x75, 0x74, 0x68, 0x22, 0x3c, 0x0a, 0x0e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x12, 0x14,
+	0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65,
+	0x72, 0x72, 0x6f, 0x72, 0x32, 0x4c, 0x0a, 0x06, 0x50, 0x72, 0x6f, 0x76, 0x65, 0x72, 0x12, 0x42,
+	0x0a, 0x05, 0x50, 0x72, 0x6f, 0x76, 0x65, 0x12, 0x1b, 0x2e, 0x6b, 0x72, 0x61, 0x6b, 0x65, 0x6e,
+	0x2e, 0x7a, 0x6b, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x72, 0x6f, 0x76, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x6b, 0x72, 0x61, 0x6b, 0x65, 0x6e, 0x2e, 0x7a, 0x6b,
+	0x70, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x72, 0x6f, 0x76, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x32, 0x51, 0x0a, 0x08, 0x56, 0x65, 0x72, 0x69, 0x66, 0x69, 0x65, 0x72, 0x12, 0x45,
+	0x0a, 0x06, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x12, 0x1c, 0x2e, 0x6b, 0x72, 0x61, 0x6b, 0x65,
+	0x6e, 0x2e, 0x7a, 0x6b, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x6b, 0x72, 0x61, 0x6b, 0x65, 0x6e, 0x2e,
+	0x7a, 0x6b, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x31, 0x5a, 0x2f, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e,
+	0x63, 0x6f, 0x6d, 0x2f, 0x6b, 0x72, 0x61, 0x6b, 0x65, 0x6e, 0x68, 0x32, 0x30, 0x32, 0x30, 0x2f,
+	0x5a, 0x4b, 0x50, 0x43, 0x6f, 0x6d, 0x70, 0x6f, 0x6e, 0x65, 0x6e, 0x74, 0x2f, 0x77, 0x69, 0x72,
+	0x65, 0x5f, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_zkp_proto_rawDescOnce sync.Once
+	file_zkp_proto_rawDescData = file_zkp_proto_rawDesc
+)
+
+func file_zkp_proto_rawDescGZIP() []byte {
+	file_zkp_proto_rawDescOnce.Do(func() {
+		file_zkp_proto_rawDescData = protoimpl.X.CompressGZIP(file_zkp_proto_rawDescData)
+	})
+	return file_zkp_proto_rawDescData
+}
+
+var file_zkp_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_zkp_proto_goTypes = []interface{}{
+	(*Ec)(nil),             // 0: kraken.zkp.v1.Ec
+	(*PedCommit)(nil),      // 1: kraken.zkp.v1.PedCommit
+	(*SignatureZKP)(nil),   // 2: kraken.zkp.v1.SignatureZKP
+	(*AuthProof)(nil),      // 3: kraken.zkp.v1.AuthProof
+	(*VecEnc)(nil),         // 4: kraken.zkp.v1.VecEnc
+	(*ProveRequest)(nil),   // 5: kraken.zkp.v1.ProveRequest
+	(*ProveResponse)(nil),  // 6: kraken.zkp.v1.ProveResponse
+	(*VerifyRequest)(nil),  // 7: kraken.zkp.v1.VerifyRequest
+	(*VerifyResponse)(nil), // 8: kraken.zkp.v1.VerifyResponse
+}
+var file_zkp_proto_depIdxs = []int32{
+	1, // 0: kraken.zkp.v1.SignatureZKP.commit:type_name -> kraken.zkp.v1.PedCommit
+	0, // 1: kraken.zkp.v1.SignatureZKP.commit_data:type_name -> kraken.zkp.v1.Ec
+	0, // 2: kraken.zkp.v1.AuthProof.commits:type_name -> kraken.zkp.v1.Ec
+	2, // 3: kraken.zkp.v1.AuthProof.sign:type_name -> kraken.zkp.v1.SignatureZKP
+	4, // 4: kraken.zkp.v1.ProveResponse.shares:type_name -> kraken.zkp.v1.VecEnc
+	3, // 5: kraken.zkp.v1.ProveResponse.auth:type_name -> kraken.zkp.v1.AuthProof
+	3, // 6: kraken.zkp.v1.VerifyRequest.auth:type_name -> kraken.zkp.v1.AuthProof
+	5, // 7: kraken.zkp.v1.Prover.Prove:input_type -> kraken.zkp.v1.ProveRequest
+	7, // 8: kraken.zkp.v1.Verifier.Verify:input_type -> kraken.zkp.v1.VerifyRequest
+	6, // 9: kraken.zkp.v1.Prover.Prove:output_type -> kraken.zkp.v1.ProveResponse
+	8, // 10: kraken.zkp.v1.Verifier.Verify:output_type -> kraken.zkp.v1.VerifyResponse
+	9, // [9:11] is the sub-list for method output_type
+	7, // [7:9] is the sub-list for method input_type
+	7, // [7:7] is the sub-list for extension type_name
+	7, // [7:7] is the sub-list for extension extendee
+	0, // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_zkp_proto_init() }
+func file_zkp_proto_init() {
+	if File_zkp_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_zkp_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Ec); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zkp_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PedCommit); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zkp_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SignatureZKP); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zkp_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AuthProof); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zkp_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VecEnc); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zkp_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ProveRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zkp_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ProveResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zkp_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VerifyRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_zkp_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VerifyResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_zkp_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   2,
+		},
+		GoTypes:           file_zkp_proto_goTypes,
+		DependencyIndexes: file_zkp_proto_depIdxs,
+		MessageInfos:      file_zkp_proto_msgTypes,
+	}.Build()
+	File_zkp_proto = out.File
+	file_zkp_proto_rawDesc = nil
+	file_zkp_proto_goTypes = nil
+	file_zkp_proto_depIdxs = nil
+}
@@ -0,0 +1,103 @@
+package ZKPComponent
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/krakenh2020/ZKPComponent/proof_aggregation"
+)
+
+// datasetInputs returns the public inputs of CircuitDataset for the
+// AuthProof of a dataset of the columns.
+func datasetInputs(aProof *AuthProof, cols []string) ([]fr.Element, error) {
+	if aProof.Sign == nil || len(aProof.Commits) != 3 {
+		return nil, fmt.Errorf("incomplete authentication proof")
+	}
+	_, commits, sign, pubKey, err := ExpandAuthProof(aProof)
+	if err != nil {
+		return nil, err
+	}
+	publicWitness, err := datasetPublicWitness(commits, cols, sign, pubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return publicInputs(publicWitness)
+}
+
+// aggregatedCircuit returns the circuit of the AuthProofs, which must
+// all be of the same one.
+func aggregatedCircuit(aProofs []*AuthProof) (CircuitId, error) {
+	var id CircuitId
+	for i := range aProofs {
+		if aProofs[i].Sign == nil {
+			return CircuitId{}, fmt.Errorf("incomplete authentication proof")
+		}
+		other := DatasetCircuitId(aProofs[i].Sign)
+		if i > 0 && other != id {
+			return CircuitId{}, fmt.Errorf("proofs of circuits %s and %s", id, other)
+		}
+		id = other
+	}
+
+	return id, nil
+}
+
+// AggregateDatasetProofs aggregates the proofs of the AuthProofs of
+// datasets of the columns, all of the same circuit, into a single proof.
+func AggregateDatasetProofs(srs *proof_aggregation.SRS, aProofs []*AuthProof, cols [][]string) (*proof_aggregation.Proof, error) {
+	if len(aProofs) != len(cols) {
+		return nil, fmt.Errorf("number of proofs and columns differ")
+	}
+	_, err := aggregatedCircuit(aProofs)
+	if err != nil {
+		return nil, err
+	}
+	proofs := make([]groth16.Proof, len(aProofs))
+	inputs := make([][]fr.Element, len(aProofs))
+	for i := range aProofs {
+		inputs[i], err = datasetInputs(aProofs[i], cols[i])
+		if err != nil {
+			return nil, err
+		}
+		proofs[i], _, _, _, err = ExpandAuthProof(aProofs[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return proof_aggregation.Aggregate(srs, proofs, inputs)
+}
+
+// VerifyAggregatedDatasetProofs verifies the aggregated proof of the
+// datasets like VerifyDatasetZKp does for each of them. The proofs in
+// the AuthProofs are not used, only their public data. The verifying
+// key is the one of keys for the circuit of their signatures.
+func VerifyAggregatedDatasetProofs(srs *proof_aggregation.VerifierSRS, keys VerifyingKeys,
+	proof *proof_aggregation.Proof, aProofs []*AuthProof, cols [][]string) (bool, error) {
+	if len(aProofs) != len(cols) {
+		return false, fmt.Errorf("number of proofs and columns differ")
+	}
+	id, err := aggregatedCircuit(aProofs)
+	if err != nil {
+		return false, err
+	}
+	verKey, err := keys.VerifyingKey(id)
+	if err != nil {
+		return false, err
+	}
+	inputs := make([][]fr.Element, len(aProofs))
+	for i := range aProofs {
+		inputs[i], err = datasetInputs(aProofs[i], cols[i])
+		if err != nil {
+			return false, err
+		}
+	}
+	err = proof_aggregation.Verify(srs, verKey, inputs, proof)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,43 @@
+package ZKPComponent
+
+import (
+	"testing"
+
+	"github.com/krakenh2020/ZKPComponent/proof_aggregation"
+	"github.com/krakenh2020/ZKPComponent/signature"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregateDatasetProofs(t *testing.T) {
+	_, aProofs, cols, keys := signedDatasets(t, 3)
+	srs, err := proof_aggregation.GenerateSRS(2)
+	assert.NoError(t, err)
+
+	proof, err := AggregateDatasetProofs(srs, aProofs, cols)
+	assert.NoError(t, err)
+	check, err := VerifyAggregatedDatasetProofs(srs.VerifierSRS(), keys, proof, aProofs, cols)
+	assert.NoError(t, err)
+	assert.True(t, check)
+
+	// the datasets in another order
+	aProofs[0], aProofs[1] = aProofs[1], aProofs[0]
+	check, err = VerifyAggregatedDatasetProofs(srs.VerifierSRS(), keys, proof, aProofs, cols)
+	assert.Error(t, err)
+	assert.False(t, check)
+
+	_, err = AggregateDatasetProofs(srs, aProofs, cols[:2])
+	assert.Error(t, err)
+
+	// no key of the circuit
+	aProofs[0], aProofs[1] = aProofs[1], aProofs[0]
+	check, err = VerifyAggregatedDatasetProofs(srs.VerifierSRS(), NewVerifyingKeySet(), proof, aProofs, cols)
+	assert.Error(t, err)
+	assert.False(t, check)
+
+	// proofs of different circuits
+	sign := *aProofs[0].Sign
+	sign.SigScheme = signature.SignatureSchemeEd25519
+	aProofs[0].Sign = &sign
+	_, err = AggregateDatasetProofs(srs, aProofs, cols)
+	assert.Error(t, err)
+}
@@ -0,0 +1,284 @@
+package ZKPComponent
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	sig "github.com/consensys/gnark-crypto/signature"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/krakenh2020/ZKPComponent/proof_aggregation"
+	"github.com/krakenh2020/ZKPComponent/signature"
+	"github.com/krakenh2020/ZKPComponent/signature/ec"
+)
+
+// batchWeightBits is the bit length of the random weights combining the
+// checks of a batch: a batch with an invalid proof passes with
+// probability at most 2^-128.
+const batchWeightBits = 128
+
+// SplitProof is the share of a node and the authentication proof of
+// its dataset, the arguments of VerifyDatasetSplitAndZKpCsv.
+type SplitProof struct {
+	Proof   groth16.Proof
+	Split   []*big.Int
+	Id      int
+	Commits []*ec.Ec
+	Cols    []string
+	Sign    *signature.SignatureZKP
+	PubKey  sig.PublicKey
+}
+
+// publicInputs returns the elements of the public witness.
+func publicInputs(w witness.Witness) ([]fr.Element, error) {
+	vector, ok := w.Vector().(fr.Vector)
+	if !ok {
+		return nil, fmt.Errorf("invalid public witness")
+	}
+
+	return vector, nil
+}
+
+// batchEntry is a proof of a batch ready for the combined checks.
+type batchEntry struct {
+	index  int
+	proof  *proof_aggregation.Groth16Proof
+	inputs []fr.Element
+	weight fr.Element
+}
+
+// check returns an error if the proof is incomplete.
+func (p *SplitProof) check() error {
+	if p.Sign == nil || len(p.Commits) != 3 {
+		return fmt.Errorf("incomplete authentication proof")
+	}
+	for _, c := range p.Commits {
+		if c == nil {
+			return fmt.Errorf("missing commitment")
+		}
+	}
+	if p.Id < 0 || p.Id >= 3 {
+		return fmt.Errorf("invalid node id %d", p.Id)
+	}
+	if len(p.Split) == 0 {
+		return fmt.Errorf("empty share")
+	}
+
+	return nil
+}
+
+func (p *SplitProof) prepare(vk *proof_aggregation.Groth16Key) (*proof_aggregation.Groth16Proof, []fr.Element, error) {
+	publicWitness, err := datasetPublicWitness(p.Commits, p.Cols, p.Sign, p.PubKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	inputs, err := publicInputs(publicWitness)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(inputs) != len(vk.K)-1 {
+		return nil, nil, fmt.Errorf("public witness of wrong length")
+	}
+	proof, err := proof_aggregation.DecodeProof(p.Proof)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return proof, inputs, nil
+}
+
+// VerifyDatasetSplitAndZKpCsvBatch verifies the proofs like
+// VerifyDatasetSplitAndZKpCsv, with the verifying key of the circuit of
+// the signature of each proof, see DatasetCircuitId, and returns the
+// error of each proof, nil if it is valid. The Groth16 proofs of a
+// circuit are checked together with a random linear combination of
+// their pairing equations, sharing the final exponentiation, and the
+// commitments of the shares with the same generators and length are
+// checked with a single commitment to a random linear combination of
+// the shares. If a combined check fails, the proofs are verified one by
+// one to find the invalid ones. The proofs of circuits with commitments
+// of gnark, as CircuitDatasetECDSA, are verified one by one.
+func VerifyDatasetSplitAndZKpCsvBatch(keys VerifyingKeys, proofs []SplitProof) []error {
+	errs := make([]error, len(proofs))
+	var order []CircuitId
+	circuits := make(map[CircuitId][]int)
+	for i := range proofs {
+		if proofs[i].Sign == nil {
+			errs[i] = fmt.Errorf("incomplete authentication proof")
+			continue
+		}
+		id := DatasetCircuitId(proofs[i].Sign)
+		if _, ok := circuits[id]; !ok {
+			order = append(order, id)
+		}
+		circuits[id] = append(circuits[id], i)
+	}
+
+	for _, id := range order {
+		verKey, err := keys.VerifyingKey(id)
+		if err != nil {
+			for _, i := range circuits[id] {
+				errs[i] = err
+			}
+			continue
+		}
+		verifyBatch(verKey, proofs, circuits[id], errs)
+	}
+
+	return errs
+}
+
+// verifyBatch verifies the proofs of the indexes, of the circuit of
+// verKey, and sets their errors.
+func verifyBatch(verKey groth16.VerifyingKey, proofs []SplitProof, indexes []int, errs []error) {
+	var batch []batchEntry
+	vk, err := proof_aggregation.DecodeVerifyingKey(verKey)
+	if err == nil {
+		for _, i := range indexes {
+			if errs[i] = proofs[i].check(); errs[i] != nil {
+				continue
+			}
+			proof, inputs, err := proofs[i].prepare(vk)
+			if err != nil {
+				errs[i] = err
+				continue
+			}
+			e := batchEntry{index: i, proof: proof, inputs: inputs}
+			w, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), batchWeightBits))
+			if err != nil {
+				errs[i] = err
+				continue
+			}
+			e.weight.SetBigInt(w.Add(w, big.NewInt(1)))
+			batch = append(batch, e)
+		}
+		if len(batch) == 0 {
+			return
+		}
+
+		ok, err := verifyGroth16Batch(vk, batch)
+		if err == nil && ok {
+			ok, err = verifyCommitsBatch(proofs, batch)
+		}
+		if err == nil && ok {
+			return
+		}
+	} else {
+		for _, i := range indexes {
+			if errs[i] = proofs[i].check(); errs[i] == nil {
+				batch = append(batch, batchEntry{index: i})
+			}
+		}
+	}
+
+	// fallback to the individual verification
+	for _, e := range batch {
+		p := &proofs[e.index]
+		check, err := VerifyDatasetSplitAndZKpCsv(p.Proof, verKey, p.Split, p.Id, p.Commits, p.Cols, p.Sign, p.PubKey)
+		if err == nil && !check {
+			err = fmt.Errorf("share not authenticated")
+		}
+		errs[e.index] = err
+	}
+}
+
+// verifyGroth16Batch checks
+// prod_j e(r_j A_j, B_j) = e((sum_j r_j) α, β) e(sum_j r_j vk_x_j, γ) e(sum_j r_j C_j, δ)
+// for the weights r_j, vk_x_j being the combination of the points of
+// the public wires with the public inputs of proof j.
+func verifyGroth16Batch(vk *proof_aggregation.Groth16Key, batch []batchEntry) (bool, error) {
+	n := len(batch)
+	g1 := make([]curve.G1Affine, 0, n+3)
+	g2 := make([]curve.G2Affine, 0, n+3)
+
+	var sumWeights fr.Element
+	inputs := make([]fr.Element, len(vk.K))
+	c := make([]curve.G1Affine, n)
+	weights := make([]fr.Element, n)
+	var tmp fr.Element
+	var w big.Int
+	for j, e := range batch {
+		var a curve.G1Affine
+		a.ScalarMultiplication(&e.proof.A, e.weight.BigInt(&w))
+		g1 = append(g1, a)
+		g2 = append(g2, e.proof.B)
+
+		sumWeights.Add(&sumWeights, &e.weight)
+		for i := range e.inputs {
+			tmp.Mul(&e.inputs[i], &e.weight)
+			inputs[i+1].Add(&inputs[i+1], &tmp)
+		}
+		c[j] = e.proof.C
+		weights[j] = e.weight
+	}
+	inputs[0] = sumWeights
+
+	cfg := ecc.MultiExpConfig{}
+	var vkX, cSum, alpha curve.G1Affine
+	if _, err := vkX.MultiExp(vk.K, inputs, cfg); err != nil {
+		return false, err
+	}
+	if _, err := cSum.MultiExp(c, weights, cfg); err != nil {
+		return false, err
+	}
+	alpha.ScalarMultiplication(&vk.Alpha, sumWeights.BigInt(&w))
+
+	alpha.Neg(&alpha)
+	vkX.Neg(&vkX)
+	cSum.Neg(&cSum)
+	g1 = append(g1, alpha, vkX, cSum)
+	g2 = append(g2, vk.Beta2, vk.Gamma2, vk.Delta2)
+
+	return curve.PairingCheck(g1, g2)
+}
+
+// verifyCommitsBatch checks sum_j r_j Commits_j[Id_j] = sum_j r_j
+// CommitShareSpecial(Split_j) for the weights r_j, one commitment per
+// generator version and share length.
+func verifyCommitsBatch(proofs []SplitProof, batch []batchEntry) (bool, error) {
+	type group struct {
+		version signature.GeneratorVersion
+		length  int
+	}
+	var order []group
+	groups := make(map[group][]batchEntry)
+	for _, e := range batch {
+		p := &proofs[e.index]
+		g := group{version: p.Sign.GenVersion, length: len(p.Split)}
+		if _, ok := groups[g]; !ok {
+			order = append(order, g)
+		}
+		groups[g] = append(groups[g], e)
+	}
+
+	for _, g := range order {
+		entries := groups[g]
+		commits := make([]*ec.Ec, len(entries))
+		splits := make([][]*big.Int, len(entries))
+		weights := make([]*big.Int, len(entries))
+		for j, e := range entries {
+			p := &proofs[e.index]
+			commits[j] = p.Commits[p.Id]
+			splits[j] = p.Split
+			weights[j] = e.weight.BigInt(new(big.Int))
+		}
+		lhs, err := ec.MultiScalarMult(context.Background(), commits, weights, 0)
+		if err != nil {
+			return false, err
+		}
+		rhs, err := signature.CommitSharesCombination(context.Background(), g.version, splits, weights)
+		if err != nil {
+			return false, err
+		}
+		if !lhs.Equal(rhs) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
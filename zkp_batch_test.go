@@ -0,0 +1,129 @@
+package ZKPComponent
+
+import (
+	"crypto/rand"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	sig "github.com/consensys/gnark-crypto/signature"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/constraint"
+	"github.com/krakenh2020/ZKPComponent/key_management"
+	"github.com/krakenh2020/ZKPComponent/signature"
+	"github.com/stretchr/testify/assert"
+)
+
+// signedDatasets returns the shares and the AuthProofs of n datasets
+// signed by different owners, and the verifying keys of their proofs.
+func signedDatasets(t *testing.T, n int) ([][][]*big.Int, []*AuthProof, [][]string, VerifyingKeySet) {
+	pk, err := LoadProvingKey("proofKey.txt")
+	assert.NoError(t, err)
+	keys, err := LoadLegacyVerifyingKeys("verifyKey.txt")
+	assert.NoError(t, err)
+	r1cs, err := CompileCircuitDataset()
+	assert.NoError(t, err)
+	signers := make([]sig.Signer, n)
+	for d := range signers {
+		signers[d], err = eddsa.GenerateKey(rand.Reader)
+		assert.NoError(t, err)
+	}
+	splits, aProofs, cols := signDatasets(t, signers, pk, r1cs)
+
+	return splits, aProofs, cols, keys
+}
+
+// signDatasets returns the shares and the AuthProofs of datasets signed
+// by the signers, proved with the key of the circuit.
+func signDatasets(t *testing.T, signers []sig.Signer, pk groth16.ProvingKey, r1cs constraint.ConstraintSystem) (
+	[][][]*big.Int, []*AuthProof, [][]string) {
+	pubKey, err := key_management.LoadPubKey("test", "key_management/keys")
+	assert.NoError(t, err)
+	dir := t.TempDir()
+
+	var splits [][][]*big.Int
+	var aProofs []*AuthProof
+	var cols [][]string
+	for _, signer := range signers {
+		signed := filepath.Join(dir, "signed.csv")
+		sign, err := signature.SignCsv("datasets/framingham_tiny.csv", signer)
+		assert.NoError(t, err)
+		assert.NoError(t, signature.WriteSignCsv("datasets/framingham_tiny.csv", signed, sign))
+
+		container := filepath.Join(dir, "container.txt")
+		split, _, _, _, _, err := DatasetSplitEncryptAndZkpCsvToFile(signed, container, pk, r1cs, [][]byte{pubKey, pubKey, pubKey})
+		assert.NoError(t, err)
+		f, err := os.Open(container)
+		assert.NoError(t, err)
+		aProof, c, err := ReadAuthColsFrom(f)
+		f.Close()
+		assert.NoError(t, err)
+		splits = append(splits, split)
+		aProofs = append(aProofs, aProof)
+		cols = append(cols, c)
+	}
+
+	return splits, aProofs, cols
+}
+
+func TestVerifyDatasetSplitAndZKpCsvBatch(t *testing.T) {
+	splits, aProofs, cols, keys := signedDatasets(t, 2)
+
+	// a dataset signed with Ed25519, of CircuitDatasetOpening
+	r1cs, err := CompileCircuitDatasetOpening(signature.CurrentCircuitVersion)
+	assert.NoError(t, err)
+	pk, vk, err := groth16.Setup(r1cs)
+	assert.NoError(t, err)
+	keys[CircuitId{Name: CircuitDatasetOpeningName, Version: signature.CurrentCircuitVersion}] = vk
+	ed25519Key, err := signature.GenerateEd25519(rand.Reader)
+	assert.NoError(t, err)
+	splits2, aProofs2, cols2 := signDatasets(t, []sig.Signer{ed25519Key}, pk, r1cs)
+	splits, aProofs, cols = append(splits, splits2...), append(aProofs, aProofs2...), append(cols, cols2...)
+
+	var proofs []SplitProof
+	for d := range aProofs {
+		proof, commits, sign, pubKey, err := ExpandAuthProof(aProofs[d])
+		assert.NoError(t, err)
+		for i := 0; i < 3; i++ {
+			proofs = append(proofs, SplitProof{Proof: proof, Split: splits[d][i], Id: i, Commits: commits,
+				Cols: cols[d], Sign: sign, PubKey: pubKey})
+		}
+	}
+
+	for _, err := range VerifyDatasetSplitAndZKpCsvBatch(keys, proofs) {
+		assert.NoError(t, err)
+	}
+
+	// no key of the circuit of the Ed25519 dataset
+	errs := VerifyDatasetSplitAndZKpCsvBatch(NewVerifyingKeySet(), proofs)
+	for i := range proofs {
+		assert.Error(t, errs[i])
+	}
+	errs = VerifyDatasetSplitAndZKpCsvBatch(VerifyingKeySet{
+		{Name: CircuitDatasetName, Version: signature.CurrentCircuitVersion}: keys[CircuitId{Name: CircuitDatasetName,
+			Version: signature.CurrentCircuitVersion}]}, proofs)
+	for i := range proofs {
+		assert.Equal(t, i >= 6, errs[i] != nil)
+	}
+
+	// a share of the wrong node, a proof of the other dataset and an
+	// incomplete proof are found by the individual verification
+	proofs[1].Split = proofs[2].Split
+	proofs[3].Proof = proofs[0].Proof
+	proofs[5].Sign = nil
+	errs = VerifyDatasetSplitAndZKpCsvBatch(keys, proofs)
+	assert.Len(t, errs, len(proofs))
+	assert.NoError(t, errs[0])
+	assert.Error(t, errs[1])
+	assert.NoError(t, errs[2])
+	assert.Error(t, errs[3])
+	assert.NoError(t, errs[4])
+	assert.Error(t, errs[5])
+
+	// a share tampered with
+	proofs[4].Split = append([]*big.Int{new(big.Int).Add(proofs[4].Split[0], big.NewInt(1))}, proofs[4].Split[1:]...)
+	errs = VerifyDatasetSplitAndZKpCsvBatch(keys, proofs[4:5])
+	assert.Error(t, errs[0])
+}
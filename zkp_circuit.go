@@ -1,10 +1,16 @@
 package ZKPComponent
 
 import (
-	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	tedwards "github.com/consensys/gnark-crypto/ecc/twistededwards"
 	"github.com/consensys/gnark/frontend"
-	"github.com/consensys/gnark/std/algebra/twistededwards"
-	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/consensys/gnark/std/hash/sha2"
+	"github.com/consensys/gnark/std/math/bits"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/std/signature/ecdsa"
 	"github.com/krakenh2020/ZKPComponent/signature"
 )
 
@@ -18,6 +24,10 @@ type CircuitDataset struct {
 	// Signature
 	PublicKey twistededwards.Point `gnark:",public"`
 	Signature Signature            `gnark:",public"`
+
+	// Version selects the hash of the circuit, it is not part of the
+	// witness
+	Version signature.CircuitVersion `gnark:"-"`
 }
 
 type Signature struct {
@@ -26,67 +36,167 @@ type Signature struct {
 }
 
 func (circuit *CircuitDataset) Define(api frontend.API) error {
+	return assertSignedText(api, circuit.Version, circuit.ColsHash, circuit.Commit, circuit.SecTextHash, circuit.R,
+		&circuit.PublicKey, &circuit.Signature)
+}
+
+// CircuitDatasetOpening is CircuitDataset for the signatures verified
+// outside of the circuit, see signature.SignatureScheme.InCircuit: the
+// signed MiMC-Pedersen commitment C is public and the circuit proves
+// that it commits to the columns hash, the commitment to the data and
+// the private text hash.
+type CircuitDatasetOpening struct {
+	ColsHash    frontend.Variable    `gnark:",public"`
+	Commit      frontend.Variable    `gnark:",public"`
+	C           twistededwards.Point `gnark:",public"`
+	SecTextHash frontend.Variable
+	R           frontend.Variable
+
+	Version signature.CircuitVersion `gnark:"-"`
+}
+
+func (circuit *CircuitDatasetOpening) Define(api frontend.API) error {
+	c, _, _, err := commitText(api, circuit.Version, circuit.ColsHash, circuit.Commit, circuit.SecTextHash, circuit.R)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(c.X, circuit.C.X)
+	api.AssertIsEqual(c.Y, circuit.C.Y)
+
+	return nil
+}
+
+// CircuitDatasetECDSA is CircuitDataset for ECDSA over P-256, see
+// signature.SignatureSchemeECDSAP256: the circuit verifies the
+// signature of the SHA-256 of the X coordinate of the MiMC-Pedersen
+// commitment with the emulated arithmetic of P-256.
+type CircuitDatasetECDSA struct {
+	ColsHash    frontend.Variable `gnark:",public"`
+	Commit      frontend.Variable `gnark:",public"`
+	SecTextHash frontend.Variable
+	R           frontend.Variable
+
+	PublicKey ecdsa.PublicKey[emulated.P256Fp, emulated.P256Fr] `gnark:",public"`
+	Signature ecdsa.Signature[emulated.P256Fr]                  `gnark:",public"`
+
+	Version signature.CircuitVersion `gnark:"-"`
+}
+
+func (circuit *CircuitDatasetECDSA) Define(api frontend.API) error {
+	c, _, _, err := commitText(api, circuit.Version, circuit.ColsHash, circuit.Commit, circuit.SecTextHash, circuit.R)
+	if err != nil {
+		return err
+	}
+
+	// SHA-256 of the 32 bytes of c.X, big endian
+	uapi, err := uints.New[uints.U32](api)
+	if err != nil {
+		return err
+	}
+	cBits := bits.ToBinary(api, c.X)
+	cBits = append(cBits, make([]frontend.Variable, 8*fr.Bytes-len(cBits))...)
+	for i := api.Compiler().FieldBitLen(); i < len(cBits); i++ {
+		cBits[i] = 0
+	}
+	h, err := sha2.New(api)
+	if err != nil {
+		return err
+	}
+	for i := fr.Bytes - 1; i >= 0; i-- {
+		b := bits.FromBinary(api, cBits[8*i:8*i+8], bits.WithUnconstrainedInputs())
+		h.Write([]uints.U8{uapi.ByteValueOf(b)})
+	}
+	digest := h.Sum()
+
+	scalarApi, err := emulated.NewField[emulated.P256Fr](api)
+	if err != nil {
+		return err
+	}
+	msgBits := make([]frontend.Variable, 0, 8*len(digest))
+	for i := len(digest) - 1; i >= 0; i-- {
+		msgBits = append(msgBits, bits.ToBinary(api, digest[i].Val, bits.WithNbDigits(8))...)
+	}
+	msg := scalarApi.FromBits(msgBits...)
+
+	circuit.PublicKey.Verify(api, sw_emulated.GetP256Params(), msg, &circuit.Signature)
+
+	return nil
+}
+
+// commitText returns the MiMC-Pedersen commitment with randomness r to
+// the columns hash, the commitment to the data and the private text
+// hash, see signature.MiMCPedersenVersion, with the hash and the curve
+// it used.
+func commitText(api frontend.API, version signature.CircuitVersion, colsHash, commit, secTextHash, r frontend.Variable) (
+	twistededwards.Point, circuitHash, twistededwards.Curve, error) {
 	// hash with MiMC
-	miMC, _ := mimc.NewMiMC(api)
-	miMC.Write(circuit.ColsHash)
-	miMC.Write(circuit.Commit)
-	miMC.Write(circuit.SecTextHash)
+	miMC, err := newCircuitHash(api, version)
+	if err != nil {
+		return twistededwards.Point{}, nil, nil, err
+	}
+	miMC.Write(colsHash)
+	miMC.Write(commit)
+	miMC.Write(secTextHash)
 
 	miMCres := miMC.Sum()
 
 	// Pedersen commit
-	curve, err := twistededwards.NewEdCurve(ecc.BN254)
+	curve, err := twistededwards.NewEdCurve(api, tedwards.BN254)
 	if err != nil {
-		return err
+		return twistededwards.Point{}, nil, nil, err
 	}
 
-	g := &twistededwards.Point{signature.G.X, signature.G.Y}
-	gX := &twistededwards.Point{}
-	gX.X = curve
-	gX.ScalarMul(api, g, miMCres, curve)
-	gX.MustBeOnCurve(api, curve)
+	g := twistededwards.Point{X: signature.G.X, Y: signature.G.Y}
+	gX := curve.ScalarMul(g, miMCres)
+	curve.AssertIsOnCurve(gX)
 
-	h := &twistededwards.Point{signature.H.X, signature.H.Y}
-	hR := &twistededwards.Point{}
-	hR.ScalarMul(api, h, circuit.R, curve)
-	hR.MustBeOnCurve(api, curve)
+	h := twistededwards.Point{X: signature.H.X, Y: signature.H.Y}
+	hR := curve.ScalarMul(h, r)
+	curve.AssertIsOnCurve(hR)
 
-	c := &twistededwards.Point{}
-	c.Add(api, gX, hR, curve)
-	c.MustBeOnCurve(api, curve)
+	c := curve.Add(gX, hR)
+	curve.AssertIsOnCurve(c)
+
+	return c, miMC, curve, nil
+}
+
+// assertSignedText asserts that the owner of publicKey signed the
+// MiMC-Pedersen commitment with randomness r to the columns hash, the
+// commitment to the data and the private text hash, see
+// signature.MiMCPedersenVersion.
+func assertSignedText(api frontend.API, version signature.CircuitVersion, colsHash, commit, secTextHash, r frontend.Variable,
+	publicKey *twistededwards.Point, sig *Signature) error {
+	c, miMC, curve, err := commitText(api, version, colsHash, commit, secTextHash, r)
+	if err != nil {
+		return err
+	}
 
 	// compute H(RData, A, M), all parameters in data are in Montgomery form
 	data := []frontend.Variable{
-		circuit.Signature.R.X,
-		circuit.Signature.R.Y,
-		circuit.PublicKey.X,
-		circuit.PublicKey.Y,
+		sig.R.X,
+		sig.R.Y,
+		publicKey.X,
+		publicKey.Y,
 		c.X,
 	}
 	miMC.Reset()
 	miMC.Write(data...)
 	hramConstant := miMC.Sum()
 
-	base := twistededwards.Point{}
-	base.X = curve.Base.X
-	base.Y = curve.Base.Y
+	base := twistededwards.Point{X: curve.Params().Base[0], Y: curve.Params().Base[1]}
 
 	// lhs = [S]G
-	lhs := twistededwards.Point{}
-	lhs.ScalarMul(api, &base, circuit.Signature.S, curve)
-	lhs.MustBeOnCurve(api, curve)
-
-	rhs := twistededwards.Point{}
-	rhs.ScalarMul(api, &circuit.PublicKey, hramConstant, curve).Add(api, &rhs, &circuit.Signature.R, curve)
+	lhs := curve.ScalarMul(base, sig.S)
+	curve.AssertIsOnCurve(lhs)
 
-	rhs.MustBeOnCurve(api, curve)
+	rhs := curve.Add(curve.ScalarMul(*publicKey, hramConstant), sig.R)
+	curve.AssertIsOnCurve(rhs)
 
 	// lhs-rhs
-	rhs.Neg(api, &rhs).Add(api, &lhs, &rhs, curve)
+	rhs = curve.Add(lhs, curve.Neg(rhs))
 
 	// [cofactor](lhs-rhs), cofactor = 8
-	rhs.Double(api, &rhs, curve).
-		Double(api, &rhs, curve).Double(api, &rhs, curve)
+	rhs = curve.Double(curve.Double(curve.Double(rhs)))
 
 	api.AssertIsEqual(rhs.X, 0)
 	api.AssertIsEqual(rhs.Y, 1)
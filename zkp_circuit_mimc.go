@@ -0,0 +1,125 @@
+package ZKPComponent
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/krakenh2020/ZKPComponent/data_common"
+	"github.com/krakenh2020/ZKPComponent/signature"
+)
+
+// bit lengths of the range checks of CircuitDatasetMiMC: the values
+// are in (-2^127, 2^127), the shares below 2^129 and the quotients of
+// the share relations in [-8, 8)
+const (
+	mimcValueBits    = 128
+	mimcShareBits    = 129
+	mimcQuotientBits = 4
+)
+
+// CircuitDatasetMiMC proves that the dataset Data, committed with
+// signature.CommitSchemeMiMC and signed like in CircuitDataset, is
+// split into the Shamir shares Shares committed in ShareCommits, see
+// signature.CreateSharesShamirMiMC. Contrary to CircuitDataset, the
+// opening of the commitment is checked inside the circuit, hence the
+// circuit depends on the length of the dataset, see
+// NewCircuitDatasetMiMC.
+type CircuitDatasetMiMC struct {
+	// text
+	ColsHash    frontend.Variable `gnark:",public"`
+	SecTextHash frontend.Variable
+	// Pedersen
+	R frontend.Variable
+	// Signature
+	PublicKey twistededwards.Point `gnark:",public"`
+	Signature Signature            `gnark:",public"`
+
+	// data and its commitment randomness
+	Data  []frontend.Variable
+	RData frontend.Variable
+	// shares, their commitment randomness and commitments
+	Shares       [3][]frontend.Variable
+	RShares      [3]frontend.Variable
+	ShareCommits [3]frontend.Variable `gnark:",public"`
+	// quotients by MPCPrime of the relations between the shares
+	Quotients [2][]frontend.Variable
+
+	// Version selects the hash of the circuit, it is not part of the
+	// witness
+	Version signature.CircuitVersion `gnark:"-"`
+}
+
+// NewCircuitDatasetMiMC returns the circuit of the version for datasets
+// of n values.
+func NewCircuitDatasetMiMC(version signature.CircuitVersion, n int) *CircuitDatasetMiMC {
+	circuit := CircuitDatasetMiMC{Version: version}
+	circuit.Data = make([]frontend.Variable, n)
+	for i := 0; i < 3; i++ {
+		circuit.Shares[i] = make([]frontend.Variable, n)
+	}
+	for i := 0; i < 2; i++ {
+		circuit.Quotients[i] = make([]frontend.Variable, n)
+	}
+
+	return &circuit
+}
+
+// CompileCircuitDatasetMiMC compiles CircuitDatasetMiMC of the version
+// for datasets of n values for Groth16 over BN254.
+func CompileCircuitDatasetMiMC(version signature.CircuitVersion, n int) (constraint.ConstraintSystem, error) {
+	return frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, NewCircuitDatasetMiMC(version, n))
+}
+
+func (circuit *CircuitDatasetMiMC) Define(api frontend.API) error {
+	miMC, err := newCircuitHash(api, circuit.Version)
+	if err != nil {
+		return err
+	}
+
+	commit := mimcCommitCircuit(miMC, circuit.RData, circuit.Data)
+	err = assertSignedText(api, circuit.Version, circuit.ColsHash, commit, circuit.SecTextHash, circuit.R,
+		&circuit.PublicKey, &circuit.Signature)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < 3; i++ {
+		api.AssertIsEqual(mimcCommitCircuit(miMC, circuit.RShares[i], circuit.Shares[i]), circuit.ShareCommits[i])
+	}
+
+	// the shares are f(i) = v + a (i+1) mod MPCPrime, that is
+	// s_1 = 2 s_0 - v and s_2 = 3 s_0 - 2 v mod MPCPrime
+	offsetValue := new(big.Int).Lsh(big.NewInt(1), mimcValueBits-1)
+	offsetQuotient := 1 << (mimcQuotientBits - 1)
+	for j, v := range circuit.Data {
+		api.ToBinary(api.Add(v, offsetValue), mimcValueBits)
+		for i := 0; i < 3; i++ {
+			api.ToBinary(circuit.Shares[i][j], mimcShareBits)
+		}
+		for i := 0; i < 2; i++ {
+			api.ToBinary(api.Add(circuit.Quotients[i][j], offsetQuotient), mimcQuotientBits)
+		}
+
+		s0, s1, s2 := circuit.Shares[0][j], circuit.Shares[1][j], circuit.Shares[2][j]
+		api.AssertIsEqual(api.Add(api.Sub(s1, api.Mul(s0, 2)), v),
+			api.Mul(circuit.Quotients[0][j], data_common.MPCPrime))
+		api.AssertIsEqual(api.Add(api.Sub(s2, api.Mul(s0, 3)), api.Mul(v, 2)),
+			api.Mul(circuit.Quotients[1][j], data_common.MPCPrime))
+	}
+
+	return nil
+}
+
+// mimcCommitCircuit computes MiMC(r, vec...) like the commitments of
+// signature.CommitSchemeMiMC.
+func mimcCommitCircuit(miMC circuitHash, r frontend.Variable, vec []frontend.Variable) frontend.Variable {
+	miMC.Reset()
+	miMC.Write(r)
+	miMC.Write(vec...)
+
+	return miMC.Sum()
+}
@@ -0,0 +1,69 @@
+package ZKPComponent
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/krakenh2020/ZKPComponent/signature"
+)
+
+// circuitHash is the in-circuit hash of a signature.CircuitVersion.
+type circuitHash interface {
+	Write(data ...frontend.Variable)
+	Reset()
+	Sum() frontend.Variable
+}
+
+// newCircuitHash returns the in-circuit hash of the version, matching
+// its native hash signature.CircuitVersion.NewHash.
+func newCircuitHash(api frontend.API, version signature.CircuitVersion) (circuitHash, error) {
+	constants, err := version.MiMCConstants()
+	if err != nil {
+		return nil, err
+	}
+
+	return &seededMiMC{api: api, constants: constants, h: 0}, nil
+}
+
+// seededMiMC is the MiMC of std/hash/mimc up to gnark v0.6, with the
+// round constants of the version: gnark now uses 110 rounds.
+type seededMiMC struct {
+	api       frontend.API
+	constants []big.Int
+	h         frontend.Variable
+	data      []frontend.Variable
+}
+
+func (h *seededMiMC) Write(data ...frontend.Variable) {
+	h.data = append(h.data, data...)
+}
+
+func (h *seededMiMC) Reset() {
+	h.data = nil
+	h.h = 0
+}
+
+// Sum hashes the data written so far with Miyaguchi–Preneel and
+// flushes it.
+func (h *seededMiMC) Sum() frontend.Variable {
+	for _, m := range h.data {
+		h.h = h.api.Add(h.h, h.encrypt(m), m)
+	}
+	h.data = nil
+
+	return h.h
+}
+
+// encrypt is the MiMC permutation with exponent 5, keyed with the
+// current state.
+func (h *seededMiMC) encrypt(m frontend.Variable) frontend.Variable {
+	x := m
+	for i := range h.constants {
+		t := h.api.Add(x, h.h, h.constants[i])
+		x = h.api.Mul(t, t)
+		x = h.api.Mul(x, x)
+		x = h.api.Mul(x, t)
+	}
+
+	return h.api.Add(x, h.h)
+}
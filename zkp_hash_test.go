@@ -0,0 +1,107 @@
+package ZKPComponent
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+	"github.com/krakenh2020/ZKPComponent/signature"
+	"github.com/stretchr/testify/assert"
+)
+
+type circuitHashTest struct {
+	Data    [3]frontend.Variable
+	Digests [2]frontend.Variable     `gnark:",public"`
+	Version signature.CircuitVersion `gnark:"-"`
+}
+
+func (c *circuitHashTest) Define(api frontend.API) error {
+	h, err := newCircuitHash(api, c.Version)
+	if err != nil {
+		return err
+	}
+	h.Write(c.Data[:]...)
+	api.AssertIsEqual(h.Sum(), c.Digests[0])
+	// the state is kept after Sum
+	h.Write(c.Data[0])
+	api.AssertIsEqual(h.Sum(), c.Digests[1])
+
+	return nil
+}
+
+func TestCircuitHash(t *testing.T) {
+	for _, version := range []signature.CircuitVersion{signature.CircuitV1, signature.CircuitV2} {
+		hs, err := version.NewHash()
+		assert.NoError(t, err)
+
+		var witness circuitHashTest
+		buf := make([]byte, fr.Bytes)
+		data := make([]*big.Int, 3)
+		for i := range data {
+			data[i], err = rand.Int(rand.Reader, fr.Modulus())
+			assert.NoError(t, err)
+			witness.Data[i] = data[i]
+			hs.Write(data[i].FillBytes(buf))
+		}
+		witness.Digests[0] = hs.Sum(nil)
+		hs.Write(data[0].FillBytes(buf))
+		witness.Digests[1] = hs.Sum(nil)
+
+		circuit := circuitHashTest{Version: version}
+		assert.NoError(t, test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField()))
+
+		circuit.Version = 1 - version
+		assert.Error(t, test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField()))
+	}
+
+	_, err := newCircuitHash(nil, signature.CircuitVersion(7))
+	assert.Error(t, err)
+}
+
+func TestCircuitDatasetVersion(t *testing.T) {
+	signer, err := eddsa.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	for _, version := range []signature.CircuitVersion{signature.CircuitV1, signature.CircuitV2} {
+		opts := signature.DefaultSignOptions
+		opts.Circuit = version
+		sign, err := signature.SignCsvWithOptions("datasets/framingham_tiny.csv", signer, opts)
+		assert.NoError(t, err)
+		signed := filepath.Join(t.TempDir(), "signed.csv")
+		assert.NoError(t, signature.WriteSignCsv("datasets/framingham_tiny.csv", signed, sign))
+		check, err := signature.VerifyCsv(signed, signer.Public())
+		assert.NoError(t, err)
+		assert.True(t, check)
+
+		_, cols, _, privateText, signBytes, err := signature.CsvToVecAuth(signed)
+		assert.NoError(t, err)
+		var s signature.SignatureZKP
+		assert.NoError(t, json.Unmarshal(signBytes, &s))
+		assert.Equal(t, version, s.CircuitVersion)
+
+		witness := CircuitDataset{Version: version}
+		assert.NoError(t, ColumnsCommitTextAssign(cols, s.CommitData, privateText, &witness, true))
+		witness.R = s.Commit.R
+		pubKey := signature.ParsePoint(s.PubKey)
+		witness.PublicKey.X = pubKey.X
+		witness.PublicKey.Y = pubKey.Y
+		sigR, sigS := signature.ParseSignature(s.Sig)
+		witness.Signature.R.X = sigR.X
+		witness.Signature.R.Y = sigR.Y
+		witness.Signature.S = sigS
+
+		for _, e := range []frontend.Variable{witness.ColsHash, witness.Commit, witness.SecTextHash} {
+			assert.True(t, e.(*big.Int).Cmp(fr.Modulus()) < 0)
+		}
+
+		assert.NoError(t, test.IsSolved(&CircuitDataset{Version: version}, &witness, ecc.BN254.ScalarField()))
+		assert.Error(t, test.IsSolved(&CircuitDataset{Version: 1 - version}, &witness, ecc.BN254.ScalarField()))
+	}
+}
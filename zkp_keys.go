@@ -0,0 +1,105 @@
+package ZKPComponent
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/krakenh2020/ZKPComponent/signature"
+)
+
+// readKeyBytes reads a key stored as a JSON array of bytes, as in
+// proofKey.txt and verifyKey.txt.
+func readKeyBytes(file string) ([]byte, error) {
+	keyBytes, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var c []byte
+	err = json.Unmarshal(keyBytes, &c)
+
+	return c, err
+}
+
+// WriteKey writes the key as a JSON array of bytes, as in proofKey.txt
+// and verifyKey.txt.
+func WriteKey(file string, key io.WriterTo) error {
+	var buf bytes.Buffer
+	_, err := key.WriteTo(&buf)
+	if err != nil {
+		return err
+	}
+	keyBytes, err := json.Marshal(buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(file, keyBytes, 0644)
+}
+
+func LoadProvingKey(file string) (groth16.ProvingKey, error) {
+	c, err := readKeyBytes(file)
+	if err != nil {
+		return nil, err
+	}
+
+	pk := groth16.NewProvingKey(ecc.BN254)
+	_, err = pk.ReadFrom(bytes.NewReader(c))
+	if err != nil {
+		return nil, err
+	}
+
+	return pk, nil
+}
+
+func LoadVerifyingKey(file string) (groth16.VerifyingKey, error) {
+	c, err := readKeyBytes(file)
+	if err != nil {
+		return nil, err
+	}
+
+	vk := groth16.NewVerifyingKey(ecc.BN254)
+	_, err = vk.ReadFrom(bytes.NewReader(c))
+	if err != nil {
+		return nil, err
+	}
+
+	return vk, nil
+}
+
+// CompileCircuitDataset compiles CircuitDataset of the version of the
+// distributed keys for Groth16 over BN254.
+func CompileCircuitDataset() (constraint.ConstraintSystem, error) {
+	return CompileCircuitDatasetVersion(signature.CurrentCircuitVersion)
+}
+
+// CompileCircuitDatasetVersion compiles CircuitDataset of the version
+// for Groth16 over BN254.
+func CompileCircuitDatasetVersion(version signature.CircuitVersion) (constraint.ConstraintSystem, error) {
+	circuit := CircuitDataset{Version: version}
+
+	return frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &circuit)
+}
+
+// CompileCircuitDatasetOpening compiles CircuitDatasetOpening of the
+// version for Groth16 over BN254.
+func CompileCircuitDatasetOpening(version signature.CircuitVersion) (constraint.ConstraintSystem, error) {
+	circuit := CircuitDatasetOpening{Version: version}
+
+	return frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &circuit)
+}
+
+// CompileCircuitDatasetECDSA compiles CircuitDatasetECDSA of the
+// version for Groth16 over BN254.
+func CompileCircuitDatasetECDSA(version signature.CircuitVersion) (constraint.ConstraintSystem, error) {
+	circuit := CircuitDatasetECDSA{Version: version}
+
+	return frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &circuit)
+}
@@ -0,0 +1,53 @@
+package ZKPComponent
+
+import (
+	"fmt"
+	"math/big"
+
+	sig "github.com/consensys/gnark-crypto/signature"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/krakenh2020/ZKPComponent/signature"
+	"github.com/krakenh2020/ZKPComponent/signature/ec"
+)
+
+// VerifyLinearResult lets a buyer verify that result, reconstructed from
+// the result shares of the MPC nodes, is the linear function w of the
+// dataset authenticated by the AuthProof (proof, commits, sign). Each
+// node proves with a signature.LinearProof that its result derives from
+// its committed share, and the proven results must join to result.
+func VerifyLinearResult(result *big.Int, w []*big.Int, linProofs []*signature.LinearProof, proof groth16.Proof,
+	verKey groth16.VerifyingKey, commits []*ec.Ec, cols []string, sign *signature.SignatureZKP, pubKey sig.PublicKey) (bool, error) {
+	if len(linProofs) != 3 || len(commits) != 3 {
+		return false, fmt.Errorf("expected the proofs and commits of 3 nodes")
+	}
+
+	check, err := VerifyDatasetZKp(proof, verKey, commits, cols, sign, pubKey)
+	if err != nil {
+		return false, err
+	}
+	if !check {
+		return false, fmt.Errorf("dataset proof not valid")
+	}
+
+	ys := make([]*big.Int, 3)
+	for i := 0; i < 3; i++ {
+		check, err = signature.VerifyLinearShare(linProofs[i], commits[i], w, sign.GenVersion)
+		if err != nil {
+			return false, fmt.Errorf("node %d: %w", i, err)
+		}
+		if !check {
+			return false, fmt.Errorf("node %d: linear proof not valid", i)
+		}
+		ys[i] = linProofs[i].Y
+	}
+
+	y, err := signature.JoinLinearResults(ys)
+	if err != nil {
+		return false, err
+	}
+	if y.Cmp(result) != 0 {
+		return false, fmt.Errorf("result does not match the committed dataset")
+	}
+
+	return true, nil
+}
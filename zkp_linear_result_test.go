@@ -0,0 +1,58 @@
+package ZKPComponent
+
+import (
+	"crypto/rand"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	"github.com/krakenh2020/ZKPComponent/data_common"
+	"github.com/krakenh2020/ZKPComponent/mpc"
+	"github.com/krakenh2020/ZKPComponent/signature"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyLinearResult(t *testing.T) {
+	signer, err := eddsa.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	signed := filepath.Join(t.TempDir(), "framingham_tiny_signed.csv")
+	sign, err := signature.SignCsv("datasets/framingham_tiny.csv", signer)
+	assert.NoError(t, err)
+	assert.NoError(t, signature.WriteSignCsv("datasets/framingham_tiny.csv", signed, sign))
+
+	r1cs, err := CompileCircuitDataset()
+	assert.NoError(t, err)
+	pk, err := LoadProvingKey("proofKey.txt")
+	assert.NoError(t, err)
+	vk, err := LoadVerifyingKey("verifyKey.txt")
+	assert.NoError(t, err)
+
+	splits, proof, commits, cols, pubSign, err := DatasetSplitAndZkpCsv(signed, pk, r1cs)
+	assert.NoError(t, err)
+	n := (len(splits[0]) - 1) / 2
+
+	// the nodes compute the sum of a column on their shares and prove it
+	col := 10
+	w := signature.ColumnSumWeights(n, len(cols), col)
+	linProofs := make([]*signature.LinearProof, 3)
+	res, err := mpc.RunNodes(mpc.NewDealer(), func(node *mpc.Node) ([]*big.Int, error) {
+		var err error
+		linProofs[node.Id], err = signature.ProveLinearShare(splits[node.Id], commits[node.Id], w, pubSign.GenVersion)
+		if err != nil {
+			return nil, err
+		}
+		return mpc.ColumnSum(splits[node.Id][:n], len(cols))
+	})
+	assert.NoError(t, err)
+	sums, err := data_common.JoinSharesShamir(res)
+	assert.NoError(t, err)
+
+	check, err := VerifyLinearResult(sums[col], w, linProofs, proof, vk, commits, cols, pubSign, signer.Public())
+	assert.NoError(t, err)
+	assert.True(t, check)
+
+	_, err = VerifyLinearResult(sums[col+1], w, linProofs, proof, vk, commits, cols, pubSign, signer.Public())
+	assert.Error(t, err)
+}
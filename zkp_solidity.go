@@ -0,0 +1,115 @@
+package ZKPComponent
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/krakenh2020/ZKPComponent/proof_aggregation"
+	"golang.org/x/crypto/sha3"
+)
+
+// datasetPublicInputs is the number of public inputs of CircuitDataset:
+// the columns, the commitment, the public key and the signature
+const datasetPublicInputs = 7
+
+// solidityWord is the size of a uint256 in the Solidity ABI
+const solidityWord = 32
+
+// ExportSolidityVerifier writes the Solidity Verifier contract of the
+// verifying key of CircuitDataset. Its verifyProof function takes the
+// arguments of a SolidityProof and reverts if the proof is invalid.
+func ExportSolidityVerifier(verKey groth16.VerifyingKey, w io.Writer) error {
+	if verKey.NbPublicWitness() != datasetPublicInputs {
+		return fmt.Errorf("verifying key not made for circuit %s", CircuitDatasetName)
+	}
+
+	return verKey.ExportSolidity(w)
+}
+
+// SolidityProof is a proof of CircuitDataset and its public inputs as
+// the arguments of verifyProof of the contract of
+// ExportSolidityVerifier: the points of the proof, passed as 8 words,
+// with the coordinates of G2 in the order of the EVM precompiles,
+// imaginary part first.
+type SolidityProof struct {
+	A     [2]*big.Int
+	B     [2][2]*big.Int
+	C     [2]*big.Int
+	Input []*big.Int
+}
+
+// NewSolidityProof returns the arguments of verifyProof for the AuthProof
+// of a dataset of the columns. Only the proofs of CircuitDataset are
+// verified on chain: the signature is not checked by the opening circuit
+// and the verifier contract does not support the commitments of the
+// ECDSA circuit.
+func NewSolidityProof(aProof *AuthProof, cols []string) (*SolidityProof, error) {
+	if aProof.Sign == nil || len(aProof.Commits) != 3 {
+		return nil, fmt.Errorf("incomplete authentication proof")
+	}
+	if name := DatasetCircuitName(aProof.Sign); name != CircuitDatasetName {
+		return nil, fmt.Errorf("proofs of circuit %s not verified on chain", name)
+	}
+	zkProof, commits, sign, pubKey, err := ExpandAuthProof(aProof)
+	if err != nil {
+		return nil, err
+	}
+
+	proof, err := proof_aggregation.DecodeProof(zkProof)
+	if err != nil {
+		return nil, err
+	}
+	a, b, c := &proof.A, &proof.B, &proof.C
+	res := &SolidityProof{
+		A: [2]*big.Int{a.X.BigInt(new(big.Int)), a.Y.BigInt(new(big.Int))},
+		B: [2][2]*big.Int{
+			{b.X.A1.BigInt(new(big.Int)), b.X.A0.BigInt(new(big.Int))},
+			{b.Y.A1.BigInt(new(big.Int)), b.Y.A0.BigInt(new(big.Int))},
+		},
+		C: [2]*big.Int{c.X.BigInt(new(big.Int)), c.Y.BigInt(new(big.Int))},
+	}
+
+	publicWitness, err := datasetPublicWitness(commits, cols, sign, pubKey)
+	if err != nil {
+		return nil, err
+	}
+	inputs, err := publicInputs(publicWitness)
+	if err != nil {
+		return nil, err
+	}
+	if len(inputs) != datasetPublicInputs {
+		return nil, fmt.Errorf("public witness of wrong length")
+	}
+	for i := range inputs {
+		res.Input = append(res.Input, inputs[i].BigInt(new(big.Int)))
+	}
+
+	return res, nil
+}
+
+// soliditySignature is the signature of verifyProof, whose Keccak-256
+// starts the calldata
+func soliditySignature(nbInputs int) string {
+	return fmt.Sprintf("verifyProof(uint256[8],uint256[%d])", nbInputs)
+}
+
+// Calldata returns the ABI encoded call of verifyProof: the function
+// selector followed by the arguments, all static, as uint256 words.
+func (p *SolidityProof) Calldata() ([]byte, error) {
+	h := sha3.NewLegacyKeccak256()
+	h.Write([]byte(soliditySignature(len(p.Input))))
+	res := h.Sum(nil)[:4]
+
+	words := []*big.Int{p.A[0], p.A[1], p.B[0][0], p.B[0][1], p.B[1][0], p.B[1][1], p.C[0], p.C[1]}
+	words = append(words, p.Input...)
+	for _, w := range words {
+		if w == nil || w.Sign() < 0 || w.BitLen() > 8*solidityWord {
+			return nil, fmt.Errorf("argument not a uint256")
+		}
+		res = append(res, w.FillBytes(make([]byte, solidityWord))...)
+	}
+
+	return res, nil
+}
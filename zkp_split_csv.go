@@ -3,19 +3,22 @@ package ZKPComponent
 import (
 	"bufio"
 	"bytes"
-	"crypto/sha256"
+	"context"
 	"encoding/json"
 	"fmt"
+	"github.com/consensys/gnark/constraint"
 	"github.com/krakenh2020/ZKPComponent/signature"
+	"io"
 	"math/big"
 	"os"
 	"strings"
 
 	"github.com/consensys/gnark-crypto/ecc"
-	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
 	sig "github.com/consensys/gnark-crypto/signature"
 	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/witness"
 	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/emulated"
 	"github.com/krakenh2020/ZKPComponent/data_common"
 	"github.com/krakenh2020/ZKPComponent/encryption"
 	"github.com/krakenh2020/ZKPComponent/signature/ec"
@@ -27,84 +30,128 @@ type AuthProof struct {
 	Sign    *signature.SignatureZKP
 }
 
+// ColumnsCommitTextAssign assigns the field elements of the columns,
+// the commitment and, if private, the private text in the encoding of
+// witness.Version, see signature.ColumnsField.
 func ColumnsCommitTextAssign(columns []string, commit *ec.Ec, privateText string, witness *CircuitDataset, private bool) error {
-	var valBytes []byte
-	hashSha := sha256.New()
-	_, err := hashSha.Write([]byte(strings.Join(columns, ",")))
+	colsHash, err := signature.ColumnsField(witness.Version, columns)
 	if err != nil {
 		return err
 	}
-	columnsHash := hashSha.Sum(nil)
-	witness.ColsHash = columnsHash
+	witness.ColsHash = colsHash
 
-	commitBytes := commit.XBytes()
-	valBytes = make([]byte, 32)
-	copy(valBytes[32-len(commitBytes):], commitBytes)
-	witness.Commit = valBytes
+	commitField, err := signature.CommitDataField(witness.Version, commit)
+	if err != nil {
+		return err
+	}
+	witness.Commit = commitField
 
 	if private {
-		hashSha.Reset()
-		_, err = hashSha.Write([]byte(privateText))
+		secTextHash, err := signature.PrivateTextField(witness.Version, privateText)
 		if err != nil {
 			return err
 		}
-		privateTextHash := hashSha.Sum(nil)
-		witness.SecTextHash = privateTextHash
+		witness.SecTextHash = secTextHash
 	}
 
 	return nil
 }
 
 func DatasetSplitAndZkpCsvText(vec []*big.Int, cols []string, privateText string, signBytes []byte, proofKey groth16.ProvingKey,
-	r1cs frontend.CompiledConstraintSystem) ([][]*big.Int, groth16.Proof, []*ec.Ec, *signature.SignatureZKP, error) {
+	r1cs constraint.ConstraintSystem) ([][]*big.Int, groth16.Proof, []*ec.Ec, *signature.SignatureZKP, error) {
 
 	var sign signature.SignatureZKP
 	err := json.Unmarshal(signBytes, &sign)
 	if err != nil {
 		return nil, nil, nil, nil, err
 	}
+	if sign.Scheme != signature.CommitSchemeP256 || sign.CommitData == nil {
+		return nil, nil, nil, nil, fmt.Errorf("dataset not committed with Pedersen commitments, see DatasetSplitAndZkpMiMC")
+	}
 
 	splits, err := signature.CreateSharesShamirSpecial(vec, sign.RData)
 	if err != nil {
 		return nil, nil, nil, nil, err
 	}
 
+	commit0, err := signature.CommitShareSpecialContext(context.Background(), sign.GenVersion, splits[0])
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	commits := splitCommits(commit0, sign.CommitData)
+	proof, err := proveDataset(cols, privateText, &sign, proofKey, r1cs)
+
+	// make sig public
+	sign.Commit.R = nil
+
+	return splits, proof, commits, &sign, err
+}
+
+// splitCommits returns the commitments of the 3 shares from the
+// commitment of the first share and the commitment to the data.
+func splitCommits(commit0, commitData *ec.Ec) []*ec.Ec {
 	commits := make([]*ec.Ec, 3)
-	commits[0] = signature.CommitShareSpecial(splits[0])
+	commits[0] = commit0
 	commits[1] = new(ec.Ec).ScalarMult(commits[0], big.NewInt(2))
-	commits[1] = new(ec.Ec).Add(commits[1], new(ec.Ec).Neg(sign.CommitData))
+	commits[1] = new(ec.Ec).Add(commits[1], new(ec.Ec).Neg(commitData))
 	commits[2] = new(ec.Ec).ScalarMult(commits[0], big.NewInt(3))
-	commits[2] = new(ec.Ec).Add(commits[2], new(ec.Ec).Neg(new(ec.Ec).ScalarMult(sign.CommitData, big.NewInt(2))))
+	commits[2] = new(ec.Ec).Add(commits[2], new(ec.Ec).Neg(new(ec.Ec).ScalarMult(commitData, big.NewInt(2))))
 
-	var circuit CircuitDataset
+	return commits
+}
 
-	// assign cols
-	err = ColumnsCommitTextAssign(cols, sign.CommitData, privateText, &circuit, true)
-	if err != nil {
-		return nil, nil, nil, nil, err
-	}
+// proveDataset proves that the commitment to the data of sign is signed
+// together with the columns and the private text.
+func proveDataset(cols []string, privateText string, sign *signature.SignatureZKP, proofKey groth16.ProvingKey,
+	r1cs constraint.ConstraintSystem) (groth16.Proof, error) {
+	var assignment frontend.Circuit
+	var err error
+	switch sign.SigScheme {
+	case signature.SignatureSchemeEdDSABN254:
+		err = sign.CheckSizes()
+		if err != nil {
+			return nil, err
+		}
+		circuit := CircuitDataset{Version: sign.CircuitVersion}
 
-	circuit.R = sign.Commit.R
+		// assign cols
+		err = ColumnsCommitTextAssign(cols, sign.CommitData, privateText, &circuit, true)
+		if err != nil {
+			return nil, err
+		}
 
-	pubkey2 := signature.ParsePoint(sign.PubKey)
-	circuit.PublicKey.X = pubkey2.X
-	circuit.PublicKey.Y = pubkey2.Y
+		circuit.R = sign.Commit.R
 
-	sig2, sigS := signature.ParseSignature(sign.Sig)
-	circuit.Signature.R.X = sig2.X
-	circuit.Signature.R.Y = sig2.Y
-	circuit.Signature.S = sigS
+		pubkey2 := signature.ParsePoint(sign.PubKey)
+		circuit.PublicKey.X = pubkey2.X
+		circuit.PublicKey.Y = pubkey2.Y
 
-	witness, err := frontend.NewWitness(&circuit, ecc.BN254)
-	proof, err := groth16.Prove(r1cs, proofKey, witness)
+		sig2, sigS := signature.ParseSignature(sign.Sig)
+		circuit.Signature.R.X = sig2.X
+		circuit.Signature.R.Y = sig2.Y
+		circuit.Signature.S = sigS
+		assignment = &circuit
+	case signature.SignatureSchemeECDSAP256:
+		assignment, err = assignCircuitDatasetECDSA(cols, sign.CommitData, privateText, sign, true)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		assignment, err = assignCircuitDatasetOpening(cols, sign.CommitData, privateText, sign, true)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	// make sig public
-	sign.Commit.R = nil
+	witness, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+	if err != nil {
+		return nil, err
+	}
 
-	return splits, proof, commits, &sign, err
+	return groth16.Prove(r1cs, proofKey, witness)
 }
 
-func CsvTextSplitAndZkpCsvText(csvText string, proofKey groth16.ProvingKey, r1cs frontend.CompiledConstraintSystem) ([][]*big.Int,
+func CsvTextSplitAndZkpCsvText(csvText string, proofKey groth16.ProvingKey, r1cs constraint.ConstraintSystem) ([][]*big.Int,
 	groth16.Proof, []*ec.Ec, []string, *signature.SignatureZKP, error) {
 	vec, cols, _, privateText, signBytes, err := signature.CsvTextToVecAuth(csvText)
 	if err != nil {
@@ -115,7 +162,7 @@ func CsvTextSplitAndZkpCsvText(csvText string, proofKey groth16.ProvingKey, r1cs
 	return splits, proof, commits, cols, sign, err
 }
 
-func DatasetSplitAndZkpCsv(file string, proofKey groth16.ProvingKey, r1cs frontend.CompiledConstraintSystem) ([][]*big.Int,
+func DatasetSplitAndZkpCsv(file string, proofKey groth16.ProvingKey, r1cs constraint.ConstraintSystem) ([][]*big.Int,
 	groth16.Proof, []*ec.Ec, []string, *signature.SignatureZKP, error) {
 	csvBytes, err := os.ReadFile(file)
 	if err != nil {
@@ -127,9 +174,9 @@ func DatasetSplitAndZkpCsv(file string, proofKey groth16.ProvingKey, r1cs fronte
 	return splits, proof, commits, cols, sign, err
 }
 
-func DatasetSplitEncryptAndZkpCsvToFile(file, output string, proofKey groth16.ProvingKey, r1cs frontend.CompiledConstraintSystem,
+func DatasetSplitEncryptAndZkpCsvToFile(file, output string, proofKey groth16.ProvingKey, r1cs constraint.ConstraintSystem,
 	pubKeys [][]byte) ([][]*big.Int, groth16.Proof, []*ec.Ec, []string, *signature.SignatureZKP, error) {
-	shares, proof, commits, cols, sign, err := DatasetSplitAndZkpCsv(file, proofKey, r1cs)
+	csvBytes, err := os.ReadFile(file)
 	if err != nil {
 		return nil, nil, nil, nil, nil, err
 	}
@@ -139,61 +186,127 @@ func DatasetSplitEncryptAndZkpCsvToFile(file, output string, proofKey groth16.Pr
 		return nil, nil, nil, nil, nil, err
 	}
 
-	for i := int64(0); i < 3; i++ {
-		msg, err := encryption.EncryptVec(shares[i], pubKeys[i])
-		if err != nil {
-			return nil, nil, nil, nil, nil, err
-		}
-		write, err := json.Marshal(msg)
+	shares, proof, commits, cols, sign, err := CsvTextSplitEncryptAndZkpToWriter(string(csvBytes), w, proofKey, r1cs, pubKeys)
+	if err != nil {
+		w.Close()
+		return nil, nil, nil, nil, nil, err
+	}
+
+	err = w.Close()
 
+	return shares, proof, commits, cols, sign, err
+}
+
+// CsvTextSplitEncryptAndZkpToWriter splits the signed csv text, encrypts
+// the shares for the nodes and writes them to w followed by the columns
+// and the AuthProof, in the format read by ReadShare and ReadAuth.
+func CsvTextSplitEncryptAndZkpToWriter(csvText string, w io.Writer, proofKey groth16.ProvingKey, r1cs constraint.ConstraintSystem,
+	pubKeys [][]byte) ([][]*big.Int, groth16.Proof, []*ec.Ec, []string, *signature.SignatureZKP, error) {
+	if len(pubKeys) != 3 {
+		return nil, nil, nil, nil, nil, fmt.Errorf("expected 3 public keys")
+	}
+	shares, proof, commits, cols, sign, err := CsvTextSplitAndZkpCsvText(csvText, proofKey, r1cs)
+	if err != nil {
+		return nil, nil, nil, nil, nil, err
+	}
+	err = writeContainer(w, shares, proof, commits, cols, sign, pubKeys)
+	if err != nil {
+		return nil, nil, nil, nil, nil, err
+	}
+
+	return shares, proof, commits, cols, sign, nil
+}
+
+// writeContainer encrypts the shares for the nodes and writes them to w
+// followed by the columns and the AuthProof.
+func writeContainer(w io.Writer, shares [][]*big.Int, proof groth16.Proof, commits []*ec.Ec, cols []string,
+	sign *signature.SignatureZKP, pubKeys [][]byte) error {
+	encShares, err := EncryptShares(shares, pubKeys)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < 3; i++ {
+		write, err := json.Marshal(encShares[i])
 		if err != nil {
-			return nil, nil, nil, nil, nil, err
+			return err
 		}
 
 		_, err = w.Write(write)
 		if err != nil {
-			return nil, nil, nil, nil, nil, err
+			return err
 		}
 		_, err = w.Write([]byte("\n"))
 		if err != nil {
-			return nil, nil, nil, nil, nil, err
+			return err
 		}
 	}
 	_, err = w.Write([]byte(strings.Join(cols, ",") + "\n"))
 	if err != nil {
-		return nil, nil, nil, nil, nil, err
+		return err
 	}
 
-	sign.RData = nil
-
-	var buf bytes.Buffer
-	_, err = proof.WriteTo(&buf)
+	aProof, err := NewAuthProof(proof, commits, sign)
 	if err != nil {
-		return nil, nil, nil, nil, nil, err
+		return err
 	}
-
-	aProof := AuthProof{ZkProof: buf.Bytes(), Commits: commits, Sign: sign}
 	aProofBytes, err := json.Marshal(aProof)
 	if err != nil {
-		return nil, nil, nil, nil, nil, err
+		return err
 	}
 	_, err = w.Write(aProofBytes)
 	if err != nil {
-		return nil, nil, nil, nil, nil, err
+		return err
 	}
 	_, err = w.Write([]byte("\n"))
-	if err != nil {
-		return nil, nil, nil, nil, nil, err
+
+	return err
+}
+
+// EncryptShares encrypts the shares of the nodes, each with the NaCl
+// public key of its node.
+func EncryptShares(shares [][]*big.Int, pubKeys [][]byte) ([]*encryption.VecEnc, error) {
+	if len(shares) != 3 || len(pubKeys) != 3 {
+		return nil, fmt.Errorf("expected the shares and keys of 3 nodes")
 	}
 
-	err = w.Close()
+	res := make([]*encryption.VecEnc, 3)
+	var err error
+	for i := 0; i < 3; i++ {
+		res[i], err = encryption.EncryptVec(shares[i], pubKeys[i])
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	return shares, proof, commits, cols, sign, err
+	return res, nil
+}
+
+// NewAuthProof serializes the proof and collects the public
+// authentication data of the shares. The randomness of the commitment
+// to the data is removed from sign.
+func NewAuthProof(proof groth16.Proof, commits []*ec.Ec, sign *signature.SignatureZKP) (*AuthProof, error) {
+	sign.RData = nil
+
+	var buf bytes.Buffer
+	_, err := proof.WriteTo(&buf)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuthProof{ZkProof: buf.Bytes(), Commits: commits, Sign: sign}, nil
 }
 
 func ExpandAuthProof(aProof *AuthProof) (groth16.Proof, []*ec.Ec, *signature.SignatureZKP, sig.PublicKey, error) {
+	if aProof.Sign == nil {
+		return nil, nil, nil, nil, fmt.Errorf("incomplete authentication proof")
+	}
+	err := aProof.Sign.CheckSizes()
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
 	var buf bytes.Buffer
-	_, err := buf.Write(aProof.ZkProof)
+	_, err = buf.Write(aProof.ZkProof)
 	if err != nil {
 		return nil, nil, nil, nil, err
 	}
@@ -205,10 +318,12 @@ func ExpandAuthProof(aProof *AuthProof) (groth16.Proof, []*ec.Ec, *signature.Sig
 		return nil, nil, nil, nil, err
 	}
 
-	var pubKey eddsa.PublicKey
-	_, err = pubKey.SetBytes(aProof.Sign.PubKey)
+	pubKey, err := signature.ParsePublicKey(aProof.Sign.SigScheme, aProof.Sign.PubKey)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
 
-	return proof, aProof.Commits, aProof.Sign, &pubKey, nil
+	return proof, aProof.Commits, aProof.Sign, pubKey, nil
 }
 
 func ReadAuth(file string) (*AuthProof, error) {
@@ -216,50 +331,120 @@ func ReadAuth(file string) (*AuthProof, error) {
 	if err != nil {
 		return nil, err
 	}
+	defer f.Close()
 
-	reader := bufio.NewReader(f)
+	return ReadAuthFrom(f)
+}
+
+// ReadAuthFrom reads the AuthProof from a share container, as written
+// by CsvTextSplitEncryptAndZkpToWriter.
+func ReadAuthFrom(r io.Reader) (*AuthProof, error) {
+	aProof, _, err := ReadAuthColsFrom(r)
+
+	return aProof, err
+}
 
-	for i := 0; i < 4; i++ {
-		_, err = data_common.Readln(reader)
+// ReadAuthColsFrom reads the columns and the AuthProof from a share
+// container.
+func ReadAuthColsFrom(r io.Reader) (*AuthProof, []string, error) {
+	reader := bufio.NewReader(r)
+
+	for i := 0; i < 3; i++ {
+		_, err := data_common.Readln(reader)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
+	colsString, err := data_common.Readln(reader)
+	if err != nil {
+		return nil, nil, err
+	}
 	// zkp info
 	zkpString, err := data_common.Readln(reader)
 	if err != nil {
-		return nil, err
-	}
-	err = f.Close()
-	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	var aProof AuthProof
 	err = json.Unmarshal([]byte(zkpString), &aProof)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return &aProof, nil
+	return &aProof, strings.Split(colsString, ","), nil
 }
 
 func VerifyDatasetSplitAndZKpCsv(proof groth16.Proof, verKey groth16.VerifyingKey, splitI []*big.Int, id int, commits []*ec.Ec, cols []string,
 	sig *signature.SignatureZKP, pubKey sig.PublicKey) (bool, error) {
+	check, err := VerifyDatasetZKp(proof, verKey, commits, cols, sig, pubKey)
+	if err != nil {
+		return false, err
+	}
+
+	// verify the commit
+	partCommit, err := signature.CommitShareSpecialContext(context.Background(), sig.GenVersion, splitI)
+	if err != nil {
+		return false, err
+	}
+	if partCommit.Equal(commits[id]) == false {
+		return false, fmt.Errorf("commit of the split does not match encrypted values")
+	}
+
+	return check, nil
+}
+
+// VerifyDatasetZKp verifies that the commits of the shares join to a
+// commitment of a dataset signed by the owner of pubKey, without
+// checking any of the shares.
+func VerifyDatasetZKp(proof groth16.Proof, verKey groth16.VerifyingKey, commits []*ec.Ec, cols []string,
+	sig *signature.SignatureZKP, pubKey sig.PublicKey) (bool, error) {
+	publicWitness, err := datasetPublicWitness(commits, cols, sig, pubKey)
+	if err != nil {
+		return false, err
+	}
+	err = groth16.Verify(proof, verKey, publicWitness)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// datasetPublicWitness returns the public witness of CircuitDataset for
+// the commits of the shares, the columns and the signature, of
+// CircuitDatasetECDSA for ECDSA signatures or of CircuitDatasetOpening
+// for signatures not verified in the circuit.
+func datasetPublicWitness(commits []*ec.Ec, cols []string, sig *signature.SignatureZKP,
+	pubKey sig.PublicKey) (witness.Witness, error) {
+	switch sig.SigScheme {
+	case signature.SignatureSchemeEdDSABN254:
+	case signature.SignatureSchemeECDSAP256:
+		return ecdsaPublicWitness(commits, cols, sig, pubKey)
+	default:
+		return openingPublicWitness(commits, cols, sig, pubKey)
+	}
+
+	err := sig.CheckSizes()
+	if err != nil {
+		return nil, err
+	}
+	err = checkSignatureKey(sig, pubKey)
+	if err != nil {
+		return nil, err
+	}
 	// verify the signature
-	var circuit CircuitDataset
+	circuit := CircuitDataset{Version: sig.CircuitVersion}
 
 	commit, err := signature.JoinCommits(commits)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
 	err = ColumnsCommitTextAssign(cols, commit, "", &circuit, false)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
-	// todo assert that the pubkey is the same
 	pubkey2 := signature.ParsePoint(pubKey.Bytes())
 	circuit.PublicKey.X = pubkey2.X
 	circuit.PublicKey.Y = pubkey2.Y
@@ -269,17 +454,150 @@ func VerifyDatasetSplitAndZKpCsv(proof groth16.Proof, verKey groth16.VerifyingKe
 	circuit.Signature.R.Y = sig2.Y
 	circuit.Signature.S = sigS
 
-	publicWitness, err := frontend.NewWitness(&circuit, ecc.BN254, frontend.PublicOnly())
-	err = groth16.Verify(proof, verKey, publicWitness)
+	return frontend.NewWitness(&circuit, ecc.BN254.ScalarField(), frontend.PublicOnly())
+}
+
+// assignCircuitDatasetOpening returns the witness of
+// CircuitDatasetOpening for the columns, the commitment to the data
+// and, if private, the private text and the randomness of the signed
+// commitment.
+func assignCircuitDatasetOpening(cols []string, commit *ec.Ec, privateText string, sign *signature.SignatureZKP,
+	private bool) (*CircuitDatasetOpening, error) {
+	if sign.Commit.C == nil {
+		return nil, fmt.Errorf("missing signed commitment")
+	}
+	circuit := &CircuitDatasetOpening{Version: sign.CircuitVersion}
+	colsHash, err := signature.ColumnsField(sign.CircuitVersion, cols)
 	if err != nil {
-		return false, err
+		return nil, err
+	}
+	circuit.ColsHash = colsHash
+	commitField, err := signature.CommitDataField(sign.CircuitVersion, commit)
+	if err != nil {
+		return nil, err
 	}
+	circuit.Commit = commitField
+	circuit.C.X = sign.Commit.C.X
+	circuit.C.Y = sign.Commit.C.Y
 
-	// verify the commit
-	partCommit := signature.CommitShareSpecial(splitI)
-	if partCommit.Equal(commits[id]) == false {
-		return false, fmt.Errorf("commit of the split does not match encrypted values")
+	if private {
+		secTextHash, err := signature.PrivateTextField(sign.CircuitVersion, privateText)
+		if err != nil {
+			return nil, err
+		}
+		circuit.SecTextHash = secTextHash
+		circuit.R = sign.Commit.R
 	}
 
-	return true, nil
+	return circuit, nil
+}
+
+// checkSignatureKey returns an error if pubKey is not the key of the
+// scheme of the signature.
+func checkSignatureKey(sign *signature.SignatureZKP, pubKey sig.PublicKey) error {
+	scheme, err := signature.SchemeOf(pubKey)
+	if err != nil {
+		return err
+	}
+	if scheme != sign.SigScheme || !bytes.Equal(pubKey.Bytes(), sign.PubKey) {
+		return fmt.Errorf("public keys do not match")
+	}
+
+	return nil
+}
+
+// assignCircuitDatasetECDSA returns the witness of CircuitDatasetECDSA
+// for the columns, the commitment to the data and, if private, the
+// private text and the randomness of the signed commitment.
+func assignCircuitDatasetECDSA(cols []string, commit *ec.Ec, privateText string, sign *signature.SignatureZKP,
+	private bool) (*CircuitDatasetECDSA, error) {
+	pubKey, err := signature.ParsePublicKey(signature.SignatureSchemeECDSAP256, sign.PubKey)
+	if err != nil {
+		return nil, err
+	}
+	r, s, err := signature.ParseECDSASignature(sign.Sig)
+	if err != nil {
+		return nil, err
+	}
+
+	circuit := &CircuitDatasetECDSA{Version: sign.CircuitVersion}
+	colsHash, err := signature.ColumnsField(sign.CircuitVersion, cols)
+	if err != nil {
+		return nil, err
+	}
+	circuit.ColsHash = colsHash
+	commitField, err := signature.CommitDataField(sign.CircuitVersion, commit)
+	if err != nil {
+		return nil, err
+	}
+	circuit.Commit = commitField
+
+	key := pubKey.(*signature.ECDSAP256PublicKey)
+	circuit.PublicKey.X = emulated.ValueOf[emulated.P256Fp](key.X)
+	circuit.PublicKey.Y = emulated.ValueOf[emulated.P256Fp](key.Y)
+	circuit.Signature.R = emulated.ValueOf[emulated.P256Fr](r)
+	circuit.Signature.S = emulated.ValueOf[emulated.P256Fr](s)
+
+	if private {
+		secTextHash, err := signature.PrivateTextField(sign.CircuitVersion, privateText)
+		if err != nil {
+			return nil, err
+		}
+		circuit.SecTextHash = secTextHash
+		circuit.R = sign.Commit.R
+	}
+
+	return circuit, nil
+}
+
+// ecdsaPublicWitness returns the public witness of CircuitDatasetECDSA.
+func ecdsaPublicWitness(commits []*ec.Ec, cols []string, sign *signature.SignatureZKP,
+	pubKey sig.PublicKey) (witness.Witness, error) {
+	err := checkSignatureKey(sign, pubKey)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := signature.JoinCommits(commits)
+	if err != nil {
+		return nil, err
+	}
+	circuit, err := assignCircuitDatasetECDSA(cols, commit, "", sign, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return frontend.NewWitness(circuit, ecc.BN254.ScalarField(), frontend.PublicOnly())
+}
+
+// openingPublicWitness verifies the signature of a scheme not verified
+// in the circuit on the signed commitment and returns the public
+// witness of CircuitDatasetOpening.
+func openingPublicWitness(commits []*ec.Ec, cols []string, sign *signature.SignatureZKP,
+	pubKey sig.PublicKey) (witness.Witness, error) {
+	err := checkSignatureKey(sign, pubKey)
+	if err != nil {
+		return nil, err
+	}
+	if sign.Commit.C == nil {
+		return nil, fmt.Errorf("missing signed commitment")
+	}
+	hashed := sign.Commit.C.X.Bytes()
+	check, err := pubKey.Verify(sign.Sig, hashed[:], nil)
+	if err != nil {
+		return nil, err
+	}
+	if !check {
+		return nil, fmt.Errorf("invalid signature")
+	}
+
+	commit, err := signature.JoinCommits(commits)
+	if err != nil {
+		return nil, err
+	}
+	circuit, err := assignCircuitDatasetOpening(cols, commit, "", sign, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return frontend.NewWitness(circuit, ecc.BN254.ScalarField(), frontend.PublicOnly())
 }
@@ -5,6 +5,7 @@ import (
 	"crypto/rand"
 	"encoding/json"
 	"fmt"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
 	"github.com/krakenh2020/ZKPComponent/signature"
 	"os"
 	"testing"
@@ -13,7 +14,6 @@ import (
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
 	sig "github.com/consensys/gnark-crypto/signature"
-	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/backend/groth16"
 	"github.com/consensys/gnark/frontend"
 	"github.com/krakenh2020/ZKPComponent/key_management"
@@ -21,8 +21,7 @@ import (
 )
 
 func TestDatasetSplitAndZkpCsv(t *testing.T) {
-	sig.Register(sig.EDDSA_BN254, eddsa.GenerateKeyInterfaces)
-	signer, err := sig.EDDSA_BN254.New(rand.Reader)
+	signer, err := eddsa.GenerateKey(rand.Reader)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -38,8 +37,8 @@ func TestDatasetSplitAndZkpCsv(t *testing.T) {
 	}
 
 	now := time.Now()
-	var circuit CircuitDataset
-	r1cs, err := frontend.Compile(ecc.BN254, backend.GROTH16, &circuit)
+	circuit := CircuitDataset{Version: signature.CurrentCircuitVersion}
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &circuit)
 	elapsed := time.Since(now)
 	fmt.Println("ZKSign precompute1", elapsed.Milliseconds())
 	if err != nil {
@@ -47,7 +46,7 @@ func TestDatasetSplitAndZkpCsv(t *testing.T) {
 	}
 	now = time.Now()
 
-	pk, vk, err := groth16.Setup(r1cs)
+	pk, vk, err := groth16.Setup(ccs)
 	elapsed = time.Since(now)
 	fmt.Println("ZKSign precompute2", elapsed.Milliseconds())
 
@@ -79,7 +78,7 @@ func TestDatasetSplitAndZkpCsv(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	r1cs, err = frontend.Compile(ecc.BN254, backend.GROTH16, &circuit)
+	ccs, err = frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &circuit)
 
 	MPCpubKey, err := key_management.LoadPubKey("test", "key_management/keys")
 	if err != nil {
@@ -89,7 +88,7 @@ func TestDatasetSplitAndZkpCsv(t *testing.T) {
 	pubKeys := [][]byte{MPCpubKey, MPCpubKey, MPCpubKey}
 
 	now = time.Now()
-	split, proof, commits, cols, pubSig, err := DatasetSplitEncryptAndZkpCsvToFile("datasets/framingham_small_signed.csv", "datasets/framingham_small_signed_enc.txt", proofKey3, r1cs, pubKeys)
+	split, proof, commits, cols, pubSig, err := DatasetSplitEncryptAndZkpCsvToFile("datasets/framingham_small_signed.csv", "datasets/framingham_small_signed_enc.txt", proofKey3, ccs, pubKeys)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -121,7 +120,7 @@ func TestDatasetSplitAndZkpCsv(t *testing.T) {
 
 	//var buf bytes.Buffer
 	buf.Reset()
-	_, _ = r1cs.WriteTo(&buf)
+	_, _ = ccs.WriteTo(&buf)
 	fmt.Println("r1cs", buf.Len())
 
 	buf.Reset()
@@ -157,3 +156,78 @@ func TestDatasetSplitAndZkpCsv(t *testing.T) {
 	_, _ = proof.WriteTo(&buf)
 	fmt.Println("proof", buf.Len())
 }
+
+func TestDatasetSplitAndZkpCsvSchemes(t *testing.T) {
+	ecdsaKey, err := signature.GenerateECDSAP256(rand.Reader)
+	assert.NoError(t, err)
+	ed25519Key, err := signature.GenerateEd25519(rand.Reader)
+	assert.NoError(t, err)
+
+	nodeKey, err := key_management.LoadPubKey("test", "key_management/keys")
+	assert.NoError(t, err)
+	dir := t.TempDir()
+
+	for _, c := range []struct {
+		signer  sig.Signer
+		circuit string
+	}{{ecdsaKey, CircuitDatasetECDSAName}, {ed25519Key, CircuitDatasetOpeningName}} {
+		if c.circuit == CircuitDatasetECDSAName && testing.Short() {
+			// the setup and the proof of the ECDSA circuit take minutes
+			continue
+		}
+		r1cs, err := CircuitId{Name: c.circuit, Version: signature.CurrentCircuitVersion}.Compile()
+		assert.NoError(t, err)
+		pk, vk, err := groth16.Setup(r1cs)
+		assert.NoError(t, err)
+
+		sign, err := signature.SignCsv("datasets/framingham_tiny.csv", c.signer)
+		assert.NoError(t, err)
+		assert.Equal(t, c.circuit == CircuitDatasetECDSAName, sign.SigScheme.InCircuit())
+		assert.Equal(t, c.circuit, DatasetCircuitName(sign))
+		signed := dir + "/signed.csv"
+		assert.NoError(t, signature.WriteSignCsv("datasets/framingham_tiny.csv", signed, sign))
+
+		container := dir + "/container.txt"
+		_, _, _, _, _, err = DatasetSplitEncryptAndZkpCsvToFile(signed, container, pk, r1cs,
+			[][]byte{nodeKey, nodeKey, nodeKey})
+		assert.NoError(t, err)
+		f, err := os.Open(container)
+		assert.NoError(t, err)
+		aProof, cols, err := ReadAuthColsFrom(f)
+		f.Close()
+		assert.NoError(t, err)
+
+		proof, commits, sign2, pubKey, err := ExpandAuthProof(aProof)
+		assert.NoError(t, err)
+		assert.True(t, pubKey.Equal(c.signer.Public()))
+		check, err := VerifyDatasetZKp(proof, vk, commits, cols, sign2, pubKey)
+		assert.NoError(t, err)
+		assert.True(t, check)
+
+		// the signature is verified inside or outside of the circuit
+		sign2.Sig[len(sign2.Sig)-1] ^= 1
+		check, err = VerifyDatasetZKp(proof, vk, commits, cols, sign2, pubKey)
+		assert.Error(t, err)
+		assert.False(t, check)
+		sign2.Sig[len(sign2.Sig)-1] ^= 1
+
+		// another signer
+		other, err := signature.GenerateECDSAP256(rand.Reader)
+		assert.NoError(t, err)
+		sign2.PubKey = other.Public().Bytes()
+		sign2.SigScheme = signature.SignatureSchemeECDSAP256
+		check, err = VerifyDatasetZKp(proof, vk, commits, cols, sign2, other.Public())
+		assert.Error(t, err)
+		assert.False(t, check)
+		sign2.PubKey = pubKey.Bytes()
+		sign2.SigScheme = sign.SigScheme
+
+		if c.circuit == CircuitDatasetOpeningName {
+			// the commitment is bound by the proof
+			sign2.Commit.C.Y.Neg(&sign2.Commit.C.Y)
+			check, err = VerifyDatasetZKp(proof, vk, commits, cols, sign2, pubKey)
+			assert.Error(t, err)
+			assert.False(t, check)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package ZKPComponent
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	sig "github.com/consensys/gnark-crypto/signature"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/krakenh2020/ZKPComponent/data_common"
+	"github.com/krakenh2020/ZKPComponent/signature"
+)
+
+// DatasetSplitAndZkpMiMC splits a dataset signed with
+// signature.CommitSchemeMiMC into shares, see
+// signature.CreateSharesShamirMiMC, and proves with CircuitDatasetMiMC
+// that the committed shares are a splitting of the signed dataset.
+// The proving key must be made for datasets of len(vec) values. It
+// returns the shares, the proof, the commitments of the shares and
+// the signature without its private values.
+func DatasetSplitAndZkpMiMC(vec []*big.Int, cols []string, privateText string, sign *signature.SignatureZKP,
+	proofKey groth16.ProvingKey, r1cs constraint.ConstraintSystem) ([][]*big.Int, groth16.Proof, []*big.Int,
+	*signature.SignatureZKP, error) {
+	if sign.Scheme != signature.CommitSchemeMiMC {
+		return nil, nil, nil, nil, fmt.Errorf("dataset not committed with the MiMC scheme")
+	}
+	if sign.SigScheme != signature.SignatureSchemeEdDSABN254 {
+		return nil, nil, nil, nil, fmt.Errorf("signature scheme %s not verified by circuit %s", sign.SigScheme, CircuitDatasetMiMCName)
+	}
+	commit, _, err := signature.CommitDatasetMiMC(sign.CircuitVersion, vec, sign.RData)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if sign.CommitField == nil || commit.Cmp(sign.CommitField) != 0 {
+		return nil, nil, nil, nil, fmt.Errorf("commit value and data do not match")
+	}
+
+	splits, commits, err := signature.CreateSharesShamirMiMC(sign.CircuitVersion, vec)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	circuit, err := assignCircuitDatasetMiMC(vec, cols, privateText, sign, splits, commits)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	witness, err := frontend.NewWitness(circuit, ecc.BN254.ScalarField())
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	proof, err := groth16.Prove(r1cs, proofKey, witness)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	// make sig public
+	pubSign := *sign
+	pubSign.Commit.R = nil
+	pubSign.RData = nil
+
+	return splits, proof, commits, &pubSign, nil
+}
+
+// assignCircuitDatasetMiMC returns the full witness of
+// CircuitDatasetMiMC for the dataset and its shares.
+func assignCircuitDatasetMiMC(vec []*big.Int, cols []string, privateText string, sign *signature.SignatureZKP,
+	splits [][]*big.Int, commits []*big.Int) (*CircuitDatasetMiMC, error) {
+	if len(splits) != 3 || len(commits) != 3 {
+		return nil, fmt.Errorf("expected the shares of 3 nodes")
+	}
+	circuit := NewCircuitDatasetMiMC(sign.CircuitVersion, len(vec))
+	colsHash, err := signature.ColumnsField(sign.CircuitVersion, cols)
+	if err != nil {
+		return nil, err
+	}
+	circuit.ColsHash = colsHash
+	secTextHash, err := signature.PrivateTextField(sign.CircuitVersion, privateText)
+	if err != nil {
+		return nil, err
+	}
+	circuit.SecTextHash = secTextHash
+	circuit.R = sign.Commit.R
+	err = assignPublicSignature(circuit, sign)
+	if err != nil {
+		return nil, err
+	}
+
+	circuit.RData = sign.RData
+	for j, v := range vec {
+		circuit.Data[j] = signature.FieldElement(v)
+
+		s0, s1, s2 := splits[0][j], splits[1][j], splits[2][j]
+		// (s_1 - 2 s_0 + v) / MPCPrime and (s_2 - 3 s_0 + 2 v) / MPCPrime
+		q := new(big.Int).Sub(s1, new(big.Int).Lsh(s0, 1))
+		q.Add(q, v)
+		circuit.Quotients[0][j] = signature.FieldElement(q.Div(q, data_common.MPCPrime))
+		q = new(big.Int).Sub(s2, new(big.Int).Mul(s0, big.NewInt(3)))
+		q.Add(q, new(big.Int).Lsh(v, 1))
+		circuit.Quotients[1][j] = signature.FieldElement(q.Div(q, data_common.MPCPrime))
+	}
+	for i := 0; i < 3; i++ {
+		for j := range vec {
+			circuit.Shares[i][j] = splits[i][j]
+		}
+		circuit.RShares[i] = splits[i][len(vec)]
+		circuit.ShareCommits[i] = commits[i]
+	}
+
+	return circuit, nil
+}
+
+// assignPublicSignature assigns the public key and the signature of
+// sign to the circuit.
+func assignPublicSignature(circuit *CircuitDatasetMiMC, sign *signature.SignatureZKP) error {
+	err := sign.CheckSizes()
+	if err != nil {
+		return err
+	}
+	pubkey2 := signature.ParsePoint(sign.PubKey)
+	circuit.PublicKey.X = pubkey2.X
+	circuit.PublicKey.Y = pubkey2.Y
+
+	sig2, sigS := signature.ParseSignature(sign.Sig)
+	circuit.Signature.R.X = sig2.X
+	circuit.Signature.R.Y = sig2.Y
+	circuit.Signature.S = sigS
+
+	return nil
+}
+
+// DatasetSplitAndZkpCsvMiMC is DatasetSplitAndZkpMiMC for a signed CSV
+// file.
+func DatasetSplitAndZkpCsvMiMC(file string, proofKey groth16.ProvingKey, r1cs constraint.ConstraintSystem) ([][]*big.Int,
+	groth16.Proof, []*big.Int, []string, *signature.SignatureZKP, error) {
+	csvBytes, err := os.ReadFile(file)
+	if err != nil {
+		return nil, nil, nil, nil, nil, err
+	}
+	vec, cols, _, privateText, signBytes, err := signature.CsvTextToVecAuth(string(csvBytes))
+	if err != nil {
+		return nil, nil, nil, nil, nil, err
+	}
+	if signBytes == nil {
+		return nil, nil, nil, nil, nil, fmt.Errorf("data not signed")
+	}
+	var sign signature.SignatureZKP
+	err = json.Unmarshal(signBytes, &sign)
+	if err != nil {
+		return nil, nil, nil, nil, nil, err
+	}
+
+	splits, proof, commits, pubSign, err := DatasetSplitAndZkpMiMC(vec, cols, privateText, &sign, proofKey, r1cs)
+
+	return splits, proof, commits, cols, pubSign, err
+}
+
+// VerifyDatasetZKpMiMC verifies a proof of DatasetSplitAndZkpMiMC: the
+// shares committed in commits are a splitting of a dataset signed by
+// the owner of pubKey.
+func VerifyDatasetZKpMiMC(proof groth16.Proof, verKey groth16.VerifyingKey, commits []*big.Int, cols []string,
+	sign *signature.SignatureZKP, pubKey sig.PublicKey) (bool, error) {
+	if len(commits) != 3 {
+		return false, fmt.Errorf("expected the commits of 3 nodes")
+	}
+	if sign.SigScheme != signature.SignatureSchemeEdDSABN254 {
+		return false, fmt.Errorf("signature scheme %s not verified by circuit %s", sign.SigScheme, CircuitDatasetMiMCName)
+	}
+	if string(pubKey.Bytes()) != string(sign.PubKey) {
+		return false, fmt.Errorf("public keys do not match")
+	}
+
+	circuit := NewCircuitDatasetMiMC(sign.CircuitVersion, 0)
+	colsHash, err := signature.ColumnsField(sign.CircuitVersion, cols)
+	if err != nil {
+		return false, err
+	}
+	circuit.ColsHash = colsHash
+	err = assignPublicSignature(circuit, sign)
+	if err != nil {
+		return false, err
+	}
+	for i := 0; i < 3; i++ {
+		circuit.ShareCommits[i] = commits[i]
+	}
+
+	publicWitness, err := frontend.NewWitness(circuit, ecc.BN254.ScalarField(), frontend.PublicOnly())
+	if err != nil {
+		return false, err
+	}
+	err = groth16.Verify(proof, verKey, publicWitness)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// VerifyDatasetSplitAndZKpMiMC is VerifyDatasetZKpMiMC also checking
+// that splitI is the share of node id.
+func VerifyDatasetSplitAndZKpMiMC(proof groth16.Proof, verKey groth16.VerifyingKey, splitI []*big.Int, id int,
+	commits []*big.Int, cols []string, sign *signature.SignatureZKP, pubKey sig.PublicKey) (bool, error) {
+	check, err := VerifyDatasetZKpMiMC(proof, verKey, commits, cols, sign, pubKey)
+	if err != nil {
+		return false, err
+	}
+
+	if id < 0 || id >= 3 {
+		return false, fmt.Errorf("invalid node id %d", id)
+	}
+	commit, err := signature.CommitShareMiMC(sign.CircuitVersion, splitI)
+	if err != nil {
+		return false, err
+	}
+	if commit.Cmp(commits[id]) != 0 {
+		return false, fmt.Errorf("commit of the split does not match encrypted values")
+	}
+
+	return check, nil
+}
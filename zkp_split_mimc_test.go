@@ -0,0 +1,113 @@
+package ZKPComponent
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	sig "github.com/consensys/gnark-crypto/signature"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/test"
+	"github.com/krakenh2020/ZKPComponent/data_common"
+	"github.com/krakenh2020/ZKPComponent/signature"
+	"github.com/stretchr/testify/assert"
+)
+
+const mimcTestCsv = "age,BMI\n39,26.97\n-46,28.73\n"
+
+func signMiMC(t *testing.T, csv string, version signature.CircuitVersion) (string, sig.Signer) {
+	signer, err := eddsa.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "data.csv")
+	if csv != "" {
+		assert.NoError(t, os.WriteFile(file, []byte(csv), 0644))
+	} else {
+		file = "datasets/framingham_tiny.csv"
+	}
+	opts := signature.DefaultSignOptions
+	opts.Scheme = signature.CommitSchemeMiMC
+	opts.Circuit = version
+	sign, err := signature.SignCsvWithOptions(file, signer, opts)
+	assert.NoError(t, err)
+	signed := filepath.Join(dir, "signed.csv")
+	assert.NoError(t, signature.WriteSignCsv(file, signed, sign))
+
+	return signed, signer
+}
+
+func TestCircuitDatasetMiMCSolved(t *testing.T) {
+	for _, version := range []signature.CircuitVersion{signature.CircuitV1, signature.CircuitV2} {
+		signed, _ := signMiMC(t, "", version)
+		vec, cols, _, privateText, signBytes, err := signature.CsvToVecAuth(signed)
+		assert.NoError(t, err)
+		var sign signature.SignatureZKP
+		assert.NoError(t, json.Unmarshal(signBytes, &sign))
+
+		splits, commits, err := signature.CreateSharesShamirMiMC(version, vec)
+		assert.NoError(t, err)
+		witness, err := assignCircuitDatasetMiMC(vec, cols, privateText, &sign, splits, commits)
+		assert.NoError(t, err)
+		circuit := NewCircuitDatasetMiMC(version, len(vec))
+		assert.NoError(t, test.IsSolved(circuit, witness, ecc.BN254.ScalarField()))
+
+		// the circuit of another version
+		other := NewCircuitDatasetMiMC(1-version, len(vec))
+		assert.Error(t, test.IsSolved(other, witness, ecc.BN254.ScalarField()))
+
+		// a share not splitting the data
+		splits[1][0] = new(big.Int).Add(splits[1][0], big.NewInt(1))
+		splits[1][0].Mod(splits[1][0], data_common.MPCPrime)
+		commits[1], err = signature.CommitShareMiMC(version, splits[1])
+		assert.NoError(t, err)
+		witness, err = assignCircuitDatasetMiMC(vec, cols, privateText, &sign, splits, commits)
+		assert.NoError(t, err)
+		assert.Error(t, test.IsSolved(circuit, witness, ecc.BN254.ScalarField()))
+	}
+}
+
+func TestDatasetSplitAndZkpMiMC(t *testing.T) {
+	signed, signer := signMiMC(t, mimcTestCsv, signature.CurrentCircuitVersion)
+	check, err := signature.VerifyCsv(signed, signer.Public())
+	assert.NoError(t, err)
+	assert.True(t, check)
+
+	r1cs, err := CompileCircuitDatasetMiMC(signature.CurrentCircuitVersion, 4)
+	assert.NoError(t, err)
+	pk, vk, err := groth16.Setup(r1cs)
+	assert.NoError(t, err)
+
+	splits, proof, commits, cols, pubSign, err := DatasetSplitAndZkpCsvMiMC(signed, pk, r1cs)
+	assert.NoError(t, err)
+	assert.Nil(t, pubSign.RData)
+	assert.Nil(t, pubSign.Commit.R)
+
+	joined, err := data_common.JoinSharesShamir([][]*big.Int{splits[0][:4], splits[1][:4], splits[2][:4]})
+	assert.NoError(t, err)
+	vec, _, _, _, _, err := signature.CsvToVecAuth(signed)
+	assert.NoError(t, err)
+	assert.Equal(t, vec, joined)
+
+	for i := 0; i < 3; i++ {
+		check, err = VerifyDatasetSplitAndZKpMiMC(proof, vk, splits[i], i, commits, cols, pubSign, signer.Public())
+		assert.NoError(t, err)
+		assert.True(t, check)
+	}
+
+	// the share of another node
+	_, err = VerifyDatasetSplitAndZKpMiMC(proof, vk, splits[0], 1, commits, cols, pubSign, signer.Public())
+	assert.Error(t, err)
+	// other columns
+	_, err = VerifyDatasetZKpMiMC(proof, vk, commits, []string{"age", "bmi"}, pubSign, signer.Public())
+	assert.Error(t, err)
+
+	// the dataset is refused by the split with Pedersen commitments
+	_, _, _, _, _, err = DatasetSplitAndZkpCsv(signed, pk, r1cs)
+	assert.Error(t, err)
+}
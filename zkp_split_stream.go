@@ -0,0 +1,286 @@
+package ZKPComponent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/constraint"
+	"github.com/krakenh2020/ZKPComponent/data_common"
+	"github.com/krakenh2020/ZKPComponent/encryption"
+	"github.com/krakenh2020/ZKPComponent/signature"
+)
+
+// streamTrailer is the trailer of the share streams written by
+// CsvSplitEncryptAndZkpStream.
+type streamTrailer struct {
+	// R is the share of the randomness of the commitment to the data
+	R    *big.Int
+	Auth *AuthProof
+}
+
+// CsvSplitEncryptAndZkpStream is CsvTextSplitEncryptAndZkpToWriter for
+// the signed CSV data read from r, with a memory use that does not
+// depend on the size of the dataset. The share of node i is written to
+// outputs[i] as a share stream encrypted for pubKeys[i], see
+// data_common.ShareWriter, with for each row the shares of the values
+// followed by their hide values. The commitment of the share of node 0
+// is computed block by block while splitting. Once the signature
+// following the data is read, the proof is computed and written with
+// the share of the randomness of the commitment as the trailer of
+// every stream. The dataset must be committed with the generators of
+// signature.CurrentGeneratorVersion.
+func CsvSplitEncryptAndZkpStream(r io.Reader, outputs []io.Writer, proofKey groth16.ProvingKey,
+	r1cs constraint.ConstraintSystem, pubKeys [][]byte) ([]string, int, *AuthProof, error) {
+	if len(outputs) != 3 || len(pubKeys) != 3 {
+		return nil, 0, nil, fmt.Errorf("expected 3 outputs and 3 keys")
+	}
+	rows, err := data_common.NewCsvRowReader(r)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	cols := rows.Cols()
+
+	streams := make([]*encryption.StreamWriter, 3)
+	writers := make([]*data_common.ShareWriter, 3)
+	for i := 0; i < 3; i++ {
+		streams[i], err = encryption.NewStreamWriter(outputs[i], pubKeys[i])
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		writers[i], err = data_common.NewShareWriter(streams[i], cols, encryption.EncodingBin32)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+	}
+	committer, err := signature.NewShareCommitter(context.Background(), signature.CurrentGeneratorVersion)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	n := len(cols)
+	row := make([]*big.Int, n)
+	for {
+		err = rows.Next(row)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, 0, nil, err
+		}
+
+		// the randomness is shared once the signature is read
+		shares, err := signature.CreateSharesShamirSpecial(row, new(big.Int))
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		for i := 0; i < 3; i++ {
+			err = writers[i].Write(shares[i][:2*n])
+			if err != nil {
+				return nil, 0, nil, err
+			}
+		}
+		err = committer.Add(shares[0][:n], shares[0][n:2*n])
+		if err != nil {
+			return nil, 0, nil, err
+		}
+	}
+
+	privateText, err := rows.Readln()
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	signText, err := rows.Readln()
+	if err != nil && err != io.EOF {
+		return nil, 0, nil, err
+	}
+	var sign signature.SignatureZKP
+	err = json.Unmarshal([]byte(signText), &sign)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	if sign.GenVersion != signature.CurrentGeneratorVersion || sign.Scheme != signature.CommitSchemeP256 {
+		return nil, 0, nil, fmt.Errorf("dataset not committed with the current Pedersen generators")
+	}
+
+	rShares, err := signature.CreateSharesShamirSpecial(nil, sign.RData)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	commit0, err := committer.Commit(rShares[0][0])
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	commits := splitCommits(commit0, sign.CommitData)
+	proof, err := proveDataset(cols, privateText, &sign, proofKey, r1cs)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	sign.Commit.R = nil
+	aProof, err := NewAuthProof(proof, commits, &sign)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	for i := 0; i < 3; i++ {
+		trailer, err := json.Marshal(&streamTrailer{R: rShares[i][0], Auth: aProof})
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		err = writers[i].Close(trailer)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		err = streams[i].Close()
+		if err != nil {
+			return nil, 0, nil, err
+		}
+	}
+
+	return cols, rows.Rows(), aProof, nil
+}
+
+// ShareStreamReader reads the share of a node written by
+// CsvSplitEncryptAndZkpStream row by row, computing the commitment of
+// the share while reading, so that its memory use does not depend on
+// the size of the share.
+type ShareStreamReader struct {
+	reader    *data_common.ShareReader
+	committer *signature.ShareCommitter
+	keys      VerifyingKeys
+	id        int
+	rows      int
+}
+
+// OpenShareStreamReader decrypts the share stream of node id, whose
+// proof is verified with the key of keys for the circuit of its
+// signature.
+func OpenShareStreamReader(r io.Reader, keys VerifyingKeys, id int, pubKey, secKey []byte) (*ShareStreamReader, error) {
+	if id < 0 || id >= 3 {
+		return nil, fmt.Errorf("invalid node id %d", id)
+	}
+	reader, err := data_common.OpenShareStream(r, pubKey, secKey)
+	if err != nil {
+		return nil, err
+	}
+	committer, err := signature.NewShareCommitter(context.Background(), signature.CurrentGeneratorVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ShareStreamReader{reader: reader, committer: committer, keys: keys, id: id}, nil
+}
+
+func (s *ShareStreamReader) Cols() []string {
+	return s.reader.Cols()
+}
+
+// Rows returns the number of rows read so far.
+func (s *ShareStreamReader) Rows() int {
+	return s.rows
+}
+
+// Next returns the next row of the share, the shares of the values
+// followed by their hide values, or io.EOF after the last row. The rows
+// are only authenticated once Verify succeeds.
+func (s *ShareStreamReader) Next() ([]*big.Int, error) {
+	n := len(s.reader.Cols())
+	row, err := s.reader.Read(2 * n)
+	if err != nil {
+		return nil, err
+	}
+	if len(row) != 2*n {
+		return nil, fmt.Errorf("incomplete row %d", s.rows+1)
+	}
+	err = s.committer.Add(row[:n], row[n:])
+	if err != nil {
+		return nil, err
+	}
+	s.rows++
+
+	return row, nil
+}
+
+// Verify reads the trailer after the last row and verifies the share
+// like VerifyDatasetSplitAndZKpCsv. It returns the share of the
+// randomness of the commitment and the AuthProof. If the trailer is
+// read but the proof or the commitment of the share does not verify,
+// the AuthProof is returned with the error.
+func (s *ShareStreamReader) Verify() (*big.Int, *AuthProof, error) {
+	trailerBytes, err := s.reader.Trailer()
+	if err != nil {
+		return nil, nil, err
+	}
+	var trailer streamTrailer
+	err = json.Unmarshal(trailerBytes, &trailer)
+	if err != nil {
+		return nil, nil, err
+	}
+	if trailer.R == nil || trailer.Auth == nil || trailer.Auth.Sign == nil || trailer.Auth.Sign.CommitData == nil ||
+		len(trailer.Auth.Commits) != 3 {
+		return nil, nil, fmt.Errorf("incomplete authentication proof")
+	}
+	if trailer.Auth.Sign.GenVersion != signature.CurrentGeneratorVersion {
+		return nil, nil, fmt.Errorf("dataset not committed with the current Pedersen generators")
+	}
+
+	proof, commits, sign, sigPubKey, err := ExpandAuthProof(trailer.Auth)
+	if err != nil {
+		return nil, trailer.Auth, err
+	}
+	verKey, err := s.keys.VerifyingKey(DatasetCircuitId(sign))
+	if err != nil {
+		return nil, trailer.Auth, err
+	}
+	_, err = VerifyDatasetZKp(proof, verKey, commits, s.Cols(), sign, sigPubKey)
+	if err != nil {
+		return nil, trailer.Auth, err
+	}
+	commit, err := s.committer.Commit(trailer.R)
+	if err != nil {
+		return nil, trailer.Auth, err
+	}
+	if !commit.Equal(commits[s.id]) {
+		return nil, trailer.Auth, fmt.Errorf("commit of the split does not match encrypted values")
+	}
+
+	return trailer.R, trailer.Auth, nil
+}
+
+// ReadShareStream reads the whole share of node id with a
+// ShareStreamReader. It returns the share in the layout of
+// signature.CreateSharesShamirSpecial, the columns and the AuthProof.
+// If the trailer is read but the proof or the commitment of the share
+// does not verify, the AuthProof is returned with the error.
+func ReadShareStream(r io.Reader, keys VerifyingKeys, id int, pubKey, secKey []byte) ([]*big.Int,
+	[]string, *AuthProof, error) {
+	reader, err := OpenShareStreamReader(r, keys, id, pubKey, secKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	n := len(reader.Cols())
+	vals := make([]*big.Int, 0)
+	hides := make([]*big.Int, 0)
+	for {
+		row, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		vals = append(vals, row[:n]...)
+		hides = append(hides, row[n:]...)
+	}
+
+	rShare, aProof, err := reader.Verify()
+	if err != nil {
+		return nil, nil, aProof, err
+	}
+	share := append(append(vals, hides...), rShare)
+
+	return share, reader.Cols(), aProof, nil
+}
@@ -0,0 +1,77 @@
+package ZKPComponent
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	"github.com/krakenh2020/ZKPComponent/data_common"
+	"github.com/krakenh2020/ZKPComponent/key_management"
+	"github.com/krakenh2020/ZKPComponent/signature"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCsvSplitEncryptAndZkpStream(t *testing.T) {
+	pk, err := LoadProvingKey("proofKey.txt")
+	assert.NoError(t, err)
+	keys, err := LoadLegacyVerifyingKeys("verifyKey.txt")
+	assert.NoError(t, err)
+	vk := keys[CircuitId{Name: CircuitDatasetName, Version: signature.CurrentCircuitVersion}]
+	r1cs, err := CompileCircuitDataset()
+	assert.NoError(t, err)
+	pubKey, secKey := key_management.GenerateKeypair()
+
+	signer, err := eddsa.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	signed := filepath.Join(t.TempDir(), "signed.csv")
+	sign, err := signature.SignCsv("datasets/framingham_tiny.csv", signer)
+	assert.NoError(t, err)
+	assert.NoError(t, signature.WriteSignCsv("datasets/framingham_tiny.csv", signed, sign))
+
+	f, err := os.Open(signed)
+	assert.NoError(t, err)
+	defer f.Close()
+	outputs := make([]bytes.Buffer, 3)
+	cols, rows, aProof, err := CsvSplitEncryptAndZkpStream(f, []io.Writer{&outputs[0], &outputs[1], &outputs[2]},
+		pk, r1cs, [][]byte{pubKey, pubKey, pubKey})
+	assert.NoError(t, err)
+
+	text, err := os.ReadFile(signed)
+	assert.NoError(t, err)
+	vec, cols2, _, _, _, err := signature.CsvTextToVecAuth(string(text))
+	assert.NoError(t, err)
+	assert.Equal(t, cols2, cols)
+	assert.Equal(t, len(vec), rows*len(cols))
+
+	shares := make([][]*big.Int, 3)
+	for i := 0; i < 3; i++ {
+		var aProof2 *AuthProof
+		shares[i], cols2, aProof2, err = ReadShareStream(bytes.NewReader(outputs[i].Bytes()), keys, i, pubKey, secKey)
+		assert.NoError(t, err)
+		assert.Equal(t, cols, cols2)
+		assert.Equal(t, aProof.Commits, aProof2.Commits)
+		// the shares verify as the shares of a container
+		proof, commits, sign, pubSig, err := ExpandAuthProof(aProof2)
+		assert.NoError(t, err)
+		_, err = VerifyDatasetSplitAndZKpCsv(proof, vk, shares[i], i, commits, cols, sign, pubSig)
+		assert.NoError(t, err)
+	}
+	res, err := data_common.JoinSharesShamir([][]*big.Int{shares[0][:len(vec)], shares[1][:len(vec)], shares[2][:len(vec)]})
+	assert.NoError(t, err)
+	for i := range vec {
+		assert.Equal(t, 0, vec[i].Cmp(res[i]), i)
+	}
+
+	// the share of another node is refused
+	_, _, _, err = ReadShareStream(bytes.NewReader(outputs[0].Bytes()), keys, 1, pubKey, secKey)
+	assert.Error(t, err)
+
+	// no key of the circuit of the signature
+	_, _, _, err = ReadShareStream(bytes.NewReader(outputs[0].Bytes()), VerifyingKeySet{}, 0, pubKey, secKey)
+	assert.Error(t, err)
+}
@@ -0,0 +1,611 @@
+package ZKPComponent
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	sig "github.com/consensys/gnark-crypto/signature"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/constraint"
+	cs_bn254 "github.com/consensys/gnark/constraint/bn254"
+	"github.com/krakenh2020/ZKPComponent/signature"
+	"github.com/krakenh2020/ZKPComponent/signature/ec"
+)
+
+const (
+	// CircuitDatasetName is the name of CircuitDataset in a CircuitId.
+	CircuitDatasetName = "dataset"
+	// CircuitDatasetMiMCName is the name of CircuitDatasetMiMC in a
+	// CircuitId, whose Size is the number of values of the datasets.
+	CircuitDatasetMiMCName = "dataset-mimc"
+	// CircuitDatasetOpeningName is the name of CircuitDatasetOpening in
+	// a CircuitId.
+	CircuitDatasetOpeningName = "dataset-opening"
+	// CircuitDatasetECDSAName is the name of CircuitDatasetECDSA in a
+	// CircuitId.
+	CircuitDatasetECDSAName = "dataset-ecdsa"
+)
+
+// DatasetCircuitName returns the name of the circuit proving the
+// datasets of the signature with CommitSchemeP256: CircuitDataset for
+// EdDSA, CircuitDatasetECDSA for ECDSA and CircuitDatasetOpening for
+// the schemes not verified in the circuit.
+func DatasetCircuitName(sign *signature.SignatureZKP) string {
+	switch sign.SigScheme {
+	case signature.SignatureSchemeEdDSABN254:
+		return CircuitDatasetName
+	case signature.SignatureSchemeECDSAP256:
+		return CircuitDatasetECDSAName
+	}
+
+	return CircuitDatasetOpeningName
+}
+
+// DatasetCircuitId returns the id of the circuit proving the datasets
+// of the signature with CommitSchemeP256.
+func DatasetCircuitId(sign *signature.SignatureZKP) CircuitId {
+	return CircuitId{Name: DatasetCircuitName(sign), Version: sign.CircuitVersion}
+}
+
+// VerifyingKeys gives the verifying keys of the circuits, to verify
+// the proofs of datasets of every signature scheme with the key of
+// DatasetCircuitId.
+type VerifyingKeys interface {
+	VerifyingKey(id CircuitId) (groth16.VerifyingKey, error)
+}
+
+// VerifyingKeySet is the VerifyingKeys of loaded circuits.
+type VerifyingKeySet map[CircuitId]groth16.VerifyingKey
+
+// NewVerifyingKeySet returns the set of the verifying keys of the
+// artifacts.
+func NewVerifyingKeySet(artifacts ...*CircuitArtifacts) VerifyingKeySet {
+	s := make(VerifyingKeySet)
+	for _, a := range artifacts {
+		s[a.Manifest.Circuit] = a.VerifyingKey
+	}
+
+	return s
+}
+
+// LoadLegacyVerifyingKeys returns the set of the verifying key stored
+// as a JSON array of bytes, as verifyKey.txt, made for CircuitDataset
+// of signature.CurrentCircuitVersion.
+func LoadLegacyVerifyingKeys(file string) (VerifyingKeySet, error) {
+	vk, err := LoadVerifyingKey(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return VerifyingKeySet{{Name: CircuitDatasetName, Version: signature.CurrentCircuitVersion}: vk}, nil
+}
+
+func (s VerifyingKeySet) VerifyingKey(id CircuitId) (groth16.VerifyingKey, error) {
+	vk, ok := s[id]
+	if !ok {
+		return nil, fmt.Errorf("no verifying key of circuit %s", id)
+	}
+
+	return vk, nil
+}
+
+// artifact files of a circuit in an ArtifactStore
+const (
+	manifestFile     = "manifest.json"
+	r1csFile         = "circuit.r1cs"
+	provingKeyFile   = "proving.key"
+	verifyingKeyFile = "verifying.key"
+)
+
+// CircuitId identifies a circuit: its name, its version and, for
+// circuits depending on the length of the dataset, its size.
+type CircuitId struct {
+	Name    string
+	Version signature.CircuitVersion
+	Size    int `json:",omitempty"`
+}
+
+func (id CircuitId) String() string {
+	if id.Size != 0 {
+		return fmt.Sprintf("%s-v%d-%d", id.Name, id.Version, id.Size)
+	}
+
+	return fmt.Sprintf("%s-v%d", id.Name, id.Version)
+}
+
+// Compile compiles the circuit of the id for Groth16 over BN254.
+func (id CircuitId) Compile() (constraint.ConstraintSystem, error) {
+	switch id.Name {
+	case CircuitDatasetName:
+		if id.Size != 0 {
+			return nil, fmt.Errorf("circuit %s has no size", id.Name)
+		}
+		return CompileCircuitDatasetVersion(id.Version)
+	case CircuitDatasetOpeningName:
+		if id.Size != 0 {
+			return nil, fmt.Errorf("circuit %s has no size", id.Name)
+		}
+		return CompileCircuitDatasetOpening(id.Version)
+	case CircuitDatasetECDSAName:
+		if id.Size != 0 {
+			return nil, fmt.Errorf("circuit %s has no size", id.Name)
+		}
+		return CompileCircuitDatasetECDSA(id.Version)
+	case CircuitDatasetMiMCName:
+		if id.Size <= 0 {
+			return nil, fmt.Errorf("invalid size %d of circuit %s", id.Size, id.Name)
+		}
+		return CompileCircuitDatasetMiMC(id.Version, id.Size)
+	}
+
+	return nil, fmt.Errorf("unknown circuit %s", id.Name)
+}
+
+// CircuitManifest describes the artifacts of a circuit in an
+// ArtifactStore. Fingerprint is the SHA-256 of the constraint system
+// the keys were made for, in gnark's binary format, the other hashes
+// are those of the key files.
+type CircuitManifest struct {
+	Circuit      CircuitId
+	Fingerprint  string
+	ProvingKey   string `json:",omitempty"`
+	VerifyingKey string
+}
+
+// CircuitArtifacts are a compiled circuit and its Groth16 keys.
+// ProvingKey and R1CS are nil if only the verifier was loaded.
+type CircuitArtifacts struct {
+	Manifest     CircuitManifest
+	R1CS         constraint.ConstraintSystem
+	ProvingKey   groth16.ProvingKey
+	VerifyingKey groth16.VerifyingKey
+}
+
+// CircuitFingerprint returns the hex SHA-256 of the constraint system
+// in gnark's binary format.
+func CircuitFingerprint(r1cs constraint.ConstraintSystem) (string, error) {
+	h := sha256.New()
+	_, err := r1cs.WriteTo(h)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// NewCircuitArtifacts returns the artifacts of the circuit id for the
+// constraint system and the keys made for it.
+func NewCircuitArtifacts(id CircuitId, r1cs constraint.ConstraintSystem, pk groth16.ProvingKey,
+	vk groth16.VerifyingKey) (*CircuitArtifacts, error) {
+	err := checkKeys(r1cs, pk, vk)
+	if err != nil {
+		return nil, fmt.Errorf("keys not made for circuit %s: %w", id, err)
+	}
+
+	fingerprint, err := CircuitFingerprint(r1cs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CircuitArtifacts{Manifest: CircuitManifest{Circuit: id, Fingerprint: fingerprint}, R1CS: r1cs,
+		ProvingKey: pk, VerifyingKey: vk}, nil
+}
+
+// checkKeys returns an error if the keys do not match the constraint
+// system: the FFT domain, the wires and the points at infinity of the A
+// and B polynomials, the public wires and the commitments must be those
+// of the circuit, and the proving and the verifying key must have the
+// same [α], [β] and [δ].
+func checkKeys(r1cs constraint.ConstraintSystem, pk groth16.ProvingKey, vk groth16.VerifyingKey) error {
+	ccs, ok := r1cs.(*cs_bn254.R1CS)
+	if !ok {
+		return fmt.Errorf("constraint system not of BN254")
+	}
+	pkBn254, ok := pk.(*groth16_bn254.ProvingKey)
+	if !ok {
+		return fmt.Errorf("proving key not of BN254")
+	}
+	vkBn254, ok := vk.(*groth16_bn254.VerifyingKey)
+	if !ok {
+		return fmt.Errorf("verifying key not of BN254")
+	}
+	commitments, ok := ccs.GetCommitments().(constraint.Groth16Commitments)
+	if !ok {
+		return fmt.Errorf("commitments not of Groth16")
+	}
+
+	internal, secret, public := ccs.GetNbVariables()
+	nbWires := internal + secret + public
+	nbCommitted := 0
+	for _, c := range commitments.GetPrivateCommitted() {
+		nbCommitted += len(c)
+	}
+	// the commitments are public wires of the verifier
+	if len(vkBn254.G1.K) != public+len(commitments) || len(vkBn254.PublicAndCommitmentCommitted) != len(commitments) {
+		return fmt.Errorf("public wires differ")
+	}
+	if len(pkBn254.G1.K) != internal+secret-nbCommitted-len(commitments) ||
+		len(pkBn254.CommitmentKeys) != len(commitments) {
+		return fmt.Errorf("private wires differ")
+	}
+	if pkBn254.Domain.Cardinality != ecc.NextPowerOfTwo(uint64(ccs.GetNbConstraints())) {
+		return fmt.Errorf("domain of another number of constraints")
+	}
+
+	inA := make([]bool, nbWires)
+	inB := make([]bool, nbWires)
+	it := ccs.GetR1CIterator()
+	for c := it.Next(); c != nil; c = it.Next() {
+		for _, t := range c.L {
+			inA[t.WireID()] = inA[t.WireID()] || t.CoeffID() != constraint.CoeffIdZero
+		}
+		for _, t := range c.R {
+			inB[t.WireID()] = inB[t.WireID()] || t.CoeffID() != constraint.CoeffIdZero
+		}
+	}
+	if len(pkBn254.InfinityA) != nbWires || len(pkBn254.InfinityB) != nbWires ||
+		uint64(len(pkBn254.G1.A))+pkBn254.NbInfinityA != uint64(nbWires) ||
+		uint64(len(pkBn254.G1.B))+pkBn254.NbInfinityB != uint64(nbWires) ||
+		len(pkBn254.G2.B) != len(pkBn254.G1.B) {
+		return fmt.Errorf("wires differ")
+	}
+	for i := 0; i < nbWires; i++ {
+		if pkBn254.InfinityA[i] == inA[i] || pkBn254.InfinityB[i] == inB[i] {
+			return fmt.Errorf("wire %d used in other constraints", i)
+		}
+	}
+
+	if !pkBn254.G1.Alpha.Equal(&vkBn254.G1.Alpha) || !pkBn254.G1.Beta.Equal(&vkBn254.G1.Beta) ||
+		!pkBn254.G1.Delta.Equal(&vkBn254.G1.Delta) || !pkBn254.G2.Beta.Equal(&vkBn254.G2.Beta) ||
+		!pkBn254.G2.Delta.Equal(&vkBn254.G2.Delta) {
+		return fmt.Errorf("proving and verifying key of different setups")
+	}
+
+	return nil
+}
+
+// Check returns an error if the artifacts are not those of the circuit
+// id, to refuse proving or verifying with keys made for another
+// circuit.
+func (a *CircuitArtifacts) Check(id CircuitId) error {
+	if a.Manifest.Circuit != id {
+		return fmt.Errorf("keys made for circuit %s, not %s", a.Manifest.Circuit, id)
+	}
+
+	return nil
+}
+
+// CheckCompiled returns an error if the keys were not made for the
+// circuit as compiled by this code.
+func (a *CircuitArtifacts) CheckCompiled() error {
+	r1cs, err := a.Manifest.Circuit.Compile()
+	if err != nil {
+		return err
+	}
+	fingerprint, err := CircuitFingerprint(r1cs)
+	if err != nil {
+		return err
+	}
+	if fingerprint != a.Manifest.Fingerprint {
+		return fmt.Errorf("keys made for another constraint system of circuit %s", a.Manifest.Circuit)
+	}
+
+	return nil
+}
+
+// ArtifactStore is a directory storing the compiled circuits and their
+// keys in gnark's binary format, one subdirectory per CircuitId. It
+// does not hold CircuitDatasetECDSA, see checkStored.
+type ArtifactStore struct {
+	dir string
+}
+
+// checkStored refuses the circuits with gnark commitments, whose keys
+// cannot be made by the setup ceremony, see setup_ceremony.InitPhase2:
+// CircuitDatasetECDSA.
+func checkStored(id CircuitId) error {
+	if id.Name == CircuitDatasetECDSAName {
+		return fmt.Errorf("circuit %s has commitments, not supported by the artifact store", id)
+	}
+
+	return nil
+}
+
+func NewArtifactStore(dir string) *ArtifactStore {
+	return &ArtifactStore{dir: dir}
+}
+
+func (s *ArtifactStore) path(id CircuitId, file string) string {
+	return filepath.Join(s.dir, id.String(), file)
+}
+
+// Setup compiles the circuit id, runs the Groth16 setup and saves the
+// artifacts. The setup is not trusted: its randomness is known to this
+// process.
+func (s *ArtifactStore) Setup(id CircuitId) (*CircuitArtifacts, error) {
+	err := checkStored(id)
+	if err != nil {
+		return nil, err
+	}
+	r1cs, err := id.Compile()
+	if err != nil {
+		return nil, err
+	}
+	pk, vk, err := groth16.Setup(r1cs)
+	if err != nil {
+		return nil, err
+	}
+	a, err := NewCircuitArtifacts(id, r1cs, pk, vk)
+	if err != nil {
+		return nil, err
+	}
+
+	return a, s.Save(a)
+}
+
+// ImportLegacyKeys saves the keys stored as JSON arrays of bytes, as
+// proofKey.txt and verifyKey.txt, with the compiled circuit id.
+func (s *ArtifactStore) ImportLegacyKeys(id CircuitId, proofKeyFile, verifyKeyFile string) (*CircuitArtifacts, error) {
+	pk, err := LoadProvingKey(proofKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	vk, err := LoadVerifyingKey(verifyKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	r1cs, err := id.Compile()
+	if err != nil {
+		return nil, err
+	}
+	a, err := NewCircuitArtifacts(id, r1cs, pk, vk)
+	if err != nil {
+		return nil, err
+	}
+
+	return a, s.Save(a)
+}
+
+// writeArtifact writes the object to the file of the circuit and
+// returns the hex SHA-256 of its content.
+func (s *ArtifactStore) writeArtifact(id CircuitId, file string, o io.WriterTo) (string, error) {
+	var buf bytes.Buffer
+	_, err := o.WriteTo(&buf)
+	if err != nil {
+		return "", err
+	}
+	digest := sha256.Sum256(buf.Bytes())
+
+	return hex.EncodeToString(digest[:]), writeFileAtomic(s.path(id, file), buf.Bytes())
+}
+
+// writeFileAtomic writes data to a temporary file and renames it, so
+// that the file is never left half written.
+func writeFileAtomic(file string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if err2 := tmp.Close(); err == nil {
+		err = err2
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), file)
+}
+
+// Save writes the artifacts, the manifest last.
+func (s *ArtifactStore) Save(a *CircuitArtifacts) error {
+	id := a.Manifest.Circuit
+	if a.R1CS == nil || a.ProvingKey == nil || a.VerifyingKey == nil {
+		return fmt.Errorf("incomplete artifacts of circuit %s", id)
+	}
+	err := checkStored(id)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Join(s.dir, id.String()), 0755)
+	if err != nil {
+		return err
+	}
+
+	fingerprint, err := s.writeArtifact(id, r1csFile, a.R1CS)
+	if err != nil {
+		return err
+	}
+	if fingerprint != a.Manifest.Fingerprint {
+		return fmt.Errorf("constraint system of circuit %s does not match its fingerprint", id)
+	}
+	a.Manifest.ProvingKey, err = s.writeArtifact(id, provingKeyFile, a.ProvingKey)
+	if err != nil {
+		return err
+	}
+	a.Manifest.VerifyingKey, err = s.writeArtifact(id, verifyingKeyFile, a.VerifyingKey)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := json.MarshalIndent(a.Manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(s.path(id, manifestFile), manifest)
+}
+
+// readArtifact reads the file of the circuit into o, checking that its
+// hex SHA-256 is digest.
+func (s *ArtifactStore) readArtifact(id CircuitId, file, digest string, o io.ReaderFrom) error {
+	data, err := os.ReadFile(s.path(id, file))
+	if err != nil {
+		return err
+	}
+	d := sha256.Sum256(data)
+	if hex.EncodeToString(d[:]) != digest {
+		return fmt.Errorf("%s of circuit %s does not match the manifest", file, id)
+	}
+	_, err = o.ReadFrom(bytes.NewReader(data))
+
+	return err
+}
+
+func (s *ArtifactStore) readManifest(id CircuitId) (*CircuitManifest, error) {
+	err := checkStored(id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(s.path(id, manifestFile))
+	if err != nil {
+		return nil, err
+	}
+	var m CircuitManifest
+	err = json.Unmarshal(data, &m)
+	if err != nil {
+		return nil, err
+	}
+	if m.Circuit != id {
+		return nil, fmt.Errorf("keys made for circuit %s, not %s", m.Circuit, id)
+	}
+
+	return &m, nil
+}
+
+// Load reads the artifacts of the circuit id, checking them against the
+// manifest, the keys against the constraint system and the constraint
+// system against the one compiled from the circuit, so that they can be
+// used to prove.
+func (s *ArtifactStore) Load(id CircuitId) (*CircuitArtifacts, error) {
+	m, err := s.readManifest(id)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &CircuitArtifacts{Manifest: *m, R1CS: groth16.NewCS(ecc.BN254), ProvingKey: groth16.NewProvingKey(ecc.BN254),
+		VerifyingKey: groth16.NewVerifyingKey(ecc.BN254)}
+	err = s.readArtifact(id, r1csFile, m.Fingerprint, a.R1CS)
+	if err != nil {
+		return nil, err
+	}
+	err = s.readArtifact(id, provingKeyFile, m.ProvingKey, a.ProvingKey)
+	if err != nil {
+		return nil, err
+	}
+	err = s.readArtifact(id, verifyingKeyFile, m.VerifyingKey, a.VerifyingKey)
+	if err != nil {
+		return nil, err
+	}
+	err = checkKeys(a.R1CS, a.ProvingKey, a.VerifyingKey)
+	if err != nil {
+		return nil, fmt.Errorf("keys not made for circuit %s: %w", id, err)
+	}
+	err = a.CheckCompiled()
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// LoadVerifier reads only the verifying key of the circuit id.
+func (s *ArtifactStore) LoadVerifier(id CircuitId) (*CircuitArtifacts, error) {
+	m, err := s.readManifest(id)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &CircuitArtifacts{Manifest: *m, VerifyingKey: groth16.NewVerifyingKey(ecc.BN254)}
+	err = s.readArtifact(id, verifyingKeyFile, m.VerifyingKey, a.VerifyingKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// VerifyingKey loads the verifying key of the circuit id, checking that
+// the manifest of the artifacts is the one of the circuit.
+func (s *ArtifactStore) VerifyingKey(id CircuitId) (groth16.VerifyingKey, error) {
+	a, err := s.LoadVerifier(id)
+	if err != nil {
+		return nil, err
+	}
+	err = a.Check(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.VerifyingKey, nil
+}
+
+// DatasetSplitAndZkpWithArtifacts is DatasetSplitAndZkpCsvText with the
+// artifacts of the circuit of DatasetCircuitName, refusing keys made
+// for another circuit or circuit version than the one of the
+// signature.
+func DatasetSplitAndZkpWithArtifacts(vec []*big.Int, cols []string, privateText string, signBytes []byte,
+	a *CircuitArtifacts) ([][]*big.Int, groth16.Proof, []*ec.Ec, *signature.SignatureZKP, error) {
+	var sign signature.SignatureZKP
+	err := json.Unmarshal(signBytes, &sign)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	err = a.Check(DatasetCircuitId(&sign))
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if a.ProvingKey == nil || a.R1CS == nil {
+		return nil, nil, nil, nil, fmt.Errorf("missing proving key of circuit %s", a.Manifest.Circuit)
+	}
+
+	return DatasetSplitAndZkpCsvText(vec, cols, privateText, signBytes, a.ProvingKey, a.R1CS)
+}
+
+// CsvTextSplitEncryptAndZkpWithArtifacts is
+// CsvTextSplitEncryptAndZkpToWriter with the artifacts of the circuit
+// of DatasetCircuitName, refusing keys made for another circuit or
+// circuit version than the one of the signature.
+func CsvTextSplitEncryptAndZkpWithArtifacts(csvText string, w io.Writer, a *CircuitArtifacts, pubKeys [][]byte) (
+	[][]*big.Int, groth16.Proof, []*ec.Ec, []string, *signature.SignatureZKP, error) {
+	if len(pubKeys) != 3 {
+		return nil, nil, nil, nil, nil, fmt.Errorf("expected 3 public keys")
+	}
+	vec, cols, _, privateText, signBytes, err := signature.CsvTextToVecAuth(csvText)
+	if err != nil {
+		return nil, nil, nil, nil, nil, err
+	}
+	shares, proof, commits, sign, err := DatasetSplitAndZkpWithArtifacts(vec, cols, privateText, signBytes, a)
+	if err != nil {
+		return nil, nil, nil, nil, nil, err
+	}
+	err = writeContainer(w, shares, proof, commits, cols, sign, pubKeys)
+	if err != nil {
+		return nil, nil, nil, nil, nil, err
+	}
+
+	return shares, proof, commits, cols, sign, nil
+}
+
+// VerifyDatasetZKpWithArtifacts is VerifyDatasetZKp with the artifacts
+// of the circuit of DatasetCircuitName, refusing keys made for another
+// circuit or circuit version than the one of the signature.
+func VerifyDatasetZKpWithArtifacts(proof groth16.Proof, a *CircuitArtifacts, commits []*ec.Ec, cols []string,
+	sig *signature.SignatureZKP, pubKey sig.PublicKey) (bool, error) {
+	err := a.Check(DatasetCircuitId(sig))
+	if err != nil {
+		return false, err
+	}
+
+	return VerifyDatasetZKp(proof, a.VerifyingKey, commits, cols, sig, pubKey)
+}
@@ -0,0 +1,155 @@
+package ZKPComponent
+
+import (
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/krakenh2020/ZKPComponent/signature"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArtifactStore(t *testing.T) {
+	store := NewArtifactStore(t.TempDir())
+	id := CircuitId{Name: CircuitDatasetName, Version: signature.CurrentCircuitVersion}
+	_, err := store.ImportLegacyKeys(id, "proofKey.txt", "verifyKey.txt")
+	assert.NoError(t, err)
+
+	a, err := store.Load(id)
+	assert.NoError(t, err)
+	assert.NoError(t, a.CheckCompiled())
+	fingerprint, err := CircuitFingerprint(a.R1CS)
+	assert.NoError(t, err)
+	assert.Equal(t, a.Manifest.Fingerprint, fingerprint)
+
+	signer, err := eddsa.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	signed := filepath.Join(t.TempDir(), "signed.csv")
+	sign, err := signature.SignCsv("datasets/framingham_tiny.csv", signer)
+	assert.NoError(t, err)
+	assert.NoError(t, signature.WriteSignCsv("datasets/framingham_tiny.csv", signed, sign))
+	vec, cols, _, privateText, signBytes, err := signature.CsvToVecAuth(signed)
+	assert.NoError(t, err)
+
+	_, proof, commits, pubSign, err := DatasetSplitAndZkpWithArtifacts(vec, cols, privateText, signBytes, a)
+	assert.NoError(t, err)
+	verifier, err := store.LoadVerifier(id)
+	assert.NoError(t, err)
+	check, err := VerifyDatasetZKpWithArtifacts(proof, verifier, commits, cols, pubSign, signer.Public())
+	assert.NoError(t, err)
+	assert.True(t, check)
+
+	// the key of another signer
+	signer2, err := eddsa.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	_, err = VerifyDatasetZKpWithArtifacts(proof, verifier, commits, cols, pubSign, signer2.Public())
+	assert.EqualError(t, err, "public keys do not match")
+
+	// a signature of another circuit version is refused
+	pubSign.CircuitVersion = signature.CircuitV1
+	_, err = VerifyDatasetZKpWithArtifacts(proof, verifier, commits, cols, pubSign, signer.Public())
+	assert.Error(t, err)
+	opts := signature.DefaultSignOptions
+	opts.Circuit = signature.CircuitV1
+	sign, err = signature.SignCsvWithOptions("datasets/framingham_tiny.csv", signer, opts)
+	assert.NoError(t, err)
+	assert.NoError(t, signature.WriteSignCsv("datasets/framingham_tiny.csv", signed, sign))
+	vec, cols, _, privateText, signBytes, err = signature.CsvToVecAuth(signed)
+	assert.NoError(t, err)
+	_, _, _, _, err = DatasetSplitAndZkpWithArtifacts(vec, cols, privateText, signBytes, a)
+	assert.Error(t, err)
+
+	// keys stored for another circuit
+	_, err = store.Load(CircuitId{Name: CircuitDatasetName, Version: signature.CircuitV1})
+	assert.Error(t, err)
+	other := CircuitId{Name: CircuitDatasetName, Version: signature.CircuitV1}
+	assert.NoError(t, os.Rename(filepath.Join(store.dir, id.String()), filepath.Join(store.dir, other.String())))
+	_, err = store.Load(other)
+	assert.Error(t, err)
+	assert.NoError(t, os.Rename(filepath.Join(store.dir, other.String()), filepath.Join(store.dir, id.String())))
+
+	// keys of another constraint system
+	a.Manifest.Circuit = other
+	assert.Error(t, a.CheckCompiled())
+
+	// corrupted key
+	vkFile := store.path(id, verifyingKeyFile)
+	data, err := os.ReadFile(vkFile)
+	assert.NoError(t, err)
+	data[len(data)-1] ^= 1
+	assert.NoError(t, os.WriteFile(vkFile, data, 0644))
+	_, err = store.LoadVerifier(id)
+	assert.Error(t, err)
+}
+
+func TestArtifactStoreSetup(t *testing.T) {
+	store := NewArtifactStore(t.TempDir())
+	id := CircuitId{Name: CircuitDatasetMiMCName, Version: signature.CircuitV2, Size: 1}
+	a, err := store.Setup(id)
+	assert.NoError(t, err)
+
+	b, err := store.Load(id)
+	assert.NoError(t, err)
+	assert.Equal(t, a.Manifest, b.Manifest)
+	assert.False(t, a.VerifyingKey.IsDifferent(b.VerifyingKey))
+	assert.False(t, a.ProvingKey.IsDifferent(b.ProvingKey))
+	assert.NoError(t, b.CheckCompiled())
+
+	// the keys of a circuit do not match another one
+	b.Manifest.Circuit.Size = 2
+	assert.Error(t, b.CheckCompiled())
+	r1cs, err := CompileCircuitDataset()
+	assert.NoError(t, err)
+	_, err = NewCircuitArtifacts(CircuitId{Name: CircuitDatasetName}, r1cs, a.ProvingKey, a.VerifyingKey)
+	assert.Error(t, err)
+	r1cs2, err := CircuitId{Name: CircuitDatasetMiMCName, Version: signature.CircuitV2, Size: 2}.Compile()
+	assert.NoError(t, err)
+	_, err = NewCircuitArtifacts(b.Manifest.Circuit, r1cs2, a.ProvingKey, a.VerifyingKey)
+	assert.Error(t, err)
+	// the keys of two setups of the circuit
+	_, vk, err := groth16.Setup(a.R1CS)
+	assert.NoError(t, err)
+	_, err = NewCircuitArtifacts(id, a.R1CS, a.ProvingKey, vk)
+	assert.Error(t, err)
+	_, err = NewCircuitArtifacts(id, a.R1CS, a.ProvingKey, a.VerifyingKey)
+	assert.NoError(t, err)
+
+	// stored keys not matching the constraint system
+	assert.NoError(t, store.Save(&CircuitArtifacts{Manifest: a.Manifest, R1CS: a.R1CS, ProvingKey: a.ProvingKey,
+		VerifyingKey: vk}))
+	_, err = store.Load(id)
+	assert.Error(t, err)
+	// stored keys of another constraint system than the compiled one
+	pk2, vk2, err := groth16.Setup(r1cs2)
+	assert.NoError(t, err)
+	c, err := NewCircuitArtifacts(id, r1cs2, pk2, vk2)
+	assert.NoError(t, err)
+	assert.NoError(t, store.Save(c))
+	_, err = store.Load(id)
+	assert.Error(t, err)
+	assert.NoError(t, store.Save(a))
+	_, err = store.Load(id)
+	assert.NoError(t, err)
+
+	// the verifying keys are those of the manifest of the circuit
+	vk, err = store.VerifyingKey(id)
+	assert.NoError(t, err)
+	assert.False(t, a.VerifyingKey.IsDifferent(vk))
+	_, err = store.VerifyingKey(b.Manifest.Circuit)
+	assert.Error(t, err)
+
+	_, err = CircuitId{Name: "unknown"}.Compile()
+	assert.Error(t, err)
+	_, err = CircuitId{Name: CircuitDatasetMiMCName}.Compile()
+	assert.Error(t, err)
+
+	// the ECDSA circuit, with a commitment, is not in the store
+	ecdsaId := CircuitId{Name: CircuitDatasetECDSAName, Version: signature.CurrentCircuitVersion}
+	_, err = store.Setup(ecdsaId)
+	assert.Error(t, err)
+	_, err = store.VerifyingKey(ecdsaId)
+	assert.Error(t, err)
+}